@@ -14,7 +14,7 @@ import (
 
 type DemoCube struct {
 	rotationX, rotationY float64
-	texture              uint32
+	texture              *textures.Texture
 	xSpeed, ySpeed       float64
 	cleanupFuncs         []func()
 }
@@ -26,7 +26,7 @@ func (d *DemoCube) Draw(t time.Time, dt time.Duration) error {
 	gl.Rotatef(float32(d.rotationX), 1, 0, 0)
 	gl.Rotatef(float32(d.rotationY), 0, 1, 0)
 
-	gl.BindTexture(gl.TEXTURE_2D, d.texture)
+	gl.BindTexture(gl.TEXTURE_2D, d.texture.ID)
 
 	gl.Color4f(1, 1, 1, 1)
 
@@ -122,7 +122,7 @@ func (d *DemoCube) StartTicks(ctx context.Context) {
 }
 
 func (d *DemoCube) Cleanup() {
-	gl.DeleteTextures(1, &d.texture)
+	d.texture.Release()
 	// Grap a copy of the cleanup functions and clear the list to avoid duplicate calls
 	// Note this is not thread safe
 	cleanups := slices.Clone(d.cleanupFuncs)
@@ -132,15 +132,12 @@ func (d *DemoCube) Cleanup() {
 	}
 }
 
-func MakeCube() (*DemoCube, error) {
-	glog.Info("Creating demo cube with hard coded texture")
-	texture, err := textures.LoadTextureFile("square.png")
-	if err != nil {
-		return nil, err
-	}
+// MakeCube textures the cube with the "square" texture, shared through m.
+func MakeCube(m *textures.Manager) *DemoCube {
+	glog.Info("Creating demo cube with square texture")
 	return &DemoCube{
-		texture:   textures.LoadTextureToGPU(texture),
+		texture:   m.Acquire("square"),
 		rotationX: 0,
 		rotationY: 0,
-	}, nil
+	}
 }
@@ -15,7 +15,7 @@ import (
 
 type FancyDemoCube struct {
 	rotation     *vec.LinearDynoVec
-	texture      uint32
+	texture      *textures.Texture
 	cleanupFuncs []func()
 }
 
@@ -27,7 +27,7 @@ func (d *FancyDemoCube) Draw(t time.Time, dt time.Duration) error {
 	gl.Rotatef(float32(rotation.X), 1, 0, 0)
 	gl.Rotatef(float32(rotation.Y), 0, 1, 0)
 
-	gl.BindTexture(gl.TEXTURE_2D, d.texture)
+	gl.BindTexture(gl.TEXTURE_2D, d.texture.ID)
 
 	gl.Color4f(1, 1, 1, 1)
 
@@ -115,7 +115,7 @@ func (d *FancyDemoCube) StartTicks(ctx context.Context) {
 }
 
 func (d *FancyDemoCube) Cleanup() {
-	gl.DeleteTextures(1, &d.texture)
+	d.texture.Release()
 	// Grap a copy of the cleanup functions and clear the list to avoid duplicate calls
 	// Note this is not thread safe
 	cleanups := slices.Clone(d.cleanupFuncs)
@@ -125,14 +125,11 @@ func (d *FancyDemoCube) Cleanup() {
 	}
 }
 
-func MakeFancyCube() (*FancyDemoCube, error) {
-	glog.Info("Creating fancy demo cube with hard coded texture")
-	texture, err := textures.LoadTextureFile("square.png")
-	if err != nil {
-		return nil, err
-	}
+// MakeFancyCube textures the cube with the "square" texture, shared through m.
+func MakeFancyCube(m *textures.Manager) *FancyDemoCube {
+	glog.Info("Creating fancy demo cube with square texture")
 	return &FancyDemoCube{
-		texture:  textures.LoadTextureToGPU(texture),
+		texture:  m.Acquire("square"),
 		rotation: vec.MakeLinearDynoVec(time.Now(), vec.Vec3{}, vec.Vec3{}),
-	}, nil
+	}
 }
@@ -2,29 +2,37 @@ package demoscene
 
 import (
 	"context"
+	"flag"
 	"github.com/dragon1672/go-mine/demos/demoscene/demoasset"
 	"time"
 
+	"github.com/dragon1672/go-mine/minecraft/assets"
 	"github.com/dragon1672/go-mine/minecraft/renderer"
+	"github.com/dragon1672/go-mine/minecraft/renderer/textures"
+	"github.com/dragon1672/go-mine/minecraft/utils/tickers"
+	"github.com/dragon1672/go-mine/minecraft/world/save"
+	"github.com/dragon1672/go-mine/minecraft/world/worldtime"
 	"github.com/go-gl/gl/v2.1/gl"
 	"github.com/golang/glog"
 )
 
+var (
+	saveDir   = flag.String("save_dir", "", "directory to load and save world state from, empty to not persist")
+	dayLength = flag.Int64("day_length", worldtime.DefaultDayLength, "ticks in a full day/night cycle")
+	assetDir  = flag.String("asset_dir", "", "directory of assets overriding the built in ones")
+	hotReload = flag.Duration("hot_reload", 0, "how often to reload changed textures from asset_dir, 0 to never")
+	pixelArt  = flag.Bool("pixel_art", false, "draw textures crisp and mipmapped rather than smoothed")
+)
+
 func setupScene(r *renderer.Window) {
 	glog.Info("Setting up general area with some lights")
 	gl.Enable(gl.DEPTH_TEST)
 	gl.Enable(gl.LIGHTING)
 
-	gl.ClearColor(0.5, 0.5, 0.5, 0.0)
 	gl.ClearDepth(1)
 	gl.DepthFunc(gl.LEQUAL)
 
-	ambient := []float32{0.5, 0.5, 0.5, 1}
-	diffuse := []float32{1, 1, 1, 1}
-	lightPosition := []float32{-5, 5, 10, 0}
-	gl.Lightfv(gl.LIGHT0, gl.AMBIENT, &ambient[0])
-	gl.Lightfv(gl.LIGHT0, gl.DIFFUSE, &diffuse[0])
-	gl.Lightfv(gl.LIGHT0, gl.POSITION, &lightPosition[0])
+	// LIGHT0 is positioned each frame by sunLight
 	gl.Enable(gl.LIGHT0)
 
 	gl.MatrixMode(gl.PROJECTION)
@@ -59,11 +67,33 @@ func BadMain(ctx context.Context) {
 
 	setupScene(w)
 
-	//cube, err := demoasset.MakeCube()
-	cube, err := demoasset.MakeFancyCube()
+	clock, err := loadClock()
 	if err != nil {
-		glog.Fatalf("error making cube: %v", err)
+		glog.Fatalf("error loading world time: %v", err)
+	}
+	defer func() {
+		if err := saveClock(clock); err != nil {
+			glog.Errorf("error saving world time: %v", err)
+		}
+	}()
+	w.SetSky(clock)
+	w.AddItem(&sunLight{clock: clock})
+	clockCleanup := tickers.StartTicker(ctx, time.Second/worldtime.TicksPerSecond, func(t time.Time, dt time.Duration) (bool, error) {
+		clock.Tick()
+		return true, nil
+	})
+	defer clockCleanup()
+
+	var uploader textures.GLUploader
+	if *pixelArt {
+		uploader.Options = textures.PixelArt
 	}
+	texs := textures.NewManager(assets.New(*assetDir), uploader)
+	texs.HotReload = *hotReload
+	w.AddItem(texs)
+
+	//cube := demoasset.MakeCube(texs)
+	cube := demoasset.MakeFancyCube(texs)
 
 	glog.Info("Add cube to window to be rendered")
 	w.AddItem(cube)
@@ -75,3 +105,33 @@ func BadMain(ctx context.Context) {
 		glog.Fatalf("Game Loop Err: %v", err)
 	}
 }
+
+func loadClock() (*worldtime.Clock, error) {
+	if *saveDir == "" {
+		return worldtime.NewClock(*dayLength), nil
+	}
+	level, ok, err := save.ReadLevel(*saveDir)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		glog.Infof("No level found in %q, starting a new one", *saveDir)
+		return worldtime.NewClock(*dayLength), nil
+	}
+	clock := worldtime.NewClock(level.DayLength)
+	clock.SetTicks(level.Time)
+	return clock, nil
+}
+
+func saveClock(clock *worldtime.Clock) error {
+	if *saveDir == "" {
+		return nil
+	}
+	level, _, err := save.ReadLevel(*saveDir)
+	if err != nil {
+		return err
+	}
+	level.Time = clock.Ticks()
+	level.DayLength = clock.DayLength()
+	return save.WriteLevel(*saveDir, level)
+}
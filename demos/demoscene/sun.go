@@ -0,0 +1,31 @@
+package demoscene
+
+import (
+	"time"
+
+	"github.com/dragon1672/go-mine/minecraft/world/worldtime"
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// sunLight moves LIGHT0 with the sun and dims it at night.
+// It has to be drawn before anything it should light.
+type sunLight struct {
+	clock *worldtime.Clock
+}
+
+func (s *sunLight) Draw(t time.Time, dt time.Duration) error {
+	gl.MatrixMode(gl.MODELVIEW)
+	gl.LoadIdentity()
+
+	dir := s.clock.SunDirection()
+	l := float32(s.clock.SkylightMultiplier())
+	position := []float32{float32(dir.X), float32(dir.Y), float32(dir.Z), 0}
+	ambient := []float32{0.5 * l, 0.5 * l, 0.5 * l, 1}
+	diffuse := []float32{l, l, l, 1}
+	gl.Lightfv(gl.LIGHT0, gl.POSITION, &position[0])
+	gl.Lightfv(gl.LIGHT0, gl.AMBIENT, &ambient[0])
+	gl.Lightfv(gl.LIGHT0, gl.DIFFUSE, &diffuse[0])
+	return nil
+}
+
+func (s *sunLight) Cleanup() {}
@@ -7,12 +7,10 @@ package glhfdemo // import "github.com/go-gl/example/gl21-cube"
 
 import (
 	"image"
-	"image/draw"
-	_ "image/png"
 	"log"
-	"os"
 	"runtime"
 
+	"github.com/dragon1672/go-mine/minecraft/assets"
 	"github.com/go-gl/gl/v2.1/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
 )
@@ -49,7 +47,7 @@ func DemoMain() {
 		panic(err)
 	}
 
-	texture = newTexture("square.png")
+	texture = newTexture(assets.Default.Texture("square"))
 	defer gl.DeleteTextures(1, &texture)
 
 	setupScene()
@@ -60,21 +58,10 @@ func DemoMain() {
 	}
 }
 
-func newTexture(file string) uint32 {
-	imgFile, err := os.Open(file)
-	if err != nil {
-		log.Fatalf("texture %q not found on disk: %v\n", file, err)
-	}
-	img, _, err := image.Decode(imgFile)
-	if err != nil {
-		panic(err)
-	}
-
-	rgba := image.NewRGBA(img.Bounds())
+func newTexture(rgba *image.RGBA) uint32 {
 	if rgba.Stride != rgba.Rect.Size().X*4 {
 		panic("unsupported stride")
 	}
-	draw.Draw(rgba, rgba.Bounds(), img, image.Point{0, 0}, draw.Src)
 
 	var texture uint32
 	gl.Enable(gl.TEXTURE_2D)
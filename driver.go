@@ -4,13 +4,34 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+
 	"github.com/dragon1672/go-mine/demos/demoscene"
+	"github.com/dragon1672/go-mine/minecraft/rcon"
+	"github.com/dragon1672/go-mine/minecraft/server"
 	"github.com/golang/glog"
 )
 
 func main() {
 	flag.Parse()
 	ctx := context.Background()
+	switch flag.Arg(0) {
+	case "server":
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+		if err := server.RunDedicated(ctx, flag.Args()[1:]); err != nil {
+			glog.Errorf("Server error: %v", err)
+		}
+		glog.Flush()
+		return
+	case "rcon":
+		if err := rcon.RunCLI(ctx, flag.Args()[1:], os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	fmt.Println("Hello World!")
 	//glhfdemo.DemoMain()
 	demoscene.BadMain(ctx)
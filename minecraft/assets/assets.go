@@ -0,0 +1,45 @@
+// Package assets finds the game's files by logical name. Defaults are built into the
+// binary, and a user directory can override any of them so it runs from anywhere.
+package assets
+
+import (
+	"errors"
+	"image"
+	"io/fs"
+	"os"
+
+	"github.com/dragon1672/go-mine/minecraft/renderer/textures"
+)
+
+// Assets layers a user directory over the built in defaults. It's an fs.FS where each
+// file comes from the first layer that has it.
+type Assets struct {
+	layers []fs.FS
+}
+
+// Default is just the built in assets, see textures.Defaults.
+var Default = &Assets{layers: []fs.FS{textures.Defaults}}
+
+// New overrides the defaults with files in dir, which may be empty for no overrides.
+func New(dir string) *Assets {
+	if dir == "" {
+		return Default
+	}
+	return &Assets{layers: []fs.FS{os.DirFS(dir), textures.Defaults}}
+}
+
+func (a *Assets) Open(name string) (fs.File, error) {
+	for _, l := range a.layers {
+		f, err := l.Open(name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return f, err
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// Texture loads textures/<name>.png, see textures.TextureOrPlaceholder.
+func (a *Assets) Texture(name string) *image.RGBA {
+	return textures.TextureOrPlaceholder(a, name)
+}
@@ -0,0 +1,56 @@
+package assets
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dragon1672/go-mine/minecraft/renderer/textures"
+)
+
+func TestTexture(t *testing.T) {
+	t.Parallel()
+	builtin := Default.Texture("square")
+	if builtin.Bounds().Empty() || builtin.RGBAAt(0, 0) == (color.RGBA{248, 0, 248, 255}) {
+		t.Fatalf("built in square texture didn't load")
+	}
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "textures"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(filepath.Join(dir, "textures", "square.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, 3, 5))); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	a := New(dir)
+
+	for _, tc := range []struct {
+		name string
+		a    *Assets
+		want image.Point
+	}{
+		{"square", Default, builtin.Bounds().Size()},
+		{"square", a, image.Pt(3, 5)},
+		// missing textures are a placeholder checkerboard
+		{"nope", a, textures.Checkerboard(16).Bounds().Size()},
+	} {
+		img := tc.a.Texture(tc.name)
+		if got := img.Bounds().Size(); got != tc.want {
+			t.Errorf("Texture(%q) is %v, want %v", tc.name, got, tc.want)
+		}
+	}
+	if got := a.Texture("nope").RGBAAt(0, 0); got != (color.RGBA{248, 0, 248, 255}) {
+		t.Errorf("placeholder starts %v, want magenta", got)
+	}
+	if New("") != Default {
+		t.Errorf("New(\"\") should be the defaults")
+	}
+}
@@ -0,0 +1,348 @@
+// Package client connects to a go-mine server and mirrors the chunks it is sent.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dragon1672/go-mine/minecraft/gamemode"
+	"github.com/dragon1672/go-mine/minecraft/physics"
+	"github.com/dragon1672/go-mine/minecraft/protocol"
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// RemotePlayer is another player as last reported by the server.
+type RemotePlayer struct {
+	ID   uint32
+	Name string
+	Pos  vec.Vec3
+}
+
+type remote struct {
+	RemotePlayer
+	interp *Interpolator
+}
+
+type Client struct {
+	conn  *protocol.Conn
+	id    uint32
+	seed  int64
+	spawn vec.Vec3
+
+	mu       sync.Mutex
+	mode     gamemode.Mode
+	health   int
+	predict  *Predictor
+	chunks   map[vec.IntVec3]*world.Chunk
+	players  map[uint32]*remote
+	messages []string
+	err      error
+	// changed is closed and replaced whenever any state above changes, see WaitFor.
+	changed chan struct{}
+	done    chan struct{}
+}
+
+// Dial connects and logs in as name. token is the server password, if any.
+func Dial(ctx context.Context, addr, name, token string) (*Client, error) {
+	var d net.Dialer
+	nc, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to %s: %v", addr, err)
+	}
+	return Login(nc, name, token)
+}
+
+// Login is Dial over an existing connection, such as one wrapped by netsim.
+func Login(nc net.Conn, name, token string) (*Client, error) {
+	conn := protocol.NewConn(nc)
+	if err := conn.WritePacket(&protocol.Hello{Version: protocol.Version, Name: name, Token: token}); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error sending hello: %v", err)
+	}
+	pkt, err := conn.ReadPacket()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error reading welcome: %v", err)
+	}
+	var welcome *protocol.Welcome
+	switch pkt := pkt.(type) {
+	case *protocol.Welcome:
+		welcome = pkt
+	case *protocol.Disconnect:
+		conn.Close()
+		return nil, fmt.Errorf("server refused login: %s", pkt.Reason)
+	default:
+		conn.Close()
+		return nil, fmt.Errorf("expected welcome, got packet %d", pkt.ID())
+	}
+	c := &Client{
+		conn:    conn,
+		id:      welcome.PlayerID,
+		seed:    welcome.Seed,
+		spawn:   welcome.Spawn,
+		health:  gamemode.MaxHealth,
+		predict: NewPredictor(physics.PlayerBody, physics.State{Pos: welcome.Spawn}),
+		chunks:  map[vec.IntVec3]*world.Chunk{},
+		players: map[uint32]*remote{},
+		changed: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+func (c *Client) ID() uint32 {
+	return c.id
+}
+
+func (c *Client) Seed() int64 {
+	return c.seed
+}
+
+func (c *Client) Spawn() vec.Vec3 {
+	return c.spawn
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Done is closed once the connection ends, Err says why.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// notify wakes WaitFor callers. c.mu must be held.
+func (c *Client) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// WaitFor blocks until cond holds, checking it after every update from the server.
+func (c *Client) WaitFor(ctx context.Context, cond func(c *Client) bool) error {
+	for {
+		c.mu.Lock()
+		changed := c.changed
+		c.mu.Unlock()
+		if cond(c) {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-c.done:
+			if cond(c) {
+				return nil
+			}
+			return fmt.Errorf("connection closed: %v", c.Err())
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *Client) GameMode() gamemode.Mode {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mode
+}
+
+func (c *Client) Health() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.health
+}
+
+// Pos is the local player's predicted position.
+func (c *Client) Pos() vec.Vec3 {
+	return c.State().Pos
+}
+
+func (c *Client) State() physics.State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.predict.State()
+}
+
+// PendingInputs is how many inputs the server hasn't acknowledged yet.
+func (c *Client) PendingInputs() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.predict.Pending()
+}
+
+// Step moves the local player by one tick of in straight away and sends it to the server,
+// call it once per tick. in.Seq is filled in.
+func (c *Client) Step(in physics.Input) error {
+	c.mu.Lock()
+	in = c.predict.Apply(mirror{c}, in)
+	c.notify()
+	c.mu.Unlock()
+	return c.conn.WritePacket(&protocol.PlayerInput{Input: in})
+}
+
+// SetBlock asks the server to change a block. The local copy only changes once the server confirms.
+func (c *Client) SetBlock(pos vec.IntVec3, t blocks.SimpleBlockType) error {
+	return c.conn.WritePacket(&protocol.BlockChange{Pos: pos, Block: t})
+}
+
+func (c *Client) Chat(msg string) error {
+	return c.conn.WritePacket(&protocol.Chat{Message: msg})
+}
+
+// mirror reads blocks for prediction, c.mu must be held. Chunks not received yet are air,
+// the server corrects anything that gets wrong.
+type mirror struct {
+	c *Client
+}
+
+func (m mirror) BlockAt(pos vec.IntVec3) blocks.SimpleBlockType {
+	chunk, local := world.ChunkOf(pos)
+	if ch, ok := m.c.chunks[chunk]; ok {
+		return ch.Get(local)
+	}
+	return blocks.Air
+}
+
+// BlockAt reads the local copy of the world, ok is false if the chunk hasn't been received.
+func (c *Client) BlockAt(pos vec.IntVec3) (t blocks.SimpleBlockType, ok bool) {
+	chunk, local := world.ChunkOf(pos)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch, ok := c.chunks[chunk]
+	if !ok {
+		return blocks.Air, false
+	}
+	return ch.Get(local), true
+}
+
+func (c *Client) HasChunk(pos vec.IntVec3) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.chunks[pos]
+	return ok
+}
+
+func (c *Client) ChunkCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.chunks)
+}
+
+// Players lists the other players online, ordered by ID.
+func (c *Client) Players() []RemotePlayer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]RemotePlayer, 0, len(c.players))
+	for _, p := range c.players {
+		out = append(out, p.RemotePlayer)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// PlayerPos is where to draw player id at now, interpolated between server updates.
+func (c *Client) PlayerPos(id uint32, now time.Time) (vec.Vec3, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.players[id]
+	if !ok {
+		return vec.Vec3{}, false
+	}
+	return p.interp.At(now), true
+}
+
+// Messages is every chat line received so far.
+func (c *Client) Messages() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.messages...)
+}
+
+func (c *Client) readLoop() {
+	err := c.read()
+	c.conn.Close()
+	c.mu.Lock()
+	c.err = err
+	c.notify()
+	c.mu.Unlock()
+	close(c.done)
+}
+
+func (c *Client) read() error {
+	for {
+		pkt, err := c.conn.ReadPacket()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		if err := c.handle(pkt); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) handle(pkt protocol.Packet) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.notify()
+	switch pkt := pkt.(type) {
+	case *protocol.ChunkData:
+		ch, err := world.DecodeChunk(pkt.Pos, pkt.Data)
+		if err != nil {
+			return err
+		}
+		c.chunks[pkt.Pos] = ch
+	case *protocol.UnloadChunk:
+		delete(c.chunks, pkt.Pos)
+	case *protocol.BlockChange:
+		chunk, local := world.ChunkOf(pkt.Pos)
+		if ch, ok := c.chunks[chunk]; ok {
+			ch.Set(local, pkt.Block)
+		}
+	case *protocol.PlayerState:
+		c.predict.Reconcile(mirror{c}, pkt.Seq, pkt.State)
+	case *protocol.PlayerPosition:
+		if pkt.PlayerID == c.id {
+			c.predict.Reset(mirror{c}, physics.State{Pos: pkt.Pos})
+		} else if p, ok := c.players[pkt.PlayerID]; ok {
+			p.Pos = pkt.Pos
+			p.interp.Push(time.Now(), pkt.Pos)
+		}
+	case *protocol.PlayerJoin:
+		p := &remote{
+			RemotePlayer: RemotePlayer{ID: pkt.PlayerID, Name: pkt.Name, Pos: pkt.Pos},
+			interp:       NewInterpolator(DefaultInterpolationDelay),
+		}
+		p.interp.Push(time.Now(), pkt.Pos)
+		c.players[pkt.PlayerID] = p
+	case *protocol.PlayerLeave:
+		delete(c.players, pkt.PlayerID)
+	case *protocol.GameMode:
+		c.mode = pkt.Mode
+		c.predict.SetMovement(pkt.Mode.Abilities().Movement)
+	case *protocol.Health:
+		c.health = int(pkt.Health)
+	case *protocol.Chat:
+		c.messages = append(c.messages, pkt.Message)
+	case *protocol.Disconnect:
+		return fmt.Errorf("disconnected by server: %s", pkt.Reason)
+	default:
+		return fmt.Errorf("unexpected packet %d", pkt.ID())
+	}
+	return nil
+}
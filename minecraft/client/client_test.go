@@ -0,0 +1,115 @@
+package client
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dragon1672/go-mine/minecraft/physics"
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// floor is stone at y < 0 plus any extra blocks.
+type floor map[vec.IntVec3]blocks.SimpleBlockType
+
+func (f floor) BlockAt(pos vec.IntVec3) blocks.SimpleBlockType {
+	if b, ok := f[pos]; ok {
+		return b
+	}
+	if pos.Y < 0 {
+		return blocks.Stone
+	}
+	return blocks.Air
+}
+
+func TestPredictorReconcile(t *testing.T) {
+	t.Parallel()
+	start := physics.State{Pos: vec.Vec3{X: 0.5, Z: 0.5}, OnGround: true}
+	in := physics.Input{Forward: 1}
+
+	t.Run("agrees with server", func(t *testing.T) {
+		w := floor{}
+		p := NewPredictor(physics.PlayerBody, start)
+		server := start
+		var sent []physics.Input
+		for i := 0; i < 6; i++ {
+			sent = append(sent, p.Apply(w, in))
+		}
+		// the server has only seen the first 4
+		for _, in := range sent[:4] {
+			server = physics.Step(w, physics.PlayerBody, server, in, physics.Movement{})
+		}
+		predicted := p.State()
+		if off := p.Reconcile(w, sent[3].Seq, server); off != 0 {
+			t.Errorf("Reconcile() = %v off, want 0", off)
+		}
+		if p.State() != predicted || p.Pending() != 2 {
+			t.Errorf("after reconcile state %+v pending %d, want %+v pending 2", p.State(), p.Pending(), predicted)
+		}
+	})
+
+	t.Run("corrected by server", func(t *testing.T) {
+		// the client doesn't know about a wall the server has
+		wall := floor{{X: 0, Y: 0, Z: -1}: blocks.Stone, {X: 0, Y: 1, Z: -1}: blocks.Stone}
+		p := NewPredictor(physics.PlayerBody, start)
+		server := start
+		var sent []physics.Input
+		for i := 0; i < 8; i++ {
+			sent = append(sent, p.Apply(floor{}, in))
+		}
+		for _, in := range sent[:5] {
+			server = physics.Step(wall, physics.PlayerBody, server, in, physics.Movement{})
+		}
+		// by now the client has the wall too
+		if off := p.Reconcile(wall, sent[4].Seq, server); off == 0 {
+			t.Errorf("Reconcile() = 0 off, want a correction")
+		}
+		for _, in := range sent[5:] {
+			server = physics.Step(wall, physics.PlayerBody, server, in, physics.Movement{})
+		}
+		if p.State() != server {
+			t.Errorf("reconciled to %+v, server ends at %+v", p.State(), server)
+		}
+		if math.Abs(p.State().Pos.Z-0.3) > 1e-9 {
+			t.Errorf("Z = %v, want flush against the wall at 0.3", p.State().Pos.Z)
+		}
+	})
+
+	t.Run("teleport replays pending", func(t *testing.T) {
+		w := floor{}
+		p := NewPredictor(physics.PlayerBody, start)
+		p.Apply(w, in)
+		p.Reset(w, start)
+		if p.Pending() != 1 || p.State().Pos == start.Pos {
+			t.Errorf("Reset() dropped pending input: %+v pending %d", p.State(), p.Pending())
+		}
+	})
+}
+
+func TestInterpolator(t *testing.T) {
+	t.Parallel()
+	t0 := time.Unix(100, 0)
+	ms := func(n int) time.Time { return t0.Add(time.Duration(n) * time.Millisecond) }
+	b := NewInterpolator(100 * time.Millisecond)
+	if got := b.At(t0); got != (vec.Vec3{}) {
+		t.Errorf("At() with no updates = %v", got)
+	}
+	b.Push(ms(0), vec.Vec3{X: 0})
+	b.Push(ms(50), vec.Vec3{X: 10})
+	b.Push(ms(100), vec.Vec3{X: 10, Z: 4})
+	for _, tc := range []struct {
+		at   int
+		want vec.Vec3
+	}{
+		{at: 50, want: vec.Vec3{X: 0}},
+		{at: 100, want: vec.Vec3{X: 0}},
+		{at: 125, want: vec.Vec3{X: 5}},
+		{at: 175, want: vec.Vec3{X: 10, Z: 2}},
+		{at: 500, want: vec.Vec3{X: 10, Z: 4}},
+	} {
+		if got := b.At(ms(tc.at)); got != tc.want {
+			t.Errorf("At(%dms) = %v, want %v", tc.at, got, tc.want)
+		}
+	}
+}
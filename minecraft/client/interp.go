@@ -0,0 +1,57 @@
+package client
+
+import (
+	"time"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+)
+
+// DefaultInterpolationDelay is two ticks, so one late update doesn't make other players stutter.
+const DefaultInterpolationDelay = 100 * time.Millisecond
+
+// maxSnapshots bounds an Interpolator's memory, a couple of seconds of updates.
+const maxSnapshots = 64
+
+type snapshot struct {
+	at  time.Time
+	pos vec.Vec3
+}
+
+// Interpolator smooths another player's movement by showing them slightly in the past,
+// blending between the two updates either side of that time.
+type Interpolator struct {
+	Delay time.Duration
+	snaps []snapshot
+}
+
+func NewInterpolator(delay time.Duration) *Interpolator {
+	return &Interpolator{Delay: delay}
+}
+
+// Push records pos as received at t. Times must not go backwards.
+func (b *Interpolator) Push(t time.Time, pos vec.Vec3) {
+	b.snaps = append(b.snaps, snapshot{at: t, pos: pos})
+	if len(b.snaps) > maxSnapshots {
+		b.snaps = b.snaps[len(b.snaps)-maxSnapshots:]
+	}
+}
+
+// At is where to draw the player at now. Before the first update, or after the last one,
+// it holds the nearest position rather than guessing.
+func (b *Interpolator) At(now time.Time) vec.Vec3 {
+	if len(b.snaps) == 0 {
+		return vec.Vec3{}
+	}
+	t := now.Add(-b.Delay)
+	// drop snapshots that will never be needed again, keeping one before t
+	for len(b.snaps) > 1 && !b.snaps[1].at.After(t) {
+		b.snaps = b.snaps[1:]
+	}
+	first := b.snaps[0]
+	if len(b.snaps) == 1 || !t.After(first.at) {
+		return first.pos
+	}
+	next := b.snaps[1]
+	f := float64(t.Sub(first.at)) / float64(next.at.Sub(first.at))
+	return first.pos.Add(next.pos.Sub(first.pos).Mul(f))
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"github.com/dragon1672/go-mine/minecraft/physics"
+)
+
+// Predictor runs the local player's inputs straight away instead of waiting a round trip for the
+// server. It keeps every input the server hasn't acknowledged, so when the server's state arrives it
+// can start from that and replay them, fixing any misprediction without undoing newer movement.
+type Predictor struct {
+	body     physics.Body
+	movement physics.Movement
+	state    physics.State
+	nextSeq  uint32
+	pending  []physics.Input
+}
+
+func NewPredictor(body physics.Body, start physics.State) *Predictor {
+	return &Predictor{body: body, state: start, nextSeq: 1}
+}
+
+// SetMovement changes how inputs from now on are predicted, such as after a game mode change.
+func (p *Predictor) SetMovement(m physics.Movement) {
+	p.movement = m
+}
+
+func (p *Predictor) State() physics.State {
+	return p.state
+}
+
+// Pending is how many inputs are waiting on the server.
+func (p *Predictor) Pending() int {
+	return len(p.pending)
+}
+
+// Apply numbers in, runs it locally and returns it ready to send.
+func (p *Predictor) Apply(w physics.World, in physics.Input) physics.Input {
+	in.Seq = p.nextSeq
+	p.nextSeq++
+	p.state = physics.Step(w, p.body, p.state, in, p.movement)
+	p.pending = append(p.pending, in)
+	return in
+}
+
+// Reconcile takes the server's state after input seq and replays the inputs after it.
+// It returns how far the prediction was off.
+func (p *Predictor) Reconcile(w physics.World, seq uint32, server physics.State) float64 {
+	i := 0
+	for i < len(p.pending) && p.pending[i].Seq <= seq {
+		i++
+	}
+	p.pending = p.pending[i:]
+	predicted := p.state
+	p.Reset(w, server)
+	return predicted.Pos.Sub(p.state.Pos).Length()
+}
+
+// Reset moves the player, such as for a teleport, and replays unacknowledged inputs from there.
+// The server runs those inputs after the move too, since they reach it afterwards.
+func (p *Predictor) Reset(w physics.World, s physics.State) {
+	p.state = s
+	for _, in := range p.pending {
+		p.state = physics.Step(w, p.body, p.state, in, p.movement)
+	}
+}
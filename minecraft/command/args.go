@@ -0,0 +1,220 @@
+package command
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// Arg parses one typed argument out of the command tokens.
+type Arg interface {
+	Name() string
+	// Width is how many tokens the argument takes, 0 means the rest of the line.
+	Width() int
+	Parse(src Source, toks []Token) (any, *Error)
+	// Suggest completes the i'th token of the argument.
+	Suggest(src Source, i int, partial string) []string
+}
+
+type optionalArg struct {
+	Arg
+}
+
+// Optional marks a trailing argument as not required, check Context.Has before reading it.
+func Optional(a Arg) Arg {
+	return optionalArg{a}
+}
+
+func IsOptional(a Arg) bool {
+	_, ok := a.(optionalArg)
+	return ok
+}
+
+func filterPrefix(options []string, partial string) []string {
+	var out []string
+	for _, o := range options {
+		if strings.HasPrefix(strings.ToLower(o), strings.ToLower(partial)) {
+			out = append(out, o)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+type intArg struct {
+	name     string
+	min, max int
+}
+
+// Int is an integer argument within [min, max].
+func Int(name string, min, max int) Arg {
+	return &intArg{name: name, min: min, max: max}
+}
+
+func (a *intArg) Name() string { return a.name }
+func (a *intArg) Width() int   { return 1 }
+func (a *intArg) Parse(_ Source, toks []Token) (any, *Error) {
+	v, err := strconv.Atoi(toks[0].Text)
+	if err != nil {
+		return nil, tokenError(ErrBadArgument, toks[0], "%s: expected an integer, got %q", a.name, toks[0].Text)
+	}
+	if v < a.min || v > a.max {
+		return nil, tokenError(ErrBadArgument, toks[0], "%s: %d is not in [%d, %d]", a.name, v, a.min, a.max)
+	}
+	return v, nil
+}
+func (a *intArg) Suggest(Source, int, string) []string { return nil }
+
+type durationArg struct {
+	name string
+}
+
+// Duration is a positive length of time such as 90s, 1h30m or 2d. On top of what
+// time.ParseDuration takes it allows d for days and w for weeks.
+func Duration(name string) Arg {
+	return &durationArg{name: name}
+}
+
+func (a *durationArg) Name() string { return a.name }
+func (a *durationArg) Width() int   { return 1 }
+func (a *durationArg) Parse(_ Source, toks []Token) (any, *Error) {
+	d, err := parseDuration(toks[0].Text)
+	if err != nil || d <= 0 {
+		return nil, tokenError(ErrBadArgument, toks[0], "%s: expected a duration such as 30m or 2d, got %q", a.name, toks[0].Text)
+	}
+	return d, nil
+}
+func (a *durationArg) Suggest(Source, int, string) []string { return nil }
+
+func parseDuration(s string) (time.Duration, error) {
+	for _, u := range []struct {
+		suffix string
+		unit   time.Duration
+	}{{"d", 24 * time.Hour}, {"w", 7 * 24 * time.Hour}} {
+		if n, ok := strings.CutSuffix(s, u.suffix); ok {
+			v, err := strconv.Atoi(n)
+			if err != nil {
+				return 0, err
+			}
+			return time.Duration(v) * u.unit, nil
+		}
+	}
+	return time.ParseDuration(s)
+}
+
+type wordArg struct {
+	name    string
+	options []string
+}
+
+// Word is a single token. If options are given they are used for completion only.
+func Word(name string, options ...string) Arg {
+	return &wordArg{name: name, options: options}
+}
+
+func (a *wordArg) Name() string { return a.name }
+func (a *wordArg) Width() int   { return 1 }
+func (a *wordArg) Parse(_ Source, toks []Token) (any, *Error) {
+	return toks[0].Text, nil
+}
+func (a *wordArg) Suggest(_ Source, _ int, partial string) []string {
+	return filterPrefix(a.options, partial)
+}
+
+type textArg struct {
+	name string
+}
+
+// Text takes the rest of the line, joined with single spaces.
+func Text(name string) Arg {
+	return &textArg{name: name}
+}
+
+func (a *textArg) Name() string { return a.name }
+func (a *textArg) Width() int   { return 0 }
+func (a *textArg) Parse(_ Source, toks []Token) (any, *Error) {
+	words := make([]string, len(toks))
+	for i, t := range toks {
+		words[i] = t.Text
+	}
+	return strings.Join(words, " "), nil
+}
+func (a *textArg) Suggest(Source, int, string) []string { return nil }
+
+type blockArg struct {
+	name string
+}
+
+// Block is a block type by name, such as "stone" or "minecraft:stone".
+func Block(name string) Arg {
+	return &blockArg{name: name}
+}
+
+func (a *blockArg) Name() string { return a.name }
+func (a *blockArg) Width() int   { return 1 }
+func (a *blockArg) Parse(_ Source, toks []Token) (any, *Error) {
+	t, ok := blocks.ParseType(toks[0].Text)
+	if !ok {
+		return nil, tokenError(ErrBadArgument, toks[0], "%s: unknown block %q", a.name, toks[0].Text)
+	}
+	return t, nil
+}
+func (a *blockArg) Suggest(_ Source, _ int, partial string) []string {
+	var names []string
+	for _, t := range blocks.Types() {
+		names = append(names, strings.ToLower(t.String()))
+	}
+	return filterPrefix(names, partial)
+}
+
+type coordsArg struct {
+	name string
+}
+
+// Coords is a block position over three tokens. Each may be absolute or
+// relative to the source with ~, for example "~ ~1 -20".
+func Coords(name string) Arg {
+	return &coordsArg{name: name}
+}
+
+func (a *coordsArg) Name() string { return a.name }
+func (a *coordsArg) Width() int   { return 3 }
+func (a *coordsArg) Parse(src Source, toks []Token) (any, *Error) {
+	origin, hasOrigin := src.Position()
+	base := []float64{origin.X, origin.Y, origin.Z}
+	var out [3]int
+	for i, tok := range toks {
+		text := tok.Text
+		relative := strings.HasPrefix(text, "~")
+		if relative {
+			if !hasOrigin {
+				return nil, tokenError(ErrBadArgument, tok, "%s: %s has no position for relative coordinates", a.name, src.Name())
+			}
+			text = strings.TrimPrefix(text, "~")
+		}
+		v := 0
+		if text != "" || !relative {
+			n, err := strconv.Atoi(text)
+			if err != nil {
+				return nil, tokenError(ErrBadArgument, tok, "%s: expected a coordinate, got %q", a.name, tok.Text)
+			}
+			v = n
+		}
+		if relative {
+			v += int(math.Floor(base[i]))
+		}
+		out[i] = v
+	}
+	return vec.IntVec3{X: out[0], Y: out[1], Z: out[2]}, nil
+}
+func (a *coordsArg) Suggest(src Source, _ int, partial string) []string {
+	if _, ok := src.Position(); ok && partial == "" {
+		return []string{"~"}
+	}
+	return nil
+}
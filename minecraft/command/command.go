@@ -0,0 +1,255 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+type Command struct {
+	Name        string
+	Description string
+	// Permission is the minimum level needed to run the command or any of its subcommands.
+	Permission int
+	Args       []Arg
+	// Subcommands are chosen by the first token after the name, Run and Args are ignored if set.
+	Subcommands []*Command
+	Run         func(ctx *Context) error
+}
+
+// Usage renders the argument list, for example "setblock <pos> <block>".
+func (c *Command) Usage() string {
+	parts := []string{c.Name}
+	if len(c.Subcommands) > 0 {
+		var names []string
+		for _, s := range c.Subcommands {
+			names = append(names, s.Name)
+		}
+		parts = append(parts, "("+strings.Join(names, "|")+")")
+	}
+	for _, a := range c.Args {
+		if IsOptional(a) {
+			parts = append(parts, "["+a.Name()+"]")
+		} else {
+			parts = append(parts, "<"+a.Name()+">")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func (c *Command) subcommand(name string) *Command {
+	for _, s := range c.Subcommands {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// Context is what a running command gets to see.
+type Context struct {
+	Source Source
+	args   map[string]any
+}
+
+func (c *Context) Int(name string) int         { return c.args[name].(int) }
+func (c *Context) String(name string) string   { return c.args[name].(string) }
+func (c *Context) Pos(name string) vec.IntVec3 { return c.args[name].(vec.IntVec3) }
+func (c *Context) Block(name string) blocks.SimpleBlockType {
+	return c.args[name].(blocks.SimpleBlockType)
+}
+func (c *Context) Duration(name string) time.Duration { return c.args[name].(time.Duration) }
+
+// Has reports whether an optional trailing argument was given.
+func (c *Context) Has(name string) bool {
+	_, ok := c.args[name]
+	return ok
+}
+
+// Replyf formats a message back to the source.
+func (c *Context) Replyf(format string, args ...any) {
+	c.Source.Reply(fmt.Sprintf(format, args...))
+}
+
+// Registry dispatches command lines to registered commands. It is safe for concurrent use.
+type Registry struct {
+	mu       sync.RWMutex
+	commands map[string]*Command
+}
+
+func NewRegistry() *Registry {
+	return &Registry{commands: map[string]*Command{}}
+}
+
+func (r *Registry) Register(cmd *Command) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.commands[cmd.Name]; ok {
+		return fmt.Errorf("command %q already registered", cmd.Name)
+	}
+	r.commands[cmd.Name] = cmd
+	return nil
+}
+
+func (r *Registry) lookup(name string) (*Command, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.commands[name]
+	return c, ok
+}
+
+// Commands lists the commands src is allowed to run, sorted by name.
+func (r *Registry) Commands(src Source) []*Command {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []*Command
+	for _, c := range r.commands {
+		if src.Permission() >= c.Permission {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// resolve walks down subcommands, returning the command to run and the tokens left for its args.
+func (r *Registry) resolve(src Source, line string, toks []Token) (*Command, []Token, *Error) {
+	if len(toks) == 0 {
+		return nil, nil, &Error{Kind: ErrUnknownCommand, Msg: "empty command", Start: 0, End: len(line)}
+	}
+	cmd, ok := r.lookup(strings.ToLower(toks[0].Text))
+	if !ok {
+		return nil, nil, tokenError(ErrUnknownCommand, toks[0], "unknown command %q", toks[0].Text)
+	}
+	rest := toks[1:]
+	for {
+		if src.Permission() < cmd.Permission {
+			return nil, nil, tokenError(ErrPermission, toks[0], "%s needs permission level %d", cmd.Name, cmd.Permission)
+		}
+		if len(cmd.Subcommands) == 0 {
+			return cmd, rest, nil
+		}
+		if len(rest) == 0 {
+			return nil, nil, &Error{Kind: ErrMissingArgument, Msg: "expected a subcommand", Start: len(line), End: len(line), Usage: cmd.Usage()}
+		}
+		sub := cmd.subcommand(strings.ToLower(rest[0].Text))
+		if sub == nil {
+			e := tokenError(ErrUnknownCommand, rest[0], "unknown subcommand %q", rest[0].Text)
+			e.Usage = cmd.Usage()
+			return nil, nil, e
+		}
+		cmd, rest = sub, rest[1:]
+	}
+}
+
+// Execute parses and runs a command line. A leading slash is optional.
+func (r *Registry) Execute(src Source, line string) error {
+	line = strings.TrimPrefix(line, "/")
+	toks, err := Tokenize(line)
+	if err != nil {
+		return err
+	}
+	cmd, rest, cerr := r.resolve(src, line, toks)
+	if cerr != nil {
+		return cerr
+	}
+
+	ctx := &Context{Source: src, args: map[string]any{}}
+	for _, a := range cmd.Args {
+		if len(rest) == 0 {
+			if IsOptional(a) {
+				break
+			}
+			return &Error{Kind: ErrMissingArgument, Msg: "missing " + a.Name(), Start: len(line), End: len(line), Usage: cmd.Usage()}
+		}
+		n := a.Width()
+		if n == 0 {
+			n = len(rest)
+		}
+		if len(rest) < n {
+			e := &Error{Kind: ErrMissingArgument, Msg: fmt.Sprintf("%s needs %d values", a.Name(), n), Start: rest[0].Start, End: len(line)}
+			e.Usage = cmd.Usage()
+			return e
+		}
+		v, perr := a.Parse(src, rest[:n])
+		if perr != nil {
+			perr.Usage = cmd.Usage()
+			return perr
+		}
+		ctx.args[a.Name()] = v
+		rest = rest[n:]
+	}
+	if len(rest) > 0 {
+		e := &Error{Kind: ErrTooManyArguments, Msg: "unexpected " + strings.TrimSpace(line[rest[0].Start:]), Start: rest[0].Start, End: len(line)}
+		e.Usage = cmd.Usage()
+		return e
+	}
+	if err := cmd.Run(ctx); err != nil {
+		if cerr, ok := err.(*Error); ok {
+			return cerr
+		}
+		return Failed("%v", err)
+	}
+	return nil
+}
+
+// Suggest returns completions for the last (possibly empty) word of a partial line.
+func (r *Registry) Suggest(src Source, line string) []string {
+	line = strings.TrimPrefix(line, "/")
+	toks, err := Tokenize(line)
+	if err != nil {
+		return nil
+	}
+	partial := ""
+	if len(toks) > 0 && !strings.HasSuffix(line, " ") {
+		partial = toks[len(toks)-1].Text
+		toks = toks[:len(toks)-1]
+	}
+
+	if len(toks) == 0 {
+		var names []string
+		for _, c := range r.Commands(src) {
+			names = append(names, c.Name)
+		}
+		return filterPrefix(names, partial)
+	}
+	cmd, ok := r.lookup(strings.ToLower(toks[0].Text))
+	if !ok || src.Permission() < cmd.Permission {
+		return nil
+	}
+	rest := toks[1:]
+	for len(cmd.Subcommands) > 0 {
+		if len(rest) == 0 {
+			var names []string
+			for _, s := range cmd.Subcommands {
+				if src.Permission() >= s.Permission {
+					names = append(names, s.Name)
+				}
+			}
+			return filterPrefix(names, partial)
+		}
+		cmd = cmd.subcommand(strings.ToLower(rest[0].Text))
+		if cmd == nil || src.Permission() < cmd.Permission {
+			return nil
+		}
+		rest = rest[1:]
+	}
+
+	used := len(rest)
+	for _, a := range cmd.Args {
+		n := a.Width()
+		if n == 0 {
+			return a.Suggest(src, used, partial)
+		}
+		if used < n {
+			return a.Suggest(src, used, partial)
+		}
+		used -= n
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+type testPlayer struct {
+	pos     vec.Vec3
+	perm    int
+	replies []string
+}
+
+func (p *testPlayer) Name() string               { return "steve" }
+func (p *testPlayer) Permission() int            { return p.perm }
+func (p *testPlayer) Position() (vec.Vec3, bool) { return p.pos, true }
+func (p *testPlayer) Reply(msg string)           { p.replies = append(p.replies, msg) }
+func (p *testPlayer) Teleport(pos vec.Vec3) error {
+	p.pos = pos
+	return nil
+}
+
+func setup(t *testing.T) (*Registry, *world.World) {
+	t.Helper()
+	w := world.New(42)
+	reg := NewRegistry()
+	if err := RegisterCore(reg, w); err != nil {
+		t.Fatalf("RegisterCore() err: %v", err)
+	}
+	return reg, w
+}
+
+func errKind(err error) (ErrorKind, bool) {
+	var cerr *Error
+	if !errors.As(err, &cerr) {
+		return 0, false
+	}
+	return cerr.Kind, true
+}
+
+func TestTokenize(t *testing.T) {
+	t.Parallel()
+	toks, err := Tokenize(`say  "hello world" ~1`)
+	if err != nil {
+		t.Fatalf("Tokenize() err: %v", err)
+	}
+	want := []Token{
+		{Text: "say", Start: 0, End: 3},
+		{Text: "hello world", Start: 5, End: 18},
+		{Text: "~1", Start: 19, End: 21},
+	}
+	if !reflect.DeepEqual(toks, want) {
+		t.Errorf("got %+v, want %+v", toks, want)
+	}
+	if _, err := Tokenize(`say "oops`); err == nil {
+		t.Errorf("expected error for unterminated quote")
+	}
+}
+
+func TestExecute(t *testing.T) {
+	t.Parallel()
+	reg, w := setup(t)
+	op := &testPlayer{pos: vec.Vec3{X: 10.7, Y: 64, Z: -3.2}, perm: PermGameMaster}
+
+	t.Run("Relative setblock", func(t *testing.T) {
+		if err := reg.Execute(op, "/setblock ~ ~1 ~-1 minecraft:stone"); err != nil {
+			t.Fatalf("Execute() err: %v", err)
+		}
+		if got := w.BlockAt(vec.IntVec3{X: 10, Y: 65, Z: -5}); got != blocks.Stone {
+			t.Errorf("expected stone, got %v", got)
+		}
+	})
+
+	t.Run("Fill", func(t *testing.T) {
+		if err := reg.Execute(op, "fill 0 100 0 2 101 -2 sand"); err != nil {
+			t.Fatalf("Execute() err: %v", err)
+		}
+		if got := w.BlockAt(vec.IntVec3{X: 1, Y: 101, Z: -1}); got != blocks.Sand {
+			t.Errorf("expected sand, got %v", got)
+		}
+		if !strings.Contains(op.replies[len(op.replies)-1], "18") {
+			t.Errorf("expected 18 blocks filled, got %q", op.replies[len(op.replies)-1])
+		}
+		if kind, _ := errKind(reg.Execute(op, "fill 0 0 0 100 100 100 air")); kind != ErrFailed {
+			t.Errorf("expected oversized fill to fail, got %v", kind)
+		}
+		// 2^21 * 2^21 * 2^22 wraps to 0 if multiplied unchecked
+		if kind, _ := errKind(reg.Execute(op, "fill 0 0 0 2097151 2097151 4194303 stone")); kind != ErrFailed {
+			t.Errorf("expected overflowing fill to fail, got %v", kind)
+		}
+		if kind, _ := errKind(reg.Execute(op, "fill -9223372036854775808 0 0 9223372036854775807 0 0 stone")); kind != ErrFailed {
+			t.Errorf("expected fill across every int to fail, got %v", kind)
+		}
+	})
+
+	t.Run("Teleport", func(t *testing.T) {
+		if err := reg.Execute(op, "tp 5 70 ~"); err != nil {
+			t.Fatalf("Execute() err: %v", err)
+		}
+		if op.pos != (vec.Vec3{X: 5.5, Y: 70, Z: -3.5}) {
+			t.Errorf("got position %v", op.pos)
+		}
+		console := &ConsoleSource{Out: &bytes.Buffer{}}
+		if kind, _ := errKind(reg.Execute(console, "tp 0 0 0")); kind != ErrFailed {
+			t.Errorf("console should not be teleportable, got %v", kind)
+		}
+	})
+
+	t.Run("Time and seed", func(t *testing.T) {
+		if err := reg.Execute(op, "time set noon"); err != nil {
+			t.Fatalf("Execute() err: %v", err)
+		}
+		if w.Clock().TimeOfDay() != 6000 {
+			t.Errorf("expected noon, got %d", w.Clock().TimeOfDay())
+		}
+		if err := reg.Execute(op, "seed"); err != nil {
+			t.Fatalf("Execute() err: %v", err)
+		}
+		if op.replies[len(op.replies)-1] != "Seed: 42" {
+			t.Errorf("got %q", op.replies[len(op.replies)-1])
+		}
+	})
+
+	for _, tc := range []struct {
+		name  string
+		src   Source
+		line  string
+		want  ErrorKind
+		start int
+	}{
+		{name: "Unknown command", src: op, line: "nope", want: ErrUnknownCommand, start: 0},
+		{name: "Bad block", src: op, line: "setblock 1 2 3 bedrockk", want: ErrBadArgument, start: 15},
+		{name: "Bad int", src: op, line: "setblock 1 x 3 stone", want: ErrBadArgument, start: 11},
+		{name: "Missing block", src: op, line: "setblock 1 2 3", want: ErrMissingArgument},
+		{name: "Half coords", src: op, line: "setblock 1 2", want: ErrMissingArgument},
+		{name: "Too many", src: op, line: "seed 1", want: ErrTooManyArguments, start: 5},
+		{name: "Unknown subcommand", src: op, line: "time warp", want: ErrUnknownCommand, start: 5},
+		{name: "Permission", src: &testPlayer{}, line: "setblock 1 2 3 stone", want: ErrPermission},
+		{name: "Subcommand permission", src: &testPlayer{}, line: "time set day", want: ErrPermission},
+		{name: "Console relative", src: &ConsoleSource{Out: &bytes.Buffer{}}, line: "setblock ~ 0 0 stone", want: ErrBadArgument, start: 9},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := reg.Execute(tc.src, tc.line)
+			var cerr *Error
+			if !errors.As(err, &cerr) {
+				t.Fatalf("expected *Error, got %v", err)
+			}
+			if cerr.Kind != tc.want {
+				t.Errorf("got kind %v, want %v (%v)", cerr.Kind, tc.want, err)
+			}
+			if tc.start != 0 && cerr.Start != tc.start {
+				t.Errorf("error starts at %d, want %d", cerr.Start, tc.start)
+			}
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		in   string
+		want time.Duration
+	}{
+		{"90s", 90 * time.Second},
+		{"1h30m", 90 * time.Minute},
+		{"2d", 48 * time.Hour},
+		{"1w", 168 * time.Hour},
+	} {
+		if got, err := parseDuration(tc.in); err != nil || got != tc.want {
+			t.Errorf("parseDuration(%q) = %v, %v, want %v", tc.in, got, err, tc.want)
+		}
+	}
+	for _, bad := range []string{"", "d", "1.5d", "soon"} {
+		if _, err := parseDuration(bad); err == nil {
+			t.Errorf("parseDuration(%q) expected error", bad)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	t.Parallel()
+	reg, _ := setup(t)
+	op := &testPlayer{perm: PermOwner}
+	for _, tc := range []struct {
+		src  Source
+		line string
+		want []string
+	}{
+		{src: op, line: "/s", want: []string{"seed", "setblock"}},
+		{src: &testPlayer{}, line: "s"},
+		{src: op, line: "time ", want: []string{"query", "set"}},
+		{src: op, line: "time set n", want: []string{"night", "noon"}},
+		{src: op, line: "setblock ", want: []string{"~"}},
+		{src: op, line: "setblock 1 2 3 s", want: []string{"sand", "stone"}},
+		{src: op, line: "fill 0 0 0 1 1 1 gr", want: []string{"grass"}},
+	} {
+		got := reg.Suggest(tc.src, tc.line)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Suggest(%q) = %v, want %v", tc.line, got, tc.want)
+		}
+	}
+}
+
+func TestRunConsole(t *testing.T) {
+	t.Parallel()
+	reg, w := setup(t)
+	out := &bytes.Buffer{}
+	in := strings.NewReader("setblock 0 200 0 wood\n\nbogus\n")
+	if err := RunConsole(context.Background(), in, reg, &ConsoleSource{Out: out}); err != nil {
+		t.Fatalf("RunConsole() err: %v", err)
+	}
+	if w.BlockAt(vec.IntVec3{Y: 200}) != blocks.Wood {
+		t.Errorf("console command did not run")
+	}
+	if !strings.Contains(out.String(), "unknown command") {
+		t.Errorf("expected error to be reported, got %q", out.String())
+	}
+}
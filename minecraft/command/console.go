@@ -0,0 +1,55 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+)
+
+// RunConsole executes one command per line from in until it is exhausted or ctx is done.
+// Errors are reported back to src rather than stopping the console.
+func RunConsole(ctx context.Context, in io.Reader, reg *Registry, src Source) error {
+	lines := make(chan string)
+	scanErr := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(in)
+	scan:
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				break scan
+			}
+		}
+		scanErr <- scanner.Err()
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case line, ok := <-lines:
+			if !ok {
+				return <-scanErr
+			}
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if err := reg.Execute(src, line); err != nil {
+				src.Reply(err.Error())
+			}
+		}
+	}
+}
+
+// HandleChat runs msg as a command if it starts with a slash, reporting whether it did.
+func HandleChat(reg *Registry, src Source, msg string) bool {
+	if !strings.HasPrefix(msg, "/") {
+		return false
+	}
+	if err := reg.Execute(src, msg); err != nil {
+		src.Reply(err.Error())
+	}
+	return true
+}
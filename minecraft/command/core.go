@@ -0,0 +1,180 @@
+package command
+
+import (
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/dragon1672/go-mine/minecraft/world/worldtime"
+)
+
+// MaxFillVolume caps /fill so a typo can't stall the server.
+const MaxFillVolume = 32768
+
+// RegisterCore adds the built in world commands to reg.
+func RegisterCore(reg *Registry, w *world.World) error {
+	for _, cmd := range []*Command{
+		helpCommand(reg),
+		setblockCommand(w),
+		fillCommand(w),
+		tpCommand(),
+		seedCommand(w),
+		timeCommand(w.Clock()),
+	} {
+		if err := reg.Register(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func helpCommand(reg *Registry) *Command {
+	return &Command{
+		Name:        "help",
+		Description: "lists the commands you can run",
+		Permission:  PermAll,
+		Run: func(ctx *Context) error {
+			for _, c := range reg.Commands(ctx.Source) {
+				ctx.Replyf("/%s - %s", c.Usage(), c.Description)
+			}
+			return nil
+		},
+	}
+}
+
+func setblockCommand(w *world.World) *Command {
+	return &Command{
+		Name:        "setblock",
+		Description: "places a single block",
+		Permission:  PermGameMaster,
+		Args:        []Arg{Coords("pos"), Block("block")},
+		Run: func(ctx *Context) error {
+			pos, b := ctx.Pos("pos"), ctx.Block("block")
+			w.SetBlockBy(pos, b, ctx.Source.Name())
+			ctx.Replyf("Set %v to %v", pos, b)
+			return nil
+		},
+	}
+}
+
+// cuboid orders two corners into min and max.
+func cuboid(a, b vec.IntVec3) (lo, hi vec.IntVec3) {
+	lo = vec.IntVec3{X: min(a.X, b.X), Y: min(a.Y, b.Y), Z: min(a.Z, b.Z)}
+	hi = vec.IntVec3{X: max(a.X, b.X), Y: max(a.Y, b.Y), Z: max(a.Z, b.Z)}
+	return lo, hi
+}
+
+// fillSize is the box's size in blocks, ok is false if it holds more than MaxFillVolume.
+// Each side is checked before multiplying so a huge box can't overflow into a small one.
+func fillSize(lo, hi vec.IntVec3) (size vec.IntVec3, ok bool) {
+	volume := 1
+	sides := [3]*int{&size.X, &size.Y, &size.Z}
+	for i, d := range [3]int{hi.X - lo.X, hi.Y - lo.Y, hi.Z - lo.Z} {
+		// d overflows int for far apart corners, but as hi >= lo it's right as a uint
+		if uint(d) >= MaxFillVolume {
+			return size, false
+		}
+		*sides[i] = d + 1
+		if volume *= d + 1; volume > MaxFillVolume {
+			return size, false
+		}
+	}
+	return size, true
+}
+
+func fillCommand(w *world.World) *Command {
+	return &Command{
+		Name:        "fill",
+		Description: "fills the box between two corners",
+		Permission:  PermGameMaster,
+		Args:        []Arg{Coords("from"), Coords("to"), Block("block")},
+		Run: func(ctx *Context) error {
+			lo, hi := cuboid(ctx.Pos("from"), ctx.Pos("to"))
+			b := ctx.Block("block")
+			size, ok := fillSize(lo, hi)
+			if !ok {
+				return Failed("the box is more than the limit of %d blocks", MaxFillVolume)
+			}
+			changed := 0
+			// counting from lo rather than up to hi, which may be the largest int
+			for x := 0; x < size.X; x++ {
+				for y := 0; y < size.Y; y++ {
+					for z := 0; z < size.Z; z++ {
+						p := lo.Add(vec.IntVec3{X: x, Y: y, Z: z})
+						if w.BlockAt(p) != b {
+							w.SetBlockBy(p, b, ctx.Source.Name())
+							changed++
+						}
+					}
+				}
+			}
+			ctx.Replyf("Filled %d blocks with %v", changed, b)
+			return nil
+		},
+	}
+}
+
+func tpCommand() *Command {
+	return &Command{
+		Name:        "tp",
+		Description: "teleports you to a block position",
+		Permission:  PermGameMaster,
+		Args:        []Arg{Coords("pos")},
+		Run: func(ctx *Context) error {
+			t, ok := ctx.Source.(Teleporter)
+			if !ok {
+				return Failed("%s can not be teleported", ctx.Source.Name())
+			}
+			p := ctx.Pos("pos")
+			// stand in the middle of the block rather than on its corner
+			dest := vec.Vec3{X: float64(p.X) + 0.5, Y: float64(p.Y), Z: float64(p.Z) + 0.5}
+			if err := t.Teleport(dest); err != nil {
+				return err
+			}
+			ctx.Replyf("Teleported %s to %v", ctx.Source.Name(), p)
+			return nil
+		},
+	}
+}
+
+func seedCommand(w *world.World) *Command {
+	return &Command{
+		Name:        "seed",
+		Description: "shows the world seed",
+		Permission:  PermGameMaster,
+		Run: func(ctx *Context) error {
+			ctx.Replyf("Seed: %d", w.Seed())
+			return nil
+		},
+	}
+}
+
+func timeCommand(clock *worldtime.Clock) *Command {
+	return &Command{
+		Name:        "time",
+		Description: "shows or changes the time of day",
+		Permission:  PermAll,
+		Subcommands: []*Command{
+			{
+				Name:       "set",
+				Permission: PermGameMaster,
+				Args:       []Arg{Word("time", "day", "noon", "sunset", "night", "midnight", "sunrise")},
+				Run: func(ctx *Context) error {
+					t, err := worldtime.ParseTimeOfDay(ctx.String("time"), clock.DayLength())
+					if err != nil {
+						return Failed("%v", err)
+					}
+					clock.SetTimeOfDay(t)
+					ctx.Replyf("Set the time to %d", clock.TimeOfDay())
+					return nil
+				},
+			},
+			{
+				Name:       "query",
+				Permission: PermAll,
+				Run: func(ctx *Context) error {
+					ctx.Replyf("Day %d, time %d", clock.Day(), clock.TimeOfDay())
+					return nil
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,61 @@
+package command
+
+import "fmt"
+
+type ErrorKind int
+
+const (
+	ErrSyntax ErrorKind = iota
+	ErrUnknownCommand
+	ErrPermission
+	ErrMissingArgument
+	ErrBadArgument
+	ErrTooManyArguments
+	ErrFailed
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrSyntax:
+		return "syntax error"
+	case ErrUnknownCommand:
+		return "unknown command"
+	case ErrPermission:
+		return "permission denied"
+	case ErrMissingArgument:
+		return "missing argument"
+	case ErrBadArgument:
+		return "bad argument"
+	case ErrTooManyArguments:
+		return "too many arguments"
+	case ErrFailed:
+		return "command failed"
+	}
+	return "unknown"
+}
+
+// Error is a structured command error. Start and End point at the offending
+// part of the input so UIs can underline it, both are -1 if it is not tied to the input.
+type Error struct {
+	Kind       ErrorKind
+	Msg        string
+	Start, End int
+	Usage      string
+}
+
+func (e *Error) Error() string {
+	s := fmt.Sprintf("%v: %s", e.Kind, e.Msg)
+	if e.Usage != "" {
+		s += fmt.Sprintf(" (usage: %s)", e.Usage)
+	}
+	return s
+}
+
+func tokenError(kind ErrorKind, tok Token, format string, args ...any) *Error {
+	return &Error{Kind: kind, Msg: fmt.Sprintf(format, args...), Start: tok.Start, End: tok.End}
+}
+
+// Failed is returned by commands that parsed fine but could not run.
+func Failed(format string, args ...any) *Error {
+	return &Error{Kind: ErrFailed, Msg: fmt.Sprintf(format, args...), Start: -1, End: -1}
+}
@@ -0,0 +1,41 @@
+package command
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+)
+
+// Permission levels, following Minecraft's op levels.
+const (
+	PermAll = iota
+	PermModerator
+	PermGameMaster
+	PermAdmin
+	PermOwner
+)
+
+// Source is whoever is running a command, a player in chat or a console.
+type Source interface {
+	Name() string
+	Permission() int
+	// Position is where relative coordinates are measured from, ok is false for consoles.
+	Position() (pos vec.Vec3, ok bool)
+	Reply(msg string)
+}
+
+// Teleporter is implemented by sources that can be moved, see the tp command.
+type Teleporter interface {
+	Teleport(pos vec.Vec3) error
+}
+
+// ConsoleSource runs commands at owner level and writes replies to Out.
+type ConsoleSource struct {
+	Out io.Writer
+}
+
+func (c *ConsoleSource) Name() string               { return "Server" }
+func (c *ConsoleSource) Permission() int            { return PermOwner }
+func (c *ConsoleSource) Position() (vec.Vec3, bool) { return vec.Vec3{}, false }
+func (c *ConsoleSource) Reply(msg string)           { fmt.Fprintln(c.Out, msg) }
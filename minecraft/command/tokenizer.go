@@ -0,0 +1,53 @@
+package command
+
+import "strings"
+
+// Token is one word of a command line, Start and End are byte offsets into the line.
+type Token struct {
+	Text       string
+	Start, End int
+}
+
+// Tokenize splits a command line on spaces. Double quotes group words and
+// backslash escapes the next character inside quotes.
+func Tokenize(line string) ([]Token, error) {
+	var (
+		toks    []Token
+		cur     strings.Builder
+		start   = -1
+		quoted  bool
+		escaped bool
+	)
+	for i, r := range line {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			if start == -1 {
+				start = i
+			}
+			quoted = !quoted
+		case r == ' ' && !quoted:
+			if start != -1 {
+				toks = append(toks, Token{Text: cur.String(), Start: start, End: i})
+				cur.Reset()
+				start = -1
+			}
+		default:
+			if start == -1 {
+				start = i
+			}
+			cur.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, &Error{Kind: ErrSyntax, Msg: "unterminated quote", Start: start, End: len(line)}
+	}
+	if start != -1 {
+		toks = append(toks, Token{Text: cur.String(), Start: start, End: len(line)})
+	}
+	return toks, nil
+}
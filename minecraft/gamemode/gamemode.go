@@ -0,0 +1,99 @@
+// Package gamemode defines what players may do in each game mode. Physics, mining and
+// inventory code take the Abilities for a mode rather than checking the mode itself.
+package gamemode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dragon1672/go-mine/minecraft/physics"
+)
+
+type Mode uint8
+
+const (
+	Survival Mode = iota
+	Creative
+	Spectator
+)
+
+// Modes is every mode, in order.
+var Modes = []Mode{Survival, Creative, Spectator}
+
+func (m Mode) String() string {
+	switch m {
+	case Survival:
+		return "survival"
+	case Creative:
+		return "creative"
+	case Spectator:
+		return "spectator"
+	}
+	return fmt.Sprintf("mode(%d)", uint8(m))
+}
+
+// Parse reads a mode by name, case insensitively.
+func Parse(s string) (Mode, error) {
+	for _, m := range Modes {
+		if strings.EqualFold(s, m.String()) {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown game mode %q", s)
+}
+
+func (m Mode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Mode) UnmarshalText(b []byte) error {
+	v, err := Parse(string(b))
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// Abilities is everything a mode allows.
+type Abilities struct {
+	Movement physics.Movement
+	// Interact allows breaking and placing blocks.
+	Interact bool
+	// InstantBreak breaks blocks in one tick without dropping anything.
+	InstantBreak bool
+	// InfiniteItems places blocks and uses items without using them up.
+	InfiniteItems bool
+	// Invulnerable players take no damage.
+	Invulnerable bool
+}
+
+func (m Mode) Abilities() Abilities {
+	switch m {
+	case Creative:
+		return Abilities{
+			Movement:      physics.Movement{Fly: true},
+			Interact:      true,
+			InstantBreak:  true,
+			InfiniteItems: true,
+			Invulnerable:  true,
+		}
+	case Spectator:
+		return Abilities{
+			Movement:     physics.Movement{NoClip: true},
+			Invulnerable: true,
+		}
+	}
+	return Abilities{Interact: true}
+}
+
+// MaxHealth is a full health bar, in half hearts.
+const MaxHealth = 20
+
+// safeFall is how many blocks can be fallen without damage.
+const safeFall = 3
+
+// FallDamage is the health lost landing after falling distance blocks.
+func FallDamage(distance float64) int {
+	return max(int(distance)-safeFall, 0)
+}
@@ -0,0 +1,42 @@
+package gamemode
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	for _, m := range Modes {
+		if got, err := Parse(m.String()); got != m || err != nil {
+			t.Errorf("Parse(%q) = %v, %v", m.String(), got, err)
+		}
+	}
+	if got, err := Parse("CREATIVE"); got != Creative || err != nil {
+		t.Errorf("Parse() should ignore case, got %v, %v", got, err)
+	}
+	if _, err := Parse("adventure"); err == nil {
+		t.Errorf("expected error for an unknown mode")
+	}
+
+	data, err := json.Marshal(map[string]Mode{"mode": Spectator})
+	if err != nil || string(data) != `{"mode":"spectator"}` {
+		t.Errorf("Marshal() = %s, %v", data, err)
+	}
+	var got map[string]Mode
+	if err := json.Unmarshal(data, &got); err != nil || got["mode"] != Spectator {
+		t.Errorf("Unmarshal() = %v, %v", got, err)
+	}
+}
+
+func TestFallDamage(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		distance float64
+		want     int
+	}{{0, 0}, {3.9, 0}, {4, 1}, {23.5, 20}} {
+		if got := FallDamage(tc.distance); got != tc.want {
+			t.Errorf("FallDamage(%v) = %d, want %d", tc.distance, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package crafting
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/dragon1672/go-mine/minecraft/items"
+)
+
+// DefaultRecipes are the built in recipe files, see LoadDir.
+//
+//go:embed recipes/*.json
+var DefaultRecipes embed.FS
+
+// recipeFile is the on disk format, loosely following Minecraft's data pack recipes.
+type recipeFile struct {
+	Type        string              `json:"type"`
+	Pattern     []string            `json:"pattern"`
+	Key         map[string]items.ID `json:"key"`
+	Ingredients []items.ID          `json:"ingredients"`
+	Result      items.Stack         `json:"result"`
+}
+
+// Book holds every known recipe.
+type Book struct {
+	reg     *items.Registry
+	recipes []Recipe
+}
+
+func NewBook(reg *items.Registry) *Book {
+	return &Book{reg: reg}
+}
+
+// LoadDir reads every *.json recipe in dir, the file name becomes the recipe ID.
+// Later files replace earlier recipes with the same ID so mods can override the defaults.
+func (b *Book) LoadDir(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("error reading recipe dir %q: %v", dir, err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".json" {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return fmt.Errorf("error reading recipe %q: %v", e.Name(), err)
+		}
+		r, err := b.parse(strings.TrimSuffix(e.Name(), ".json"), data)
+		if err != nil {
+			return err
+		}
+		b.Add(r)
+	}
+	return nil
+}
+
+func (b *Book) parse(id string, data []byte) (Recipe, error) {
+	var f recipeFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("recipe %s: %v", id, err)
+	}
+	if f.Result.Count == 0 {
+		f.Result.Count = 1
+	}
+	used := []items.ID{f.Result.Item}
+	var r Recipe
+	switch f.Type {
+	case "shaped":
+		key := map[rune]items.ID{}
+		for k, v := range f.Key {
+			runes := []rune(k)
+			if len(runes) != 1 {
+				return nil, fmt.Errorf("recipe %s: key %q must be a single character", id, k)
+			}
+			key[runes[0]] = v
+			used = append(used, v)
+		}
+		s, err := NewShaped(id, f.Pattern, key, f.Result)
+		if err != nil {
+			return nil, err
+		}
+		r = s
+	case "shapeless":
+		used = append(used, f.Ingredients...)
+		s, err := NewShapeless(id, f.Ingredients, f.Result)
+		if err != nil {
+			return nil, err
+		}
+		r = s
+	default:
+		return nil, fmt.Errorf("recipe %s: unknown type %q", id, f.Type)
+	}
+	for _, item := range used {
+		if _, ok := b.reg.Get(item); !ok {
+			return nil, fmt.Errorf("recipe %s: unknown item %q", id, item)
+		}
+	}
+	return r, nil
+}
+
+// Add registers r, replacing any recipe with the same ID.
+func (b *Book) Add(r Recipe) {
+	for i, existing := range b.recipes {
+		if existing.ID() == r.ID() {
+			b.recipes[i] = r
+			return
+		}
+	}
+	b.recipes = append(b.recipes, r)
+}
+
+func (b *Book) Recipes() []Recipe {
+	return b.recipes
+}
+
+// Match finds the recipe for the current grid contents.
+func (b *Book) Match(g *Grid) (Recipe, bool) {
+	for _, r := range b.recipes {
+		if r.Matches(g) {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// Craft takes one item out of every slot and returns the result.
+func (b *Book) Craft(g *Grid) (items.Stack, error) {
+	r, ok := b.Match(g)
+	if !ok {
+		return items.Stack{}, fmt.Errorf("no recipe matches the grid")
+	}
+	for i, s := range g.Slots {
+		if !s.IsEmpty() {
+			_, g.Slots[i] = s.Take(1)
+		}
+	}
+	return r.Result(), nil
+}
+
+// Craftable answers "what can I craft with this inventory", sorted by recipe ID.
+func (b *Book) Craftable(inv *items.Inventory) []Recipe {
+	var out []Recipe
+	for _, r := range b.recipes {
+		ok := true
+		for id, n := range r.Ingredients() {
+			if inv.Count(id) < n {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			out = append(out, r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID() < out[j].ID() })
+	return out
+}
+
+// Uses lists the recipes that need id as an ingredient.
+func (b *Book) Uses(id items.ID) []Recipe {
+	var out []Recipe
+	for _, r := range b.recipes {
+		if r.Ingredients()[id] > 0 {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// DefaultBook loads the built in recipes.
+func DefaultBook(reg *items.Registry) (*Book, error) {
+	b := NewBook(reg)
+	if err := b.LoadDir(DefaultRecipes, "recipes"); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
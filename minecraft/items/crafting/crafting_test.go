@@ -0,0 +1,167 @@
+package crafting
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/dragon1672/go-mine/minecraft/items"
+)
+
+const (
+	wood   = items.ID("wood")
+	planks = items.ID("planks")
+	sand   = items.ID("sand")
+	leaves = items.ID("leaves")
+	dirt   = items.ID("dirt")
+	stick  = items.Stick
+)
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+	book, err := DefaultBook(items.DefaultRegistry())
+	if err != nil {
+		t.Fatalf("DefaultBook() err: %v", err)
+	}
+	for _, tc := range []struct {
+		name string
+		grid *Grid
+		want items.Stack
+	}{
+		{
+			name: "Wood to planks",
+			grid: GridOf([]items.ID{"", wood}, []items.ID{"", ""}),
+			want: items.NewStack(planks, 4),
+		},
+		{
+			name: "Planks to sticks",
+			grid: GridOf([]items.ID{"", planks, ""}, []items.ID{"", planks, ""}, []items.ID{"", "", ""}),
+			want: items.NewStack(stick, 4),
+		},
+		{
+			name: "Sticks in the bottom corner",
+			grid: GridOf([]items.ID{"", "", ""}, []items.ID{"", "", planks}, []items.ID{"", "", planks}),
+			want: items.NewStack(stick, 4),
+		},
+		{
+			name: "Sideways sticks",
+			grid: GridOf([]items.ID{planks, planks}, []items.ID{"", ""}),
+		},
+		{
+			name: "2x2 in a 3x3",
+			grid: GridOf([]items.ID{"", "", ""}, []items.ID{sand, sand, ""}, []items.ID{sand, sand, ""}),
+			want: items.NewStack("stone", 1),
+		},
+		{
+			name: "2x2 with extra item",
+			grid: GridOf([]items.ID{"", "", sand}, []items.ID{sand, sand, ""}, []items.ID{sand, sand, ""}),
+		},
+		{
+			name: "Shaped as written",
+			grid: GridOf([]items.ID{"", leaves}, []items.ID{stick, ""}),
+			want: items.NewStack("flower", 1),
+		},
+		{
+			name: "Shaped mirrored",
+			grid: GridOf([]items.ID{leaves, ""}, []items.ID{"", stick}),
+			want: items.NewStack("flower", 1),
+		},
+		{
+			name: "Shaped upside down",
+			grid: GridOf([]items.ID{stick, ""}, []items.ID{"", leaves}),
+		},
+		{
+			name: "Shapeless any order",
+			grid: GridOf([]items.ID{leaves, "", ""}, []items.ID{"", "", ""}, []items.ID{"", "", dirt}),
+			want: items.NewStack("grass", 1),
+		},
+		{
+			name: "Shapeless missing ingredient",
+			grid: GridOf([]items.ID{leaves, ""}, []items.ID{"", ""}),
+		},
+		{
+			name: "Empty",
+			grid: NewGrid(3, 3),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, ok := book.Match(tc.grid)
+			if tc.want.IsEmpty() {
+				if ok {
+					t.Errorf("expected no match, got %s", r.ID())
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("expected %v, got no match", tc.want)
+			}
+			if r.Result() != tc.want {
+				t.Errorf("got %v, want %v", r.Result(), tc.want)
+			}
+		})
+	}
+}
+
+func TestCraftChain(t *testing.T) {
+	t.Parallel()
+	reg := items.DefaultRegistry()
+	book, err := DefaultBook(reg)
+	if err != nil {
+		t.Fatalf("DefaultBook() err: %v", err)
+	}
+	inv := items.NewInventory(reg, 9)
+	inv.Add(items.NewStack(wood, 1))
+
+	craftable := book.Craftable(inv)
+	if len(craftable) != 1 || craftable[0].ID() != "planks" {
+		t.Fatalf("expected only planks to be craftable, got %v", craftable)
+	}
+
+	g := GridOf([]items.ID{wood})
+	got, err := book.Craft(g)
+	if err != nil {
+		t.Fatalf("Craft() err: %v", err)
+	}
+	if !g.At(0, 0).IsEmpty() {
+		t.Errorf("expected wood to be consumed, got %v", g.At(0, 0))
+	}
+	_ = inv.Remove(wood, 1)
+	inv.Add(got)
+
+	craftable = book.Craftable(inv)
+	if len(craftable) != 1 || craftable[0].ID() != "stick" {
+		t.Fatalf("expected sticks to be craftable from planks, got %v", craftable)
+	}
+	if uses := book.Uses(planks); len(uses) != 4 {
+		t.Errorf("expected planks to be used by sticks and 3 tools, got %d", len(uses))
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	t.Parallel()
+	reg := items.DefaultRegistry()
+	t.Run("Override", func(t *testing.T) {
+		book, _ := DefaultBook(reg)
+		mod := fstest.MapFS{
+			"mod/planks.json": {Data: []byte(`{"type":"shapeless","ingredients":["wood"],"result":{"item":"planks","count":8}}`)},
+		}
+		if err := book.LoadDir(mod, "mod"); err != nil {
+			t.Fatalf("LoadDir() err: %v", err)
+		}
+		r, _ := book.Match(GridOf([]items.ID{wood}))
+		if r.Result().Count != 8 {
+			t.Errorf("expected modded recipe, got %v", r.Result())
+		}
+	})
+	for name, data := range map[string]string{
+		"Unknown item": `{"type":"shapeless","ingredients":["unobtainium"],"result":{"item":"stick"}}`,
+		"Bad key":      `{"type":"shaped","pattern":["#"],"key":{"X":"wood"},"result":{"item":"stick"}}`,
+		"Bad type":     `{"type":"smelting","result":{"item":"stick"}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			fsys := fstest.MapFS{"r/bad.json": {Data: []byte(data)}}
+			if err := NewBook(reg).LoadDir(fsys, "r"); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
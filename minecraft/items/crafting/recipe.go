@@ -0,0 +1,197 @@
+package crafting
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dragon1672/go-mine/minecraft/items"
+)
+
+// Grid is a crafting grid, slots are stored row by row.
+type Grid struct {
+	Width, Height int
+	Slots         []items.Stack
+}
+
+func NewGrid(width, height int) *Grid {
+	return &Grid{
+		Width:  width,
+		Height: height,
+		Slots:  make([]items.Stack, width*height),
+	}
+}
+
+// GridOf builds a grid from rows of item IDs, "" is an empty slot.
+func GridOf(rows ...[]items.ID) *Grid {
+	g := NewGrid(len(rows[0]), len(rows))
+	for y, row := range rows {
+		for x, id := range row {
+			g.Set(x, y, items.NewStack(id, 1))
+		}
+	}
+	return g
+}
+
+func (g *Grid) At(x, y int) items.Stack {
+	return g.Slots[y*g.Width+x]
+}
+
+func (g *Grid) Set(x, y int, s items.Stack) {
+	g.Slots[y*g.Width+x] = s
+}
+
+// bounds is the smallest box holding every non-empty slot.
+func (g *Grid) bounds() (x0, y0, x1, y1 int, empty bool) {
+	x0, y0, x1, y1 = g.Width, g.Height, -1, -1
+	for y := 0; y < g.Height; y++ {
+		for x := 0; x < g.Width; x++ {
+			if g.At(x, y).IsEmpty() {
+				continue
+			}
+			x0, y0 = min(x0, x), min(y0, y)
+			x1, y1 = max(x1, x), max(y1, y)
+		}
+	}
+	return x0, y0, x1, y1, x1 < 0
+}
+
+type Recipe interface {
+	ID() string
+	Result() items.Stack
+	Matches(g *Grid) bool
+	// Ingredients counts how many of each item a single craft consumes.
+	Ingredients() map[items.ID]int
+}
+
+// Shaped recipes need their ingredients in a pattern, which may sit anywhere in
+// a large enough grid and may be mirrored left to right.
+type Shaped struct {
+	id      string
+	width   int
+	height  int
+	pattern []items.ID
+	result  items.Stack
+}
+
+func NewShaped(id string, pattern []string, key map[rune]items.ID, result items.Stack) (*Shaped, error) {
+	if len(pattern) == 0 || len(pattern) > 3 {
+		return nil, fmt.Errorf("recipe %s: pattern must have 1 to 3 rows", id)
+	}
+	s := &Shaped{id: id, width: len([]rune(pattern[0])), height: len(pattern), result: result}
+	if s.width == 0 || s.width > 3 {
+		return nil, fmt.Errorf("recipe %s: pattern must have 1 to 3 columns", id)
+	}
+	for _, row := range pattern {
+		runes := []rune(row)
+		if len(runes) != s.width {
+			return nil, fmt.Errorf("recipe %s: pattern rows must all be %d wide", id, s.width)
+		}
+		for _, r := range runes {
+			if r == ' ' {
+				s.pattern = append(s.pattern, "")
+				continue
+			}
+			item, ok := key[r]
+			if !ok {
+				return nil, fmt.Errorf("recipe %s: pattern uses %q which is not in the key", id, r)
+			}
+			s.pattern = append(s.pattern, item)
+		}
+	}
+	return s, nil
+}
+
+func (s *Shaped) ID() string          { return s.id }
+func (s *Shaped) Result() items.Stack { return s.result }
+
+func (s *Shaped) Matches(g *Grid) bool {
+	x0, y0, x1, y1, empty := g.bounds()
+	if empty {
+		return false
+	}
+	// the pattern may contain empty edges so compare against every offset that fits
+	for oy := 0; oy+s.height <= g.Height; oy++ {
+		for ox := 0; ox+s.width <= g.Width; ox++ {
+			if x0 < ox || y0 < oy || x1 >= ox+s.width || y1 >= oy+s.height {
+				continue
+			}
+			if s.matchAt(g, ox, oy, false) || s.matchAt(g, ox, oy, true) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *Shaped) matchAt(g *Grid, ox, oy int, mirror bool) bool {
+	for y := 0; y < s.height; y++ {
+		for x := 0; x < s.width; x++ {
+			px := x
+			if mirror {
+				px = s.width - 1 - x
+			}
+			want := s.pattern[y*s.width+px]
+			got := g.At(ox+x, oy+y)
+			if (want == "") != got.IsEmpty() || (want != "" && got.Item != want) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (s *Shaped) Ingredients() map[items.ID]int {
+	out := map[items.ID]int{}
+	for _, id := range s.pattern {
+		if id != "" {
+			out[id]++
+		}
+	}
+	return out
+}
+
+// Shapeless recipes only care that the grid holds exactly their ingredients.
+type Shapeless struct {
+	id          string
+	ingredients []items.ID
+	result      items.Stack
+}
+
+func NewShapeless(id string, ingredients []items.ID, result items.Stack) (*Shapeless, error) {
+	if len(ingredients) == 0 || len(ingredients) > 9 {
+		return nil, fmt.Errorf("recipe %s: needs 1 to 9 ingredients", id)
+	}
+	sorted := append([]items.ID{}, ingredients...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return &Shapeless{id: id, ingredients: sorted, result: result}, nil
+}
+
+func (s *Shapeless) ID() string          { return s.id }
+func (s *Shapeless) Result() items.Stack { return s.result }
+
+func (s *Shapeless) Matches(g *Grid) bool {
+	var have []items.ID
+	for _, slot := range g.Slots {
+		if !slot.IsEmpty() {
+			have = append(have, slot.Item)
+		}
+	}
+	if len(have) != len(s.ingredients) {
+		return false
+	}
+	sort.Slice(have, func(i, j int) bool { return have[i] < have[j] })
+	for i := range have {
+		if have[i] != s.ingredients[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Shapeless) Ingredients() map[items.ID]int {
+	out := map[items.ID]int{}
+	for _, id := range s.ingredients {
+		out[id]++
+	}
+	return out
+}
@@ -0,0 +1,216 @@
+package items
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MaxInventorySize is the most slots a saved inventory may have, far more than any
+// container needs, so bad save data can't ask for a huge allocation.
+const MaxInventorySize = 1024
+
+// Inventory is a fixed size container of item stacks.
+type Inventory struct {
+	reg   *Registry
+	slots []Stack
+}
+
+func NewInventory(reg *Registry, size int) *Inventory {
+	return &Inventory{
+		reg:   reg,
+		slots: make([]Stack, size),
+	}
+}
+
+func (inv *Inventory) Size() int {
+	return len(inv.slots)
+}
+
+func (inv *Inventory) checkSlot(i int) error {
+	if i < 0 || i >= len(inv.slots) {
+		return fmt.Errorf("slot %d out of range [0, %d)", i, len(inv.slots))
+	}
+	return nil
+}
+
+func (inv *Inventory) Slot(i int) Stack {
+	if inv.checkSlot(i) != nil {
+		return Stack{}
+	}
+	return inv.slots[i]
+}
+
+func (inv *Inventory) Set(i int, s Stack) error {
+	if err := inv.checkSlot(i); err != nil {
+		return err
+	}
+	if s.IsEmpty() {
+		inv.slots[i] = Stack{}
+		return nil
+	}
+	if _, ok := inv.reg.Get(s.Item); !ok {
+		return fmt.Errorf("unknown item %q", s.Item)
+	}
+	if limit := inv.reg.MaxStack(s.Item); s.Count > limit {
+		return fmt.Errorf("stack of %d %s exceeds max stack size %d", s.Count, s.Item, limit)
+	}
+	inv.slots[i] = s
+	return nil
+}
+
+// addRange merges s into slots [from, to) then fills empty slots, returning what did not fit.
+func (inv *Inventory) addRange(s Stack, from, to int) Stack {
+	limit := inv.reg.MaxStack(s.Item)
+	for i := from; i < to && !s.IsEmpty(); i++ {
+		if slot := inv.slots[i]; !slot.IsEmpty() && slot.Item == s.Item && slot.Count < limit {
+			moved := min(limit-slot.Count, s.Count)
+			inv.slots[i].Count += moved
+			s = NewStack(s.Item, s.Count-moved)
+		}
+	}
+	for i := from; i < to && !s.IsEmpty(); i++ {
+		if inv.slots[i].IsEmpty() {
+			inv.slots[i], s = s.Take(limit)
+		}
+	}
+	return s
+}
+
+// Add puts s into the inventory, returning the leftover that did not fit.
+func (inv *Inventory) Add(s Stack) Stack {
+	if s.IsEmpty() {
+		return Stack{}
+	}
+	return inv.addRange(s, 0, len(inv.slots))
+}
+
+// Merge moves as many items as fit from slot from onto slot to.
+func (inv *Inventory) Merge(from, to int) error {
+	if err := inv.checkSlot(from); err != nil {
+		return err
+	}
+	if err := inv.checkSlot(to); err != nil {
+		return err
+	}
+	src, dst := inv.slots[from], inv.slots[to]
+	if from == to || src.IsEmpty() {
+		return nil
+	}
+	if !dst.CanMerge(src) {
+		return fmt.Errorf("cannot merge %v into %v", src, dst)
+	}
+	moved := min(inv.reg.MaxStack(src.Item)-dst.Count, src.Count)
+	if moved <= 0 {
+		return nil
+	}
+	inv.slots[to] = NewStack(src.Item, dst.Count+moved)
+	inv.slots[from] = NewStack(src.Item, src.Count-moved)
+	return nil
+}
+
+// Split moves n items from slot from into the empty slot to.
+func (inv *Inventory) Split(from, to, n int) error {
+	if err := inv.checkSlot(from); err != nil {
+		return err
+	}
+	if err := inv.checkSlot(to); err != nil {
+		return err
+	}
+	if !inv.slots[to].IsEmpty() {
+		return fmt.Errorf("slot %d is not empty", to)
+	}
+	if n <= 0 || n > inv.slots[from].Count {
+		return fmt.Errorf("cannot split %d from %v", n, inv.slots[from])
+	}
+	inv.slots[to], inv.slots[from] = inv.slots[from].Take(n)
+	return nil
+}
+
+// SplitHalf splits off the larger half of a stack into the empty slot to, like a right click.
+func (inv *Inventory) SplitHalf(from, to int) error {
+	if err := inv.checkSlot(from); err != nil {
+		return err
+	}
+	return inv.Split(from, to, (inv.slots[from].Count+1)/2)
+}
+
+func (inv *Inventory) Swap(i, j int) error {
+	if err := inv.checkSlot(i); err != nil {
+		return err
+	}
+	if err := inv.checkSlot(j); err != nil {
+		return err
+	}
+	inv.slots[i], inv.slots[j] = inv.slots[j], inv.slots[i]
+	return nil
+}
+
+// Count totals the number of id held across all slots.
+func (inv *Inventory) Count(id ID) int {
+	total := 0
+	for _, s := range inv.slots {
+		if s.Item == id {
+			total += s.Count
+		}
+	}
+	return total
+}
+
+// Remove takes n of id out of the inventory, failing without changes if there are not enough.
+func (inv *Inventory) Remove(id ID, n int) error {
+	if have := inv.Count(id); have < n {
+		return fmt.Errorf("need %d %s, only have %d", n, id, have)
+	}
+	for i := len(inv.slots) - 1; i >= 0 && n > 0; i-- {
+		if inv.slots[i].Item != id {
+			continue
+		}
+		var taken Stack
+		taken, inv.slots[i] = inv.slots[i].Take(n)
+		n -= taken.Count
+	}
+	return nil
+}
+
+type savedSlot struct {
+	Slot int `json:"slot"`
+	Stack
+}
+
+type savedInventory struct {
+	Size  int         `json:"size"`
+	Slots []savedSlot `json:"slots"`
+}
+
+func (inv *Inventory) MarshalJSON() ([]byte, error) {
+	saved := savedInventory{Size: len(inv.slots), Slots: []savedSlot{}}
+	for i, s := range inv.slots {
+		if !s.IsEmpty() {
+			saved.Slots = append(saved.Slots, savedSlot{Slot: i, Stack: s})
+		}
+	}
+	return json.Marshal(saved)
+}
+
+// UnmarshalJSON restores the slots, the inventory must already have a registry to validate against.
+func (inv *Inventory) UnmarshalJSON(data []byte) error {
+	var saved savedInventory
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	if inv.reg == nil {
+		return fmt.Errorf("inventory has no item registry")
+	}
+	if saved.Size < 0 || saved.Size > MaxInventorySize {
+		return fmt.Errorf("inventory size %d out of range [0, %d]", saved.Size, MaxInventorySize)
+	}
+	// load into a copy so a bad slot leaves the inventory as it was
+	loaded := NewInventory(inv.reg, saved.Size)
+	for _, s := range saved.Slots {
+		if err := loaded.Set(s.Slot, s.Stack); err != nil {
+			return fmt.Errorf("error loading slot %d: %v", s.Slot, err)
+		}
+	}
+	inv.slots = loaded.slots
+	return nil
+}
@@ -0,0 +1,164 @@
+package items
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+type ID string
+
+type ToolType int
+
+const (
+	NoTool ToolType = iota
+	Pickaxe
+	Shovel
+	Axe
+)
+
+func (t ToolType) String() string {
+	switch t {
+	case NoTool:
+		return "None"
+	case Pickaxe:
+		return "Pickaxe"
+	case Shovel:
+		return "Shovel"
+	case Axe:
+		return "Axe"
+	}
+	return "unknown"
+}
+
+// Tool describes how good an item is at breaking blocks.
+type Tool struct {
+	Type ToolType
+	// Level gates which blocks drop anything, 0 is wood and 1 is stone.
+	Level int
+	// Speed multiplies mining speed on blocks that prefer this tool type.
+	Speed float64
+}
+
+const DefaultMaxStack = 64
+
+type Item struct {
+	ID       ID
+	Name     string
+	MaxStack int
+	// Block is the block placed by this item, only meaningful if IsBlock is set.
+	Block   blocks.SimpleBlockType
+	IsBlock bool
+	Tool    Tool
+}
+
+// Non-block items
+const (
+	Stick         ID = "stick"
+	WoodenPickaxe ID = "wooden_pickaxe"
+	WoodenShovel  ID = "wooden_shovel"
+	WoodenAxe     ID = "wooden_axe"
+	StonePickaxe  ID = "stone_pickaxe"
+)
+
+// BlockID is the item ID derived from a block type.
+func BlockID(b blocks.SimpleBlockType) ID {
+	return ID(strings.ToLower(b.String()))
+}
+
+type Registry struct {
+	items   map[ID]*Item
+	byBlock map[blocks.SimpleBlockType]*Item
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		items:   map[ID]*Item{},
+		byBlock: map[blocks.SimpleBlockType]*Item{},
+	}
+}
+
+func (r *Registry) Register(it Item) error {
+	if it.ID == "" {
+		return fmt.Errorf("item must have an ID")
+	}
+	if _, ok := r.items[it.ID]; ok {
+		return fmt.Errorf("item %q already registered", it.ID)
+	}
+	if it.MaxStack <= 0 {
+		it.MaxStack = DefaultMaxStack
+	}
+	if it.Name == "" {
+		it.Name = string(it.ID)
+	}
+	r.items[it.ID] = &it
+	if it.IsBlock {
+		r.byBlock[it.Block] = &it
+	}
+	return nil
+}
+
+// RegisterBlocks registers a block item for each of the given block types.
+func (r *Registry) RegisterBlocks(types ...blocks.SimpleBlockType) error {
+	for _, b := range types {
+		if err := r.Register(Item{ID: BlockID(b), Name: b.String(), Block: b, IsBlock: true}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Registry) Get(id ID) (*Item, bool) {
+	it, ok := r.items[id]
+	return it, ok
+}
+
+func (r *Registry) ForBlock(b blocks.SimpleBlockType) (*Item, bool) {
+	it, ok := r.byBlock[b]
+	return it, ok
+}
+
+// MaxStack returns the stack limit for id, unknown items do not stack.
+func (r *Registry) MaxStack(id ID) int {
+	if it, ok := r.items[id]; ok {
+		return it.MaxStack
+	}
+	return 1
+}
+
+// All returns every registered item sorted by ID.
+func (r *Registry) All() []*Item {
+	out := make([]*Item, 0, len(r.items))
+	for _, it := range r.items {
+		out = append(out, it)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// DefaultRegistry holds an item for every placeable block plus the built in non-block items.
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+	for _, b := range blocks.Types() {
+		if b == blocks.Air || b.IsFluid() {
+			continue // fluids need buckets
+		}
+		if err := r.RegisterBlocks(b); err != nil {
+			panic(err) // static data, can only fail on programmer error
+		}
+	}
+	for _, it := range []Item{
+		{ID: Stick, Name: "Stick"},
+		{ID: WoodenPickaxe, Name: "Wooden Pickaxe", MaxStack: 1, Tool: Tool{Type: Pickaxe, Level: 0, Speed: 2}},
+		{ID: WoodenShovel, Name: "Wooden Shovel", MaxStack: 1, Tool: Tool{Type: Shovel, Level: 0, Speed: 2}},
+		{ID: WoodenAxe, Name: "Wooden Axe", MaxStack: 1, Tool: Tool{Type: Axe, Level: 0, Speed: 2}},
+		{ID: StonePickaxe, Name: "Stone Pickaxe", MaxStack: 1, Tool: Tool{Type: Pickaxe, Level: 1, Speed: 4}},
+	} {
+		if err := r.Register(it); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
@@ -0,0 +1,195 @@
+package items
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dragon1672/go-mine/minecraft/gamemode"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+func TestDefaultRegistry(t *testing.T) {
+	t.Parallel()
+	reg := DefaultRegistry()
+	grass, ok := reg.ForBlock(blocks.Grass)
+	if !ok || grass.ID != "grass" || !grass.IsBlock {
+		t.Errorf("expected derived grass block item, got %+v", grass)
+	}
+	if _, ok := reg.ForBlock(blocks.Air); ok {
+		t.Errorf("air should not have an item")
+	}
+	if _, ok := reg.ForBlock(blocks.Water); ok {
+		t.Errorf("fluids should not have block items")
+	}
+	if stick, ok := reg.Get(Stick); !ok || stick.IsBlock {
+		t.Errorf("expected non-block stick item, got %+v", stick)
+	}
+	if err := reg.Register(Item{ID: Stick}); err == nil {
+		t.Errorf("expected error registering duplicate item")
+	}
+}
+
+func TestInventory(t *testing.T) {
+	t.Parallel()
+	reg := DefaultRegistry()
+	dirt := BlockID(blocks.Dirt)
+	stone := BlockID(blocks.Stone)
+
+	t.Run("Add respects max stack", func(t *testing.T) {
+		inv := NewInventory(reg, 3)
+		left := inv.Add(NewStack(dirt, 150))
+		if inv.Slot(0).Count != 64 || inv.Slot(1).Count != 64 || inv.Slot(2).Count != 22 || !left.IsEmpty() {
+			t.Errorf("unexpected slots %v %v %v left %v", inv.Slot(0), inv.Slot(1), inv.Slot(2), left)
+		}
+		left = inv.Add(NewStack(dirt, 50))
+		if left.Count != 8 {
+			t.Errorf("expected 8 left over, got %v", left)
+		}
+	})
+
+	t.Run("Set rejects oversized stacks", func(t *testing.T) {
+		inv := NewInventory(reg, 1)
+		if err := inv.Set(0, NewStack(dirt, 65)); err == nil {
+			t.Errorf("expected error")
+		}
+		if err := inv.Set(0, NewStack("nope", 1)); err == nil {
+			t.Errorf("expected error for unknown item")
+		}
+	})
+
+	t.Run("Slot ops", func(t *testing.T) {
+		inv := NewInventory(reg, 4)
+		_ = inv.Set(0, NewStack(dirt, 60))
+		_ = inv.Set(1, NewStack(dirt, 10))
+		_ = inv.Set(2, NewStack(stone, 5))
+
+		if err := inv.Merge(1, 0); err != nil {
+			t.Fatalf("Merge() err: %v", err)
+		}
+		if inv.Slot(0).Count != 64 || inv.Slot(1).Count != 6 {
+			t.Errorf("after merge got %v %v", inv.Slot(0), inv.Slot(1))
+		}
+		if err := inv.Merge(2, 1); err == nil {
+			t.Errorf("expected error merging stone into dirt")
+		}
+		if err := inv.SplitHalf(2, 3); err != nil {
+			t.Fatalf("SplitHalf() err: %v", err)
+		}
+		if inv.Slot(2).Count != 2 || inv.Slot(3).Count != 3 {
+			t.Errorf("after split got %v %v", inv.Slot(2), inv.Slot(3))
+		}
+		if err := inv.Swap(0, 3); err != nil {
+			t.Fatalf("Swap() err: %v", err)
+		}
+		if inv.Slot(0).Item != stone || inv.Slot(3).Item != dirt {
+			t.Errorf("after swap got %v %v", inv.Slot(0), inv.Slot(3))
+		}
+		if err := inv.Remove(dirt, 70); err != nil {
+			t.Fatalf("Remove() err: %v", err)
+		}
+		if inv.Count(dirt) != 0 {
+			t.Errorf("expected all dirt removed, have %d", inv.Count(dirt))
+		}
+		if err := inv.Remove(stone, 6); err == nil {
+			t.Errorf("expected error removing more than held")
+		}
+	})
+
+	t.Run("Round trip", func(t *testing.T) {
+		inv := NewInventory(reg, 5)
+		_ = inv.Set(1, NewStack(dirt, 3))
+		_ = inv.Set(4, NewStack(Stick, 12))
+		data, err := json.Marshal(inv)
+		if err != nil {
+			t.Fatalf("Marshal() err: %v", err)
+		}
+		got := NewInventory(reg, 0)
+		if err := json.Unmarshal(data, got); err != nil {
+			t.Fatalf("Unmarshal() err: %v", err)
+		}
+		for i := 0; i < inv.Size(); i++ {
+			if got.Slot(i) != inv.Slot(i) {
+				t.Errorf("slot %d: got %v want %v", i, got.Slot(i), inv.Slot(i))
+			}
+		}
+	})
+
+	t.Run("Unmarshal rejects bad sizes", func(t *testing.T) {
+		for _, data := range []string{`{"size":-1,"slots":[]}`, `{"size":1000000000000,"slots":[]}`} {
+			if err := json.Unmarshal([]byte(data), NewInventory(reg, 0)); err == nil {
+				t.Errorf("Unmarshal(%s) expected error", data)
+			}
+		}
+		inv := NewInventory(reg, 2)
+		_ = inv.Set(0, NewStack(dirt, 5))
+		bad := `{"size":3,"slots":[{"slot":1,"item":"minecraft:stone","count":1},{"slot":7,"item":"minecraft:stone","count":1}]}`
+		if err := json.Unmarshal([]byte(bad), inv); err == nil {
+			t.Errorf("Unmarshal() of a bad slot expected error")
+		}
+		if inv.Size() != 2 || inv.Slot(0) != NewStack(dirt, 5) {
+			t.Errorf("failed Unmarshal() left %d slots, slot 0 %v", inv.Size(), inv.Slot(0))
+		}
+	})
+}
+
+func TestPlayerInventory(t *testing.T) {
+	t.Parallel()
+	reg := DefaultRegistry()
+	p := NewPlayerInventory(reg)
+	_ = p.Set(MainSlot(0), NewStack(Stick, 10))
+
+	// existing stacks get topped up before the hotbar is touched
+	p.Add(NewStack(Stick, 5))
+	if p.Slot(MainSlot(0)).Count != 15 || !p.Slot(0).IsEmpty() {
+		t.Errorf("expected pickup to merge into main slot, got %v %v", p.Slot(MainSlot(0)), p.Slot(0))
+	}
+	p.Add(NewStack(BlockID(blocks.Sand), 1))
+	if p.Slot(0).Item != BlockID(blocks.Sand) {
+		t.Errorf("expected new item in hotbar, got %v", p.Slot(0))
+	}
+
+	if !p.SelectItem(BlockID(blocks.Sand)) || p.Selected() != 0 {
+		t.Errorf("SelectItem(sand) selected %d, want 0", p.Selected())
+	}
+	if p.SelectItem(Stick) {
+		t.Errorf("SelectItem() found a stick outside the hotbar")
+	}
+
+	p.Scroll(-1)
+	if p.Selected() != HotbarSize-1 {
+		t.Errorf("expected scroll to wrap, got %d", p.Selected())
+	}
+	_ = p.Select(0)
+	if err := p.ConsumeHeld(1, gamemode.Creative.Abilities()); err != nil || p.Held().Count != 1 {
+		t.Errorf("creative ConsumeHeld() err %v, held %v", err, p.Held())
+	}
+	if err := p.ConsumeHeld(1, gamemode.Survival.Abilities()); err != nil || !p.Held().IsEmpty() {
+		t.Errorf("ConsumeHeld() err %v, held %v", err, p.Held())
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal() err: %v", err)
+	}
+	got := NewPlayerInventory(reg)
+	if err := json.Unmarshal(data, got); err != nil {
+		t.Fatalf("Unmarshal() err: %v", err)
+	}
+	if got.Slot(MainSlot(0)) != p.Slot(MainSlot(0)) {
+		t.Errorf("round trip lost main slot, got %v", got.Slot(MainSlot(0)))
+	}
+
+	for _, data := range []string{
+		`{"selected":0,"inventory":null}`,
+		`{"selected":0}`,
+		`{"selected":9,"inventory":{"size":36,"slots":[]}}`,
+		`{"selected":0,"inventory":{"size":5,"slots":[]}}`,
+	} {
+		if err := json.Unmarshal([]byte(data), got); err == nil {
+			t.Errorf("Unmarshal(%s) expected error", data)
+		}
+		if got.Slot(MainSlot(0)) != p.Slot(MainSlot(0)) {
+			t.Errorf("failed Unmarshal(%s) changed the inventory", data)
+		}
+	}
+}
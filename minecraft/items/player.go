@@ -0,0 +1,123 @@
+package items
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dragon1672/go-mine/minecraft/gamemode"
+)
+
+const (
+	HotbarSize        = 9
+	MainInventorySize = 27
+)
+
+// PlayerInventory is a hotbar followed by the main inventory in one container.
+// Slots [0, HotbarSize) are the hotbar.
+type PlayerInventory struct {
+	*Inventory
+	selected int
+}
+
+func NewPlayerInventory(reg *Registry) *PlayerInventory {
+	return &PlayerInventory{
+		Inventory: NewInventory(reg, HotbarSize+MainInventorySize),
+	}
+}
+
+// MainSlot maps an index in the main inventory to its container slot.
+func MainSlot(i int) int {
+	return HotbarSize + i
+}
+
+func (p *PlayerInventory) Selected() int {
+	return p.selected
+}
+
+func (p *PlayerInventory) Select(hotbarSlot int) error {
+	if hotbarSlot < 0 || hotbarSlot >= HotbarSize {
+		return fmt.Errorf("hotbar slot %d out of range [0, %d)", hotbarSlot, HotbarSize)
+	}
+	p.selected = hotbarSlot
+	return nil
+}
+
+// Scroll moves the selection like a mouse wheel, wrapping around the hotbar.
+func (p *PlayerInventory) Scroll(delta int) {
+	p.selected = ((p.selected+delta)%HotbarSize + HotbarSize) % HotbarSize
+}
+
+func (p *PlayerInventory) Held() Stack {
+	return p.Slot(p.selected)
+}
+
+// SelectItem selects the first hotbar slot holding id, ok is false if none do.
+func (p *PlayerInventory) SelectItem(id ID) bool {
+	for i := 0; i < HotbarSize; i++ {
+		if p.Slot(i).Item == id && !p.Slot(i).IsEmpty() {
+			p.selected = i
+			return true
+		}
+	}
+	return false
+}
+
+// ConsumeHeld uses up n of the held item, unless ab gives infinite items.
+func (p *PlayerInventory) ConsumeHeld(n int, ab gamemode.Abilities) error {
+	held := p.Held()
+	if held.Count < n {
+		return fmt.Errorf("holding %v, need %d", held, n)
+	}
+	if ab.InfiniteItems {
+		return nil
+	}
+	_, rest := held.Take(n)
+	p.slots[p.selected] = rest
+	return nil
+}
+
+// Add picks up s, filling the hotbar before the main inventory.
+func (p *PlayerInventory) Add(s Stack) Stack {
+	if s.IsEmpty() {
+		return Stack{}
+	}
+	// top up existing stacks anywhere first so pickups don't fragment
+	limit := p.reg.MaxStack(s.Item)
+	for i := range p.slots {
+		if slot := p.slots[i]; !s.IsEmpty() && slot.Item == s.Item && slot.Count < limit {
+			moved := min(limit-slot.Count, s.Count)
+			p.slots[i].Count += moved
+			s = NewStack(s.Item, s.Count-moved)
+		}
+	}
+	s = p.addRange(s, 0, HotbarSize)
+	return p.addRange(s, HotbarSize, len(p.slots))
+}
+
+type savedPlayerInventory struct {
+	Selected int        `json:"selected"`
+	Slots    *Inventory `json:"inventory"`
+}
+
+func (p *PlayerInventory) MarshalJSON() ([]byte, error) {
+	return json.Marshal(savedPlayerInventory{Selected: p.selected, Slots: p.Inventory})
+}
+
+func (p *PlayerInventory) UnmarshalJSON(data []byte) error {
+	// decode into a new inventory so bad data leaves this one as it was
+	saved := savedPlayerInventory{Slots: NewInventory(p.reg, 0)}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	if saved.Slots == nil {
+		return fmt.Errorf("player inventory has no slots")
+	}
+	if saved.Slots.Size() != HotbarSize+MainInventorySize {
+		return fmt.Errorf("player inventory has %d slots, expected %d", saved.Slots.Size(), HotbarSize+MainInventorySize)
+	}
+	if err := p.Select(saved.Selected); err != nil {
+		return err
+	}
+	p.Inventory = saved.Slots
+	return nil
+}
@@ -0,0 +1,40 @@
+package items
+
+import "fmt"
+
+// Stack is a pile of one item type, the zero value is an empty slot.
+type Stack struct {
+	Item  ID  `json:"item"`
+	Count int `json:"count"`
+}
+
+func NewStack(id ID, count int) Stack {
+	if count <= 0 {
+		return Stack{}
+	}
+	return Stack{Item: id, Count: count}
+}
+
+func (s Stack) IsEmpty() bool {
+	return s.Count <= 0 || s.Item == ""
+}
+
+func (s Stack) String() string {
+	if s.IsEmpty() {
+		return "empty"
+	}
+	return fmt.Sprintf("%dx%s", s.Count, s.Item)
+}
+
+// CanMerge reports whether o could be added on top of s, ignoring stack limits.
+func (s Stack) CanMerge(o Stack) bool {
+	return s.IsEmpty() || o.IsEmpty() || s.Item == o.Item
+}
+
+// Take splits n items off the stack, returning what was taken and what remains.
+func (s Stack) Take(n int) (taken, rest Stack) {
+	if n >= s.Count {
+		return s, Stack{}
+	}
+	return NewStack(s.Item, n), NewStack(s.Item, s.Count-n)
+}
@@ -0,0 +1,119 @@
+package mining
+
+import (
+	"math/rand"
+
+	"github.com/dragon1672/go-mine/minecraft/gamemode"
+	"github.com/dragon1672/go-mine/minecraft/items"
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// BreakStages is how many crack overlay textures there are.
+const BreakStages = 10
+
+// progressEpsilon absorbs float error so 15 steps of 1/15 count as done.
+const progressEpsilon = 1e-9
+
+type World interface {
+	BlockAt(pos vec.IntVec3) blocks.SimpleBlockType
+	SetBlock(pos vec.IntVec3, t blocks.SimpleBlockType)
+}
+
+// ItemDrop is an item spawned into the world when a block breaks.
+type ItemDrop struct {
+	Pos   vec.Vec3
+	Stack items.Stack
+}
+
+// Breaker tracks a single player's progress breaking a block.
+type Breaker struct {
+	table Table
+	rng   *rand.Rand
+
+	mining   bool
+	target   vec.IntVec3
+	block    blocks.SimpleBlockType
+	progress float64
+}
+
+func NewBreaker(table Table, rng *rand.Rand) *Breaker {
+	return &Breaker{
+		table: table,
+		rng:   rng,
+	}
+}
+
+// Start begins (or continues) mining pos, switching blocks resets progress.
+func (b *Breaker) Start(pos vec.IntVec3) {
+	if b.mining && b.target == pos {
+		return
+	}
+	b.mining = true
+	b.target = pos
+	b.progress = 0
+	b.block = blocks.Air
+}
+
+// Stop is called when the player lets go of the action.
+func (b *Breaker) Stop() {
+	b.mining = false
+	b.progress = 0
+}
+
+func (b *Breaker) Target() (vec.IntVec3, bool) {
+	return b.target, b.mining
+}
+
+func (b *Breaker) Progress() float64 {
+	return b.progress
+}
+
+// Stage is the crack overlay to draw, -1 means none.
+func (b *Breaker) Stage() int {
+	if !b.mining || b.progress <= 0 {
+		return -1
+	}
+	return min(int(b.progress*BreakStages), BreakStages-1)
+}
+
+// Tick advances mining by one game tick using the held tool.
+// When the block breaks it is replaced by air and its drops are passed to spawn.
+// ab is the miner's game mode, which may forbid mining or make it instant.
+func (b *Breaker) Tick(w World, tool items.Tool, ab gamemode.Abilities, spawn func(ItemDrop)) (broken bool) {
+	if !b.mining {
+		return false
+	}
+	if !ab.Interact {
+		b.Stop()
+		return false
+	}
+	current := w.BlockAt(b.target)
+	if current != b.block {
+		// something else changed the block under us
+		b.block = current
+		b.progress = 0
+	}
+	props, ok := b.table[current]
+	if !ok {
+		props = Properties{Hardness: Unbreakable}
+	}
+	if ab.InstantBreak && props.Hardness != Unbreakable {
+		b.progress = 1
+	} else {
+		b.progress += ProgressPerTick(props, tool)
+	}
+	if b.progress < 1-progressEpsilon {
+		return false
+	}
+
+	w.SetBlock(b.target, blocks.Air)
+	if !ab.InstantBreak && canHarvest(props, tool) {
+		center := vec.Vec3{X: float64(b.target.X) + 0.5, Y: float64(b.target.Y) + 0.5, Z: float64(b.target.Z) + 0.5}
+		for _, s := range props.Drops.Roll(b.rng) {
+			spawn(ItemDrop{Pos: center, Stack: s})
+		}
+	}
+	b.Stop()
+	return true
+}
@@ -0,0 +1,152 @@
+package mining
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/dragon1672/go-mine/minecraft/gamemode"
+	"github.com/dragon1672/go-mine/minecraft/items"
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+var survival = gamemode.Survival.Abilities()
+
+type testWorld map[vec.IntVec3]blocks.SimpleBlockType
+
+func (w testWorld) BlockAt(pos vec.IntVec3) blocks.SimpleBlockType { return w[pos] }
+func (w testWorld) SetBlock(pos vec.IntVec3, t blocks.SimpleBlockType) {
+	w[pos] = t
+}
+
+func toolOf(t *testing.T, id items.ID) items.Tool {
+	it, ok := items.DefaultRegistry().Get(id)
+	if !ok {
+		t.Fatalf("missing item %s", id)
+	}
+	return it.Tool
+}
+
+// ticksToBreak mines pos until it breaks, giving up after limit ticks.
+func ticksToBreak(b *Breaker, w World, pos vec.IntVec3, tool items.Tool, ab gamemode.Abilities, limit int) (int, []ItemDrop) {
+	var drops []ItemDrop
+	b.Start(pos)
+	for i := 1; i <= limit; i++ {
+		if b.Tick(w, tool, ab, func(d ItemDrop) { drops = append(drops, d) }) {
+			return i, drops
+		}
+	}
+	return -1, drops
+}
+
+func TestBreaking(t *testing.T) {
+	t.Parallel()
+	pos := vec.IntVec3{X: 1, Y: 2, Z: 3}
+	for _, tc := range []struct {
+		name      string
+		block     blocks.SimpleBlockType
+		tool      items.Tool
+		wantTicks int
+		wantDrop  items.ID
+	}{
+		{name: "Dirt by hand", block: blocks.Dirt, wantTicks: 15, wantDrop: "dirt"},
+		{name: "Dirt with shovel", block: blocks.Dirt, tool: toolOf(t, items.WoodenShovel), wantTicks: 8, wantDrop: "dirt"},
+		{name: "Grass drops dirt", block: blocks.Grass, wantTicks: 18, wantDrop: "dirt"},
+		{name: "Stone by hand", block: blocks.Stone, wantTicks: 150},
+		{name: "Stone with pickaxe", block: blocks.Stone, tool: toolOf(t, items.WoodenPickaxe), wantTicks: 23, wantDrop: "stone"},
+		{name: "Stone with axe", block: blocks.Stone, tool: toolOf(t, items.WoodenAxe), wantTicks: 150},
+		{name: "Flower is instant", block: blocks.Flower, wantTicks: 1, wantDrop: "flower"},
+		{name: "Water is unbreakable", block: blocks.Water, wantTicks: -1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := testWorld{pos: tc.block}
+			b := NewBreaker(DefaultTable(), rand.New(rand.NewSource(1)))
+			ticks, drops := ticksToBreak(b, w, pos, tc.tool, survival, 1000)
+			if ticks != tc.wantTicks {
+				t.Errorf("took %d ticks, want %d", ticks, tc.wantTicks)
+			}
+			if ticks < 0 {
+				return
+			}
+			if w[pos] != blocks.Air {
+				t.Errorf("block not removed, got %v", w[pos])
+			}
+			if tc.wantDrop == "" {
+				if len(drops) != 0 {
+					t.Errorf("expected no drops, got %v", drops)
+				}
+				return
+			}
+			if len(drops) != 1 || drops[0].Stack != items.NewStack(tc.wantDrop, 1) {
+				t.Errorf("got drops %v, want 1 %s", drops, tc.wantDrop)
+			}
+			if drops[0].Pos != (vec.Vec3{X: 1.5, Y: 2.5, Z: 3.5}) {
+				t.Errorf("drop spawned at %v, want block center", drops[0].Pos)
+			}
+		})
+	}
+}
+
+func TestStage(t *testing.T) {
+	t.Parallel()
+	pos := vec.IntVec3{}
+	w := testWorld{pos: blocks.Dirt}
+	b := NewBreaker(DefaultTable(), rand.New(rand.NewSource(1)))
+	if b.Stage() != -1 {
+		t.Errorf("expected no stage before mining, got %d", b.Stage())
+	}
+	b.Start(pos)
+	var stages []int
+	for i := 0; i < 14; i++ {
+		b.Tick(w, items.Tool{}, survival, nil)
+		stages = append(stages, b.Stage())
+	}
+	for i := 1; i < len(stages); i++ {
+		if stages[i] < stages[i-1] {
+			t.Errorf("stages went backwards: %v", stages)
+		}
+	}
+	if stages[len(stages)-1] != BreakStages-1 {
+		t.Errorf("expected final stage %d just before breaking, got %v", BreakStages-1, stages)
+	}
+
+	b.Stop()
+	if b.Stage() != -1 || b.Progress() != 0 {
+		t.Errorf("letting go should reset progress")
+	}
+
+	b.Start(pos)
+	b.Tick(w, items.Tool{}, survival, nil)
+	w[pos] = blocks.Sand
+	b.Tick(w, items.Tool{}, survival, nil)
+	if b.Stage() != 0 {
+		t.Errorf("block swap should restart progress, got stage %d", b.Stage())
+	}
+}
+
+func TestGameModes(t *testing.T) {
+	t.Parallel()
+	pos := vec.IntVec3{}
+	for _, tc := range []struct {
+		name      string
+		mode      gamemode.Mode
+		block     blocks.SimpleBlockType
+		wantTicks int
+	}{
+		{name: "Creative breaks stone instantly", mode: gamemode.Creative, block: blocks.Stone, wantTicks: 1},
+		{name: "Creative can't break water", mode: gamemode.Creative, block: blocks.Water, wantTicks: -1},
+		{name: "Spectator can't mine", mode: gamemode.Spectator, block: blocks.Flower, wantTicks: -1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := testWorld{pos: tc.block}
+			b := NewBreaker(DefaultTable(), rand.New(rand.NewSource(1)))
+			ticks, drops := ticksToBreak(b, w, pos, items.Tool{}, tc.mode.Abilities(), 100)
+			if ticks != tc.wantTicks {
+				t.Errorf("took %d ticks, want %d", ticks, tc.wantTicks)
+			}
+			if len(drops) != 0 {
+				t.Errorf("expected no drops, got %v", drops)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package mining
+
+import (
+	"math/rand"
+
+	"github.com/dragon1672/go-mine/minecraft/items"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// Unbreakable is a hardness that can never be mined.
+const Unbreakable = -1
+
+// Properties describe how a block reacts to being mined.
+type Properties struct {
+	// Hardness is roughly how many seconds the block takes to break by hand, 0 breaks instantly.
+	Hardness float64
+	// Tool is the preferred tool type, breaking is faster with it.
+	Tool items.ToolType
+	// RequiresTool means nothing drops unless Tool of at least Level is used.
+	RequiresTool bool
+	Level        int
+	Drops        DropTable
+}
+
+type Drop struct {
+	Item     items.ID
+	Min, Max int
+	// Chance is the probability of the drop happening at all, 0 is treated as always.
+	Chance float64
+}
+
+type DropTable []Drop
+
+// Roll picks the stacks a single break produces.
+func (d DropTable) Roll(rng *rand.Rand) []items.Stack {
+	var out []items.Stack
+	for _, drop := range d {
+		if drop.Chance > 0 && rng.Float64() >= drop.Chance {
+			continue
+		}
+		n := drop.Min
+		if drop.Max > drop.Min {
+			n += rng.Intn(drop.Max - drop.Min + 1)
+		}
+		if s := items.NewStack(drop.Item, n); !s.IsEmpty() {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Table maps each block type to its mining properties.
+type Table map[blocks.SimpleBlockType]Properties
+
+func selfDrop(b blocks.SimpleBlockType) DropTable {
+	return DropTable{{Item: items.BlockID(b), Min: 1, Max: 1}}
+}
+
+// DefaultTable holds vanilla-ish values for the built in blocks.
+func DefaultTable() Table {
+	return Table{
+		blocks.Air:    {Hardness: Unbreakable},
+		blocks.Water:  {Hardness: Unbreakable},
+		blocks.Lava:   {Hardness: Unbreakable},
+		blocks.Grass:  {Hardness: 0.6, Tool: items.Shovel, Drops: selfDrop(blocks.Dirt)},
+		blocks.Dirt:   {Hardness: 0.5, Tool: items.Shovel, Drops: selfDrop(blocks.Dirt)},
+		blocks.Sand:   {Hardness: 0.5, Tool: items.Shovel, Drops: selfDrop(blocks.Sand)},
+		blocks.Stone:  {Hardness: 1.5, Tool: items.Pickaxe, RequiresTool: true, Drops: selfDrop(blocks.Stone)},
+		blocks.Wood:   {Hardness: 2, Tool: items.Axe, Drops: selfDrop(blocks.Wood)},
+		blocks.Planks: {Hardness: 2, Tool: items.Axe, Drops: selfDrop(blocks.Planks)},
+		blocks.Leaves: {Hardness: 0.2, Drops: DropTable{{Item: items.Stick, Min: 1, Max: 2, Chance: 0.1}}},
+		blocks.Flower: {Hardness: 0, Drops: selfDrop(blocks.Flower)},
+	}
+}
+
+// canHarvest reports whether tool gets drops from a block with props.
+func canHarvest(props Properties, tool items.Tool) bool {
+	if !props.RequiresTool {
+		return true
+	}
+	return tool.Type == props.Tool && tool.Level >= props.Level
+}
+
+// ProgressPerTick is the fraction of the block broken each game tick, following Minecraft's formula.
+func ProgressPerTick(props Properties, tool items.Tool) float64 {
+	if props.Hardness < 0 {
+		return 0
+	}
+	if props.Hardness == 0 {
+		return 1
+	}
+	speed := 1.0
+	if props.Tool != items.NoTool && tool.Type == props.Tool && tool.Speed > 0 {
+		speed = tool.Speed
+	}
+	if canHarvest(props, tool) {
+		return speed / props.Hardness / 30
+	}
+	return speed / props.Hardness / 100
+}
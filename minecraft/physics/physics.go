@@ -0,0 +1,202 @@
+// Package physics moves entities through the block world. The client and server share it so a client
+// can predict where the server will put its player.
+package physics
+
+import (
+	"math"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// Dt is the length of one Step in seconds, one game tick.
+const Dt = 1.0 / 20
+
+// Speeds are in blocks per second.
+const (
+	Gravity      = 32.0
+	JumpSpeed    = 9.0
+	WalkSpeed    = 4.3
+	FlySpeed     = 10.9
+	MaxFallSpeed = 60.0
+)
+
+// maxSubstep keeps fast moves from tunnelling through a block.
+const maxSubstep = 0.5
+
+// epsilon keeps boxes exactly touching a block from counting as inside it.
+const epsilon = 1e-7
+
+type World interface {
+	BlockAt(pos vec.IntVec3) blocks.SimpleBlockType
+}
+
+// Body is an entity's collision box, centred on its position horizontally with its feet at the position.
+type Body struct {
+	Width, Height float64
+}
+
+var PlayerBody = Body{Width: 0.6, Height: 1.8}
+
+type State struct {
+	Pos, Vel vec.Vec3
+	OnGround bool
+}
+
+// Movement is what a body is allowed to do, players get theirs from their game mode.
+type Movement struct {
+	// Fly lets Input.Fly turn off gravity, moving up and down with Jump and Sneak instead.
+	Fly bool
+	// NoClip always flies and passes through blocks.
+	NoClip bool
+}
+
+// Input is what a player wants to do for one tick.
+type Input struct {
+	// Seq numbers inputs so the server can say which one a state is the result of.
+	Seq uint32
+	// Forward and Strafe are -1 to 1, positive is forwards and to the right.
+	Forward, Strafe float64
+	// Yaw is the facing in radians, 0 looks down -Z.
+	Yaw  float64
+	Jump bool
+	// Sneak descends while flying.
+	Sneak bool
+	// Fly asks to fly, it is ignored unless the Movement allows it.
+	Fly bool
+}
+
+func finite(f float64) float64 {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+	return f
+}
+
+// sanitize clamps input from the network so it can't move faster than walking.
+func (in Input) sanitize() Input {
+	in.Forward, in.Strafe, in.Yaw = finite(in.Forward), finite(in.Strafe), finite(in.Yaw)
+	if l := math.Hypot(in.Forward, in.Strafe); l > 1 {
+		in.Forward /= l
+		in.Strafe /= l
+	}
+	return in
+}
+
+// Step advances s by one tick of in, as far as m allows.
+func Step(w World, b Body, s State, in Input, m Movement) State {
+	in = in.sanitize()
+	flying := m.NoClip || (m.Fly && in.Fly)
+	speed := WalkSpeed
+	if flying {
+		speed = FlySpeed
+	}
+	sin, cos := math.Sincos(in.Yaw)
+	s.Vel.X = (-sin*in.Forward + cos*in.Strafe) * speed
+	s.Vel.Z = (-cos*in.Forward - sin*in.Strafe) * speed
+	if flying {
+		s.Vel.Y = 0
+		if in.Jump {
+			s.Vel.Y += FlySpeed
+		}
+		if in.Sneak {
+			s.Vel.Y -= FlySpeed
+		}
+	} else {
+		if in.Jump && s.OnGround {
+			s.Vel.Y = JumpSpeed
+		}
+		s.Vel.Y = math.Max(s.Vel.Y-Gravity*Dt, -MaxFallSpeed)
+	}
+
+	s.OnGround = false
+	if m.NoClip {
+		s.Pos = s.Pos.Add(s.Vel.Mul(Dt))
+		return s
+	}
+	for _, axis := range []int{1, 0, 2} {
+		moved, hit := b.move(w, s.Pos, axis, get(s.Vel, axis)*Dt)
+		s.Pos = moved
+		if hit {
+			if axis == 1 && s.Vel.Y < 0 {
+				s.OnGround = true
+			}
+			s.Vel = set(s.Vel, axis, 0)
+		}
+	}
+	return s
+}
+
+func get(v vec.Vec3, axis int) float64 {
+	switch axis {
+	case 0:
+		return v.X
+	case 1:
+		return v.Y
+	}
+	return v.Z
+}
+
+func set(v vec.Vec3, axis int, f float64) vec.Vec3 {
+	switch axis {
+	case 0:
+		v.X = f
+	case 1:
+		v.Y = f
+	default:
+		v.Z = f
+	}
+	return v
+}
+
+// bounds returns the box's extent below and above pos along axis.
+func (b Body) bounds(axis int) (lo, hi float64) {
+	if axis == 1 {
+		return 0, b.Height
+	}
+	return -b.Width / 2, b.Width / 2
+}
+
+// Collides reports whether the body at pos overlaps any solid block.
+func (b Body) Collides(w World, pos vec.Vec3) bool {
+	var lo, hi [3]int
+	for axis := 0; axis < 3; axis++ {
+		l, h := b.bounds(axis)
+		lo[axis] = int(math.Floor(get(pos, axis) + l + epsilon))
+		hi[axis] = int(math.Floor(get(pos, axis) + h - epsilon))
+	}
+	for y := lo[1]; y <= hi[1]; y++ {
+		for z := lo[2]; z <= hi[2]; z++ {
+			for x := lo[0]; x <= hi[0]; x++ {
+				if w.BlockAt(vec.IntVec3{X: x, Y: y, Z: z}).IsSolid() {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// move slides pos by delta along one axis, stopping flush against the first solid block.
+// A body that starts inside a block moves freely so it can get out.
+func (b Body) move(w World, pos vec.Vec3, axis int, delta float64) (vec.Vec3, bool) {
+	if delta == 0 || b.Collides(w, pos) {
+		return set(pos, axis, get(pos, axis)+delta), false
+	}
+	lo, hi := b.bounds(axis)
+	for delta != 0 {
+		d := math.Max(-maxSubstep, math.Min(maxSubstep, delta))
+		delta -= d
+		next := set(pos, axis, get(pos, axis)+d)
+		if !b.Collides(w, next) {
+			pos = next
+			continue
+		}
+		// substeps are under a block long, so the block hit is the one the leading edge moved into
+		if d > 0 {
+			return set(pos, axis, math.Floor(get(next, axis)+hi-epsilon)-hi), true
+		}
+		return set(pos, axis, math.Floor(get(next, axis)+lo+epsilon)+1-lo), true
+	}
+	return pos, false
+}
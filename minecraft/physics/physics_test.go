@@ -0,0 +1,120 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// flat is stone at y < 0 plus any extra blocks.
+type flat map[vec.IntVec3]blocks.SimpleBlockType
+
+func (f flat) BlockAt(pos vec.IntVec3) blocks.SimpleBlockType {
+	if b, ok := f[pos]; ok {
+		return b
+	}
+	if pos.Y < 0 {
+		return blocks.Stone
+	}
+	return blocks.Air
+}
+
+func run(w World, s State, in Input, ticks int) State {
+	for i := 0; i < ticks; i++ {
+		s = Step(w, PlayerBody, s, in, Movement{})
+	}
+	return s
+}
+
+func TestFallAndLand(t *testing.T) {
+	t.Parallel()
+	s := run(flat{}, State{Pos: vec.Vec3{X: 0.5, Y: 10, Z: 0.5}}, Input{}, 40)
+	if s.Pos.Y != 0 || !s.OnGround || s.Vel.Y != 0 {
+		t.Errorf("after falling got %+v, want resting on y=0", s)
+	}
+}
+
+func TestWalkIntoWall(t *testing.T) {
+	t.Parallel()
+	w := flat{{X: 0, Y: 0, Z: -3}: blocks.Stone, {X: 0, Y: 1, Z: -3}: blocks.Stone}
+	start := State{Pos: vec.Vec3{X: 0.5, Y: 0, Z: 0.5}, OnGround: true}
+	s := run(w, start, Input{Forward: 1}, 40)
+	// the wall's +Z face is at z=-2, the body is 0.3 either side of its centre
+	if math.Abs(s.Pos.Z-(-1.7)) > 1e-9 || s.Pos.X != 0.5 {
+		t.Errorf("walked to %v, want stopped at z=-1.7", s.Pos)
+	}
+}
+
+func TestJumpOntoStep(t *testing.T) {
+	t.Parallel()
+	w := flat{}
+	for x := 1; x < 8; x++ {
+		w[vec.IntVec3{X: x, Y: 0, Z: 0}] = blocks.Stone
+	}
+	start := State{Pos: vec.Vec3{X: 0.5, Y: 0, Z: 0.5}, OnGround: true}
+	// facing +X is a quarter turn clockwise from -Z
+	s := run(w, start, Input{Forward: 1, Yaw: -math.Pi / 2, Jump: true}, 1)
+	s = run(w, s, Input{Forward: 1, Yaw: -math.Pi / 2}, 20)
+	if s.Pos.Y != 1 || s.Pos.X < 1 {
+		t.Errorf("ended at %v, want on top of the step", s.Pos)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	t.Parallel()
+	start := State{Pos: vec.Vec3{X: 0.5, Y: 0, Z: 0.5}, OnGround: true}
+	fast := Step(flat{}, PlayerBody, start, Input{Forward: 100, Strafe: 100}, Movement{})
+	if got := math.Hypot(fast.Vel.X, fast.Vel.Z); math.Abs(got-WalkSpeed) > 1e-9 {
+		t.Errorf("speed = %v, want clamped to %v", got, WalkSpeed)
+	}
+	nan := Step(flat{}, PlayerBody, start, Input{Forward: math.NaN(), Yaw: math.Inf(1)}, Movement{})
+	if nan.Pos != start.Pos {
+		t.Errorf("NaN input moved to %v", nan.Pos)
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	t.Parallel()
+	w := flat{{X: 2, Y: 0, Z: -2}: blocks.Stone}
+	start := State{Pos: vec.Vec3{X: 0.5, Y: 3, Z: 0.5}}
+	inputs := []Input{{Forward: 1, Yaw: 0.3}, {Forward: 1, Strafe: 0.5, Jump: true}, {Strafe: -1, Yaw: 2}}
+	a, b := start, start
+	for i := 0; i < 60; i++ {
+		in := inputs[i%len(inputs)]
+		a = Step(w, PlayerBody, a, in, Movement{})
+		b = Step(w, PlayerBody, b, in, Movement{})
+	}
+	if a != b {
+		t.Errorf("same inputs gave %+v and %+v", a, b)
+	}
+}
+
+func TestMovement(t *testing.T) {
+	t.Parallel()
+	start := State{Pos: vec.Vec3{X: 0.5, Y: 5, Z: 0.5}}
+	step := func(in Input, m Movement) State {
+		s := start
+		for i := 0; i < 20; i++ {
+			s = Step(flat{}, PlayerBody, s, in, m)
+		}
+		return s
+	}
+
+	if s := step(Input{Fly: true}, Movement{}); s.Pos.Y >= start.Pos.Y {
+		t.Errorf("flying without permission should still fall, at y=%v", s.Pos.Y)
+	}
+	if s := step(Input{Fly: true}, Movement{Fly: true}); s.Pos.Y != start.Pos.Y {
+		t.Errorf("hovering moved to y=%v", s.Pos.Y)
+	}
+	if s := step(Input{Fly: true, Jump: true}, Movement{Fly: true}); math.Abs(s.Pos.Y-(start.Pos.Y+FlySpeed)) > 1e-9 {
+		t.Errorf("one second flying up got to y=%v, want %v", s.Pos.Y, start.Pos.Y+FlySpeed)
+	}
+	if s := step(Input{Fly: true, Sneak: true}, Movement{Fly: true}); s.Pos.Y != 0 || !s.OnGround {
+		t.Errorf("flying down should land on the ground, got %+v", s)
+	}
+	if s := step(Input{Sneak: true}, Movement{NoClip: true}); math.Abs(s.Pos.Y-(start.Pos.Y-FlySpeed)) > 1e-9 {
+		t.Errorf("noclip should pass through the ground, got y=%v", s.Pos.Y)
+	}
+}
@@ -0,0 +1,187 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+)
+
+// Writer builds a packet payload. All numbers are big endian. The first error sticks and
+// the payload must not be sent.
+type Writer struct {
+	buf bytes.Buffer
+	err error
+}
+
+func (w *Writer) Err() error {
+	return w.err
+}
+
+func (w *Writer) U8(v uint8) {
+	w.buf.WriteByte(v)
+}
+
+func (w *Writer) U16(v uint16) {
+	w.buf.Write(binary.BigEndian.AppendUint16(nil, v))
+}
+
+func (w *Writer) U32(v uint32) {
+	w.buf.Write(binary.BigEndian.AppendUint32(nil, v))
+}
+
+func (w *Writer) I32(v int32) {
+	w.U32(uint32(v))
+}
+
+func (w *Writer) U64(v uint64) {
+	w.buf.Write(binary.BigEndian.AppendUint64(nil, v))
+}
+
+func (w *Writer) I64(v int64) {
+	w.U64(uint64(v))
+}
+
+func (w *Writer) F64(v float64) {
+	w.U64(math.Float64bits(v))
+}
+
+func (w *Writer) Bool(v bool) {
+	if v {
+		w.U8(1)
+	} else {
+		w.U8(0)
+	}
+}
+
+// String is written with a 16 bit length prefix, longer strings are an error.
+func (w *Writer) String(s string) {
+	if len(s) > math.MaxUint16 {
+		if w.err == nil {
+			w.err = fmt.Errorf("string of %d bytes is too long", len(s))
+		}
+		return
+	}
+	w.U16(uint16(len(s)))
+	w.buf.WriteString(s)
+}
+
+// Bytes is written with a 32 bit length prefix.
+func (w *Writer) Bytes(b []byte) {
+	w.U32(uint32(len(b)))
+	w.buf.Write(b)
+}
+
+func (w *Writer) Vec3(v vec.Vec3) {
+	w.F64(v.X)
+	w.F64(v.Y)
+	w.F64(v.Z)
+}
+
+func (w *Writer) IntVec3(v vec.IntVec3) {
+	w.I32(int32(v.X))
+	w.I32(int32(v.Y))
+	w.I32(int32(v.Z))
+}
+
+// Reader decodes a packet payload. The first error sticks and later reads return zero values.
+type Reader struct {
+	data []byte
+	off  int
+	err  error
+}
+
+func NewReader(data []byte) *Reader {
+	return &Reader{data: data}
+}
+
+func (r *Reader) Err() error {
+	return r.err
+}
+
+func (r *Reader) take(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.off+n > len(r.data) {
+		r.err = fmt.Errorf("payload too short: need %d bytes at offset %d of %d", n, r.off, len(r.data))
+		return nil
+	}
+	b := r.data[r.off : r.off+n]
+	r.off += n
+	return b
+}
+
+func (r *Reader) U8() uint8 {
+	b := r.take(1)
+	if b == nil {
+		return 0
+	}
+	return b[0]
+}
+
+func (r *Reader) U16() uint16 {
+	b := r.take(2)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b)
+}
+
+func (r *Reader) U32() uint32 {
+	b := r.take(4)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b)
+}
+
+func (r *Reader) I32() int32 {
+	return int32(r.U32())
+}
+
+func (r *Reader) U64() uint64 {
+	b := r.take(8)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+func (r *Reader) I64() int64 {
+	return int64(r.U64())
+}
+
+func (r *Reader) F64() float64 {
+	return math.Float64frombits(r.U64())
+}
+
+func (r *Reader) Bool() bool {
+	return r.U8() != 0
+}
+
+func (r *Reader) String() string {
+	return string(r.take(int(r.U16())))
+}
+
+func (r *Reader) Bytes() []byte {
+	return bytes.Clone(r.take(int(r.U32())))
+}
+
+func (r *Reader) Vec3() vec.Vec3 {
+	return vec.Vec3{X: r.F64(), Y: r.F64(), Z: r.F64()}
+}
+
+func (r *Reader) IntVec3() vec.IntVec3 {
+	return vec.IntVec3{X: int(r.I32()), Y: int(r.I32()), Z: int(r.I32())}
+}
+
+// done fails if there are bytes left over, which means the two sides disagree on the layout.
+func (r *Reader) done() error {
+	if r.err == nil && r.off != len(r.data) {
+		r.err = fmt.Errorf("%d unexpected trailing bytes", len(r.data)-r.off)
+	}
+	return r.err
+}
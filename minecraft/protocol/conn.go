@@ -0,0 +1,74 @@
+package protocol
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// MaxPacketSize guards against a bad length prefix allocating gigabytes.
+const MaxPacketSize = 1 << 20
+
+// Conn frames packets as a 32 bit length, a packet ID byte and the payload.
+// Reads must come from a single goroutine, writes are safe from many.
+type Conn struct {
+	c   net.Conn
+	r   *bufio.Reader
+	wmu sync.Mutex
+}
+
+func NewConn(c net.Conn) *Conn {
+	return &Conn{c: c, r: bufio.NewReader(c)}
+}
+
+func (c *Conn) NetConn() net.Conn {
+	return c.c
+}
+
+func (c *Conn) Close() error {
+	return c.c.Close()
+}
+
+func (c *Conn) WritePacket(p Packet) error {
+	w := &Writer{}
+	w.U8(uint8(p.ID()))
+	p.Encode(w)
+	if err := w.Err(); err != nil {
+		return fmt.Errorf("error encoding packet %d: %v", p.ID(), err)
+	}
+	frame := binary.BigEndian.AppendUint32(nil, uint32(w.buf.Len()))
+	frame = append(frame, w.buf.Bytes()...)
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	_, err := c.c.Write(frame)
+	return err
+}
+
+func (c *Conn) ReadPacket() (Packet, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(c.r, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n == 0 || n > MaxPacketSize {
+		return nil, fmt.Errorf("invalid packet size %d", n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		return nil, err
+	}
+	p := newPacket(PacketID(data[0]))
+	if p == nil {
+		return nil, fmt.Errorf("unknown packet id %d", data[0])
+	}
+	r := NewReader(data[1:])
+	p.Decode(r)
+	if err := r.done(); err != nil {
+		return nil, fmt.Errorf("error decoding packet %d: %v", data[0], err)
+	}
+	return p, nil
+}
@@ -0,0 +1,76 @@
+package javaping
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Ping does a server list ping against addr like a stock client would, returning the server's
+// status and the ping round trip time.
+func Ping(ctx context.Context, addr string) (*Status, time.Duration, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, 0, err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("bad port %q: %v", portStr, err)
+	}
+	var d net.Dialer
+	c, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error connecting to %s: %v", addr, err)
+	}
+	defer c.Close()
+	return ping(c, host, uint16(port))
+}
+
+func ping(c net.Conn, host string, port uint16) (*Status, time.Duration, error) {
+	c.SetDeadline(time.Now().Add(timeout))
+	hs := AppendVarInt(nil, -1) // protocol version, -1 when only pinging
+	hs = appendString(hs, host)
+	hs = binary.BigEndian.AppendUint16(hs, port)
+	hs = AppendVarInt(hs, stateStatus)
+	if err := writePacket(c, idHandshake, hs); err != nil {
+		return nil, 0, err
+	}
+	if err := writePacket(c, idStatus, nil); err != nil {
+		return nil, 0, err
+	}
+	r := bufio.NewReader(c)
+	p, err := readPacket(r)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading status: %v", err)
+	}
+	if p.id != idStatus {
+		return nil, 0, fmt.Errorf("expected status, got packet %d", p.id)
+	}
+	js, err := (&byteReader{b: p.data}).string(maxPacketSize / 4)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading status: %v", err)
+	}
+	var st Status
+	if err := json.Unmarshal([]byte(js), &st); err != nil {
+		return nil, 0, fmt.Errorf("error parsing status: %v", err)
+	}
+
+	start := time.Now()
+	payload := binary.BigEndian.AppendUint64(nil, uint64(start.UnixMilli()))
+	if err := writePacket(c, idPing, payload); err != nil {
+		return nil, 0, err
+	}
+	p, err = readPacket(r)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading pong: %v", err)
+	}
+	if p.id != idPing || string(p.data) != string(payload) {
+		return nil, 0, fmt.Errorf("bad pong %d %x", p.id, p.data)
+	}
+	return &st, time.Since(start), nil
+}
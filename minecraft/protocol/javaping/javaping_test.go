@@ -0,0 +1,120 @@
+package javaping
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestVarInt(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		v    int32
+		want []byte
+	}{
+		{0, []byte{0x00}},
+		{1, []byte{0x01}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x80, 0x01}},
+		{255, []byte{0xff, 0x01}},
+		{25565, []byte{0xdd, 0xc7, 0x01}},
+		{2097151, []byte{0xff, 0xff, 0x7f}},
+		{2147483647, []byte{0xff, 0xff, 0xff, 0xff, 0x07}},
+		{-1, []byte{0xff, 0xff, 0xff, 0xff, 0x0f}},
+		{-2147483648, []byte{0x80, 0x80, 0x80, 0x80, 0x08}},
+	} {
+		if got := AppendVarInt(nil, tc.v); !bytes.Equal(got, tc.want) {
+			t.Errorf("AppendVarInt(%d) = %x, want %x", tc.v, got, tc.want)
+		}
+		if got, err := ReadVarInt(bytes.NewReader(tc.want)); got != tc.v || err != nil {
+			t.Errorf("ReadVarInt(%x) = %d, %v, want %d", tc.want, got, err, tc.v)
+		}
+	}
+	if _, err := ReadVarInt(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0x01})); err == nil {
+		t.Errorf("ReadVarInt() of 6 bytes succeeded")
+	}
+}
+
+func TestOfflineUUID(t *testing.T) {
+	t.Parallel()
+	id := OfflineUUID("alex")
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-3[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(id) {
+		t.Errorf("OfflineUUID() = %q, not a version 3 UUID", id)
+	}
+	if OfflineUUID("alex") != id || OfflineUUID("steve") == id {
+		t.Errorf("OfflineUUID() isn't a stable per name ID")
+	}
+}
+
+func listen(t *testing.T, status Status) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() = %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go Handle(c, bufio.NewReader(c), func() Status { return status })
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestPing(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	want := Status{
+		Version:     Version{Name: "go-mine", Protocol: -1},
+		Players:     Players{Max: 20, Online: 1, Sample: []PlayerInfo{{Name: "alex", ID: OfflineUUID("alex")}}},
+		Description: Description{Text: "hello"},
+	}
+	addr := listen(t, want)
+	got, rtt, err := Ping(ctx, addr)
+	if err != nil {
+		t.Fatalf("Ping() = %v", err)
+	}
+	gotJS, _ := json.Marshal(got)
+	wantJS, _ := json.Marshal(want)
+	if !bytes.Equal(gotJS, wantJS) {
+		t.Errorf("Ping() = %s, want %s", gotJS, wantJS)
+	}
+	if rtt <= 0 {
+		t.Errorf("Ping() round trip = %v", rtt)
+	}
+}
+
+func TestLoginRefused(t *testing.T) {
+	t.Parallel()
+	addr := listen(t, Status{})
+	c, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial() = %v", err)
+	}
+	defer c.Close()
+	hs := AppendVarInt(nil, 765)
+	hs = appendString(hs, "localhost")
+	hs = append(hs, 0x63, 0xdd)
+	hs = AppendVarInt(hs, stateLogin)
+	if err := writePacket(c, idHandshake, hs); err != nil {
+		t.Fatalf("writePacket() = %v", err)
+	}
+	p, err := readPacket(bufio.NewReader(c))
+	if err != nil {
+		t.Fatalf("readPacket() = %v", err)
+	}
+	msg, err := (&byteReader{b: p.data}).string(1 << 16)
+	if p.id != idDisconnect || err != nil || !bytes.Contains([]byte(msg), []byte("go-mine")) {
+		t.Errorf("got packet %d %q, want a login disconnect", p.id, msg)
+	}
+}
@@ -0,0 +1,92 @@
+package javaping
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Handshake states.
+const (
+	stateStatus = 1
+	stateLogin  = 2
+)
+
+// Packet IDs, status and login both use 0 for their first packet.
+const (
+	idHandshake  = 0x00
+	idStatus     = 0x00
+	idPing       = 0x01
+	idDisconnect = 0x00
+)
+
+// timeout bounds a whole status exchange, it is a few small packets.
+const timeout = 10 * time.Second
+
+// LoginRefusal is shown to a Java client that tries to join.
+const LoginRefusal = "This is a go-mine server, join with the go-mine client"
+
+// Handle answers one Java connection. r must read from c, it may hold bytes already peeked.
+// status is called once the client asks for it.
+func Handle(c net.Conn, r *bufio.Reader, status func() Status) error {
+	defer c.Close()
+	c.SetDeadline(time.Now().Add(timeout))
+	p, err := readPacket(r)
+	if err != nil {
+		return fmt.Errorf("error reading handshake: %v", err)
+	}
+	if p.id != idHandshake {
+		return fmt.Errorf("expected handshake, got packet %d", p.id)
+	}
+	br := &byteReader{b: p.data}
+	if _, err := ReadVarInt(br); err != nil { // client protocol version
+		return fmt.Errorf("error reading handshake: %v", err)
+	}
+	if _, err := br.string(255); err != nil { // address the client connected to
+		return fmt.Errorf("error reading handshake: %v", err)
+	}
+	if _, err := br.next(2); err != nil { // port
+		return fmt.Errorf("error reading handshake: %v", err)
+	}
+	next, err := ReadVarInt(br)
+	if err != nil {
+		return fmt.Errorf("error reading handshake: %v", err)
+	}
+	switch next {
+	case stateStatus:
+		return handleStatus(c, r, status)
+	case stateLogin:
+		msg, _ := json.Marshal(Description{Text: LoginRefusal})
+		return writePacket(c, idDisconnect, appendString(nil, string(msg)))
+	}
+	return fmt.Errorf("unknown next state %d", next)
+}
+
+func handleStatus(c net.Conn, r *bufio.Reader, status func() Status) error {
+	for {
+		p, err := readPacket(r)
+		if err != nil {
+			return err
+		}
+		switch p.id {
+		case idStatus:
+			js, err := json.Marshal(status())
+			if err != nil {
+				return err
+			}
+			if err := writePacket(c, idStatus, appendString(nil, string(js))); err != nil {
+				return err
+			}
+		case idPing:
+			if len(p.data) != 8 {
+				return fmt.Errorf("ping has %d bytes, expected 8", len(p.data))
+			}
+			// pong echoes the payload, then the exchange is over
+			return writePacket(c, idPing, p.data)
+		default:
+			return fmt.Errorf("unexpected status packet %d", p.id)
+		}
+	}
+}
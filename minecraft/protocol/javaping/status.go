@@ -0,0 +1,46 @@
+// Package javaping answers Minecraft Java Edition's server list ping, so a stock client can
+// show a go-mine server's MOTD and player count. Only the status handshake is supported.
+package javaping
+
+import (
+	"crypto/md5"
+	"fmt"
+)
+
+// Status is the JSON a server list shows.
+type Status struct {
+	Version     Version     `json:"version"`
+	Players     Players     `json:"players"`
+	Description Description `json:"description"`
+}
+
+type Version struct {
+	Name string `json:"name"`
+	// Protocol is compared with the client's, a mismatch shows Name in red instead of ping bars.
+	Protocol int32 `json:"protocol"`
+}
+
+type Players struct {
+	Max    int          `json:"max"`
+	Online int          `json:"online"`
+	Sample []PlayerInfo `json:"sample,omitempty"`
+}
+
+// PlayerInfo is shown when hovering over the player count.
+type PlayerInfo struct {
+	Name string `json:"name"`
+	ID   string `json:"id"`
+}
+
+// Description is a chat component, only plain text is needed for a MOTD.
+type Description struct {
+	Text string `json:"text"`
+}
+
+// OfflineUUID is the UUID Java servers in offline mode give name, a version 3 UUID of "OfflinePlayer:<name>".
+func OfflineUUID(name string) string {
+	h := md5.Sum([]byte("OfflinePlayer:" + name))
+	h[6] = h[6]&0x0f | 0x30
+	h[8] = h[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", h[0:4], h[4:6], h[6:8], h[8:10], h[10:16])
+}
@@ -0,0 +1,114 @@
+package javaping
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxPacketSize is the largest packet the Java protocol frames, a 3 byte VarInt.
+const maxPacketSize = 1<<21 - 1
+
+var errVarIntTooLong = errors.New("varint is too long")
+
+// AppendVarInt appends v in the Java protocol's little endian base 128 encoding.
+// Negative numbers always take 5 bytes.
+func AppendVarInt(b []byte, v int32) []byte {
+	u := uint32(v)
+	for u >= 0x80 {
+		b = append(b, byte(u)|0x80)
+		u >>= 7
+	}
+	return append(b, byte(u))
+}
+
+func ReadVarInt(r io.ByteReader) (int32, error) {
+	var v uint32
+	for i := 0; i < 5; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		v |= uint32(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			return int32(v), nil
+		}
+	}
+	return 0, errVarIntTooLong
+}
+
+func appendString(b []byte, s string) []byte {
+	b = AppendVarInt(b, int32(len(s)))
+	return append(b, s...)
+}
+
+// packet is one frame's ID and body.
+type packet struct {
+	id   int32
+	data []byte
+}
+
+func writePacket(w io.Writer, id int32, data []byte) error {
+	body := AppendVarInt(nil, id)
+	body = append(body, data...)
+	frame := AppendVarInt(nil, int32(len(body)))
+	_, err := w.Write(append(frame, body...))
+	return err
+}
+
+func readPacket(r *bufio.Reader) (packet, error) {
+	n, err := ReadVarInt(r)
+	if err != nil {
+		return packet{}, err
+	}
+	if n <= 0 || n > maxPacketSize {
+		return packet{}, fmt.Errorf("invalid packet size %d", n)
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return packet{}, err
+	}
+	br := &byteReader{b: body}
+	id, err := ReadVarInt(br)
+	if err != nil {
+		return packet{}, err
+	}
+	return packet{id: id, data: br.b}, nil
+}
+
+// byteReader reads fields out of a packet body.
+type byteReader struct {
+	b []byte
+}
+
+func (r *byteReader) ReadByte() (byte, error) {
+	if len(r.b) == 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	c := r.b[0]
+	r.b = r.b[1:]
+	return c, nil
+}
+
+func (r *byteReader) next(n int) ([]byte, error) {
+	if n < 0 || n > len(r.b) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	out := r.b[:n]
+	r.b = r.b[n:]
+	return out, nil
+}
+
+func (r *byteReader) string(limit int) (string, error) {
+	n, err := ReadVarInt(r)
+	if err != nil {
+		return "", err
+	}
+	// limit is in characters, which are at most 4 bytes of UTF-8
+	if int(n) > limit*4 {
+		return "", fmt.Errorf("string of %d bytes is too long", n)
+	}
+	b, err := r.next(int(n))
+	return string(b), err
+}
@@ -0,0 +1,191 @@
+// Package netsim wraps connections to add latency, so laggy links can be tested on one machine.
+package netsim
+
+import (
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+// Link describes the delay added to each write.
+type Link struct {
+	// Latency is added to every write.
+	Latency time.Duration
+	// Jitter is the most extra random delay, packets still arrive in order.
+	Jitter time.Duration
+	// Seed makes the jitter repeatable.
+	Seed int64
+	// Clock times delivery, nil uses the wall clock. Tests use a ManualClock to decide
+	// exactly when packets arrive.
+	Clock Clock
+}
+
+// Clock is the time source a Conn waits on.
+type Clock interface {
+	Now() time.Time
+	// Until sends on the channel once the time is t.
+	Until(t time.Time) <-chan time.Time
+}
+
+type wallClock struct{}
+
+func (wallClock) Now() time.Time                     { return time.Now() }
+func (wallClock) Until(t time.Time) <-chan time.Time { return time.After(time.Until(t)) }
+
+// ManualClock only moves when told to, so tests can step a link's delay deterministically.
+type ManualClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiting []waiter
+}
+
+type waiter struct {
+	at time.Time
+	c  chan time.Time
+}
+
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *ManualClock) Until(t time.Time) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := waiter{at: t, c: make(chan time.Time, 1)}
+	if !t.After(c.now) {
+		w.c <- c.now
+		return w.c
+	}
+	c.waiting = append(c.waiting, w)
+	return w.c
+}
+
+// Advance moves the clock on by d, waking everything waiting until then.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	waiting := c.waiting[:0]
+	for _, w := range c.waiting {
+		if w.at.After(c.now) {
+			waiting = append(waiting, w)
+			continue
+		}
+		w.c <- c.now
+	}
+	c.waiting = waiting
+}
+
+type delayed struct {
+	data []byte
+	at   time.Time
+}
+
+// Conn delays writes to the wrapped connection, reads are untouched.
+// Wrap both ends of a connection to delay both directions.
+type Conn struct {
+	net.Conn
+	link  Link
+	clock Clock
+
+	mu    sync.Mutex
+	rng   *rand.Rand
+	last  time.Time
+	queue chan delayed
+	err   error
+	done  chan struct{}
+	once  sync.Once
+}
+
+func Wrap(c net.Conn, link Link) *Conn {
+	clock := link.Clock
+	if clock == nil {
+		clock = wallClock{}
+	}
+	nc := &Conn{
+		Conn:  c,
+		link:  link,
+		clock: clock,
+		rng:   rand.New(rand.NewSource(link.Seed)),
+		queue: make(chan delayed, 1024),
+		done:  make(chan struct{}),
+	}
+	go nc.deliver()
+	return nc
+}
+
+func (c *Conn) deliver() {
+	for {
+		select {
+		case d := <-c.queue:
+			select {
+			case <-c.clock.Until(d.at):
+			case <-c.done:
+				return
+			}
+			if _, err := c.Conn.Write(d.data); err != nil {
+				c.mu.Lock()
+				c.err = err
+				c.mu.Unlock()
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// Write queues b to be sent after the link's delay. It only fails if an earlier delayed write did.
+func (c *Conn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	if c.err != nil {
+		err := c.err
+		c.mu.Unlock()
+		return 0, err
+	}
+	at := c.clock.Now().Add(c.link.Latency)
+	if c.link.Jitter > 0 {
+		at = at.Add(time.Duration(c.rng.Int63n(int64(c.link.Jitter))))
+	}
+	// never overtake an earlier write
+	if at.Before(c.last) {
+		at = c.last
+	}
+	c.last = at
+	c.mu.Unlock()
+
+	d := delayed{data: append([]byte(nil), b...), at: at}
+	select {
+	case c.queue <- d:
+		return len(b), nil
+	case <-c.done:
+		return 0, net.ErrClosed
+	}
+}
+
+// Close drops anything still in flight.
+func (c *Conn) Close() error {
+	c.once.Do(func() { close(c.done) })
+	return c.Conn.Close()
+}
+
+// Listener wraps every accepted connection.
+type Listener struct {
+	net.Listener
+	Link Link
+}
+
+func (l *Listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return Wrap(c, l.Link), nil
+}
@@ -0,0 +1,107 @@
+package netsim
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// pipe wraps the writing end of an in memory connection, everything read from the other
+// end is sent on the returned channel.
+func pipe(t *testing.T, link Link) (*Conn, <-chan string) {
+	t.Helper()
+	a, b := net.Pipe()
+	c := Wrap(a, link)
+	t.Cleanup(func() {
+		c.Close()
+		b.Close()
+	})
+	received := make(chan string, 16)
+	go func() {
+		defer close(received)
+		buf := make([]byte, 64)
+		for {
+			n, err := b.Read(buf)
+			if err != nil {
+				return
+			}
+			received <- string(buf[:n])
+		}
+	}()
+	return c, received
+}
+
+// nothing checks nothing arrives, giving the delivery goroutine a moment to go wrong.
+func nothing(t *testing.T, received <-chan string) {
+	t.Helper()
+	select {
+	case got := <-received:
+		t.Errorf("%q arrived early", got)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestLatency(t *testing.T) {
+	t.Parallel()
+	clock := NewManualClock(time.Unix(0, 0))
+	c, received := pipe(t, Link{Latency: 50 * time.Millisecond, Clock: clock})
+	if _, err := c.Write([]byte("one")); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	clock.Advance(49 * time.Millisecond)
+	nothing(t, received)
+	if _, err := c.Write([]byte("two")); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	clock.Advance(time.Millisecond)
+	if got := <-received; got != "one" {
+		t.Errorf("got %q, want one", got)
+	}
+	// two was written 49ms in, so it's due at 99ms
+	clock.Advance(48 * time.Millisecond)
+	nothing(t, received)
+	clock.Advance(time.Millisecond)
+	if got := <-received; got != "two" {
+		t.Errorf("got %q, want two", got)
+	}
+}
+
+func TestJitterKeepsOrder(t *testing.T) {
+	t.Parallel()
+	clock := NewManualClock(time.Unix(0, 0))
+	link := Link{Latency: 10 * time.Millisecond, Jitter: 30 * time.Millisecond, Seed: 1, Clock: clock}
+	c, received := pipe(t, link)
+	const want = "0123456789"
+	for _, b := range []byte(want) {
+		if _, err := c.Write([]byte{b}); err != nil {
+			t.Fatalf("Write() = %v", err)
+		}
+		clock.Advance(time.Millisecond)
+	}
+	// the first can't be due before the latency has passed
+	nothing(t, received)
+	clock.Advance(link.Latency + link.Jitter)
+	got := ""
+	for len(got) < len(want) {
+		got += <-received
+	}
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestClose(t *testing.T) {
+	t.Parallel()
+	clock := NewManualClock(time.Unix(0, 0))
+	c, received := pipe(t, Link{Latency: time.Millisecond, Clock: clock})
+	if _, err := c.Write([]byte("lost")); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() = %v", err)
+	}
+	clock.Advance(time.Second)
+	if got, ok := <-received; ok {
+		t.Errorf("%q arrived after Close", got)
+	}
+}
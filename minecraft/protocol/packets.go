@@ -0,0 +1,274 @@
+package protocol
+
+import (
+	"github.com/dragon1672/go-mine/minecraft/gamemode"
+	"github.com/dragon1672/go-mine/minecraft/physics"
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// Version must match between client and server, bump it on any packet layout change.
+const Version = 3
+
+type PacketID uint8
+
+const (
+	IDHello PacketID = iota + 1
+	IDWelcome
+	IDDisconnect
+	IDChunkData
+	IDUnloadChunk
+	IDBlockChange
+	IDPlayerPosition
+	IDPlayerJoin
+	IDPlayerLeave
+	IDChat
+	IDPlayerInput
+	IDPlayerState
+	IDGameMode
+	IDHealth
+)
+
+type Packet interface {
+	ID() PacketID
+	Encode(w *Writer)
+	Decode(r *Reader)
+}
+
+// newPacket makes an empty packet to decode into.
+func newPacket(id PacketID) Packet {
+	switch id {
+	case IDHello:
+		return &Hello{}
+	case IDWelcome:
+		return &Welcome{}
+	case IDDisconnect:
+		return &Disconnect{}
+	case IDChunkData:
+		return &ChunkData{}
+	case IDUnloadChunk:
+		return &UnloadChunk{}
+	case IDBlockChange:
+		return &BlockChange{}
+	case IDPlayerPosition:
+		return &PlayerPosition{}
+	case IDPlayerJoin:
+		return &PlayerJoin{}
+	case IDPlayerLeave:
+		return &PlayerLeave{}
+	case IDChat:
+		return &Chat{}
+	case IDPlayerInput:
+		return &PlayerInput{}
+	case IDPlayerState:
+		return &PlayerState{}
+	case IDGameMode:
+		return &GameMode{}
+	case IDHealth:
+		return &Health{}
+	}
+	return nil
+}
+
+// Hello is the first packet a client sends.
+type Hello struct {
+	Version uint16
+	Name    string
+	// Token is the server password, empty if the server has none.
+	Token string
+}
+
+func (p *Hello) ID() PacketID { return IDHello }
+func (p *Hello) Encode(w *Writer) {
+	w.U16(p.Version)
+	w.String(p.Name)
+	w.String(p.Token)
+}
+func (p *Hello) Decode(r *Reader) {
+	p.Version = r.U16()
+	p.Name = r.String()
+	p.Token = r.String()
+}
+
+// Welcome accepts a Hello.
+type Welcome struct {
+	PlayerID uint32
+	Spawn    vec.Vec3
+	Seed     int64
+}
+
+func (p *Welcome) ID() PacketID { return IDWelcome }
+func (p *Welcome) Encode(w *Writer) {
+	w.U32(p.PlayerID)
+	w.Vec3(p.Spawn)
+	w.I64(p.Seed)
+}
+func (p *Welcome) Decode(r *Reader) {
+	p.PlayerID = r.U32()
+	p.Spawn = r.Vec3()
+	p.Seed = r.I64()
+}
+
+// Disconnect is sent right before the server closes the connection.
+type Disconnect struct {
+	Reason string
+}
+
+func (p *Disconnect) ID() PacketID     { return IDDisconnect }
+func (p *Disconnect) Encode(w *Writer) { w.String(p.Reason) }
+func (p *Disconnect) Decode(r *Reader) { p.Reason = r.String() }
+
+// ChunkData carries a whole compressed chunk, see world.EncodeChunk.
+type ChunkData struct {
+	Pos  vec.IntVec3
+	Data []byte
+}
+
+func (p *ChunkData) ID() PacketID { return IDChunkData }
+func (p *ChunkData) Encode(w *Writer) {
+	w.IntVec3(p.Pos)
+	w.Bytes(p.Data)
+}
+func (p *ChunkData) Decode(r *Reader) {
+	p.Pos = r.IntVec3()
+	p.Data = r.Bytes()
+}
+
+// UnloadChunk tells the client it can forget a chunk.
+type UnloadChunk struct {
+	Pos vec.IntVec3
+}
+
+func (p *UnloadChunk) ID() PacketID     { return IDUnloadChunk }
+func (p *UnloadChunk) Encode(w *Writer) { w.IntVec3(p.Pos) }
+func (p *UnloadChunk) Decode(r *Reader) { p.Pos = r.IntVec3() }
+
+// BlockChange is a request from a client, or a broadcast from the server.
+type BlockChange struct {
+	Pos   vec.IntVec3
+	Block blocks.SimpleBlockType
+}
+
+func (p *BlockChange) ID() PacketID { return IDBlockChange }
+func (p *BlockChange) Encode(w *Writer) {
+	w.IntVec3(p.Pos)
+	w.U8(uint8(p.Block))
+}
+func (p *BlockChange) Decode(r *Reader) {
+	p.Pos = r.IntVec3()
+	p.Block = blocks.SimpleBlockType(r.U8())
+}
+
+// PlayerPosition is sent by the server for other players, or to move a client (such as a teleport).
+type PlayerPosition struct {
+	PlayerID uint32
+	Pos      vec.Vec3
+}
+
+func (p *PlayerPosition) ID() PacketID { return IDPlayerPosition }
+func (p *PlayerPosition) Encode(w *Writer) {
+	w.U32(p.PlayerID)
+	w.Vec3(p.Pos)
+}
+func (p *PlayerPosition) Decode(r *Reader) {
+	p.PlayerID = r.U32()
+	p.Pos = r.Vec3()
+}
+
+type PlayerJoin struct {
+	PlayerID uint32
+	Name     string
+	Pos      vec.Vec3
+}
+
+func (p *PlayerJoin) ID() PacketID { return IDPlayerJoin }
+func (p *PlayerJoin) Encode(w *Writer) {
+	w.U32(p.PlayerID)
+	w.String(p.Name)
+	w.Vec3(p.Pos)
+}
+func (p *PlayerJoin) Decode(r *Reader) {
+	p.PlayerID = r.U32()
+	p.Name = r.String()
+	p.Pos = r.Vec3()
+}
+
+type PlayerLeave struct {
+	PlayerID uint32
+}
+
+func (p *PlayerLeave) ID() PacketID     { return IDPlayerLeave }
+func (p *PlayerLeave) Encode(w *Writer) { w.U32(p.PlayerID) }
+func (p *PlayerLeave) Decode(r *Reader) { p.PlayerID = r.U32() }
+
+// Chat is a message, or a command if it starts with a slash.
+type Chat struct {
+	Message string
+}
+
+func (p *Chat) ID() PacketID     { return IDChat }
+func (p *Chat) Encode(w *Writer) { w.String(p.Message) }
+func (p *Chat) Decode(r *Reader) { p.Message = r.String() }
+
+// PlayerInput is one tick of movement from a client, the server runs it through physics.Step.
+type PlayerInput struct {
+	Input physics.Input
+}
+
+func (p *PlayerInput) ID() PacketID { return IDPlayerInput }
+func (p *PlayerInput) Encode(w *Writer) {
+	w.U32(p.Input.Seq)
+	w.F64(p.Input.Forward)
+	w.F64(p.Input.Strafe)
+	w.F64(p.Input.Yaw)
+	w.Bool(p.Input.Jump)
+	w.Bool(p.Input.Sneak)
+	w.Bool(p.Input.Fly)
+}
+func (p *PlayerInput) Decode(r *Reader) {
+	p.Input.Seq = r.U32()
+	p.Input.Forward = r.F64()
+	p.Input.Strafe = r.F64()
+	p.Input.Yaw = r.F64()
+	p.Input.Jump = r.Bool()
+	p.Input.Sneak = r.Bool()
+	p.Input.Fly = r.Bool()
+}
+
+// PlayerState is the server's result of running the client's inputs up to and including Seq.
+type PlayerState struct {
+	Seq   uint32
+	State physics.State
+}
+
+func (p *PlayerState) ID() PacketID { return IDPlayerState }
+func (p *PlayerState) Encode(w *Writer) {
+	w.U32(p.Seq)
+	w.Vec3(p.State.Pos)
+	w.Vec3(p.State.Vel)
+	w.Bool(p.State.OnGround)
+}
+func (p *PlayerState) Decode(r *Reader) {
+	p.Seq = r.U32()
+	p.State.Pos = r.Vec3()
+	p.State.Vel = r.Vec3()
+	p.State.OnGround = r.Bool()
+}
+
+// GameMode tells a client its player's mode, on joining and whenever it changes.
+type GameMode struct {
+	Mode gamemode.Mode
+}
+
+func (p *GameMode) ID() PacketID     { return IDGameMode }
+func (p *GameMode) Encode(w *Writer) { w.U8(uint8(p.Mode)) }
+func (p *GameMode) Decode(r *Reader) { p.Mode = gamemode.Mode(r.U8()) }
+
+// Health tells a client its player's health, out of gamemode.MaxHealth.
+type Health struct {
+	Health int32
+}
+
+func (p *Health) ID() PacketID     { return IDHealth }
+func (p *Health) Encode(w *Writer) { w.I32(p.Health) }
+func (p *Health) Decode(r *Reader) { p.Health = r.I32() }
@@ -0,0 +1,65 @@
+package protocol
+
+import (
+	"math"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dragon1672/go-mine/minecraft/gamemode"
+	"github.com/dragon1672/go-mine/minecraft/physics"
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+func TestRoundTrip(t *testing.T) {
+	t.Parallel()
+	packets := []Packet{
+		&Hello{Version: Version, Name: "alex", Token: "pw"},
+		&Welcome{PlayerID: 7, Spawn: vec.Vec3{X: 0.5, Y: 64, Z: -3.25}, Seed: -42},
+		&Disconnect{Reason: "bye"},
+		&ChunkData{Pos: vec.IntVec3{X: -1, Y: 2, Z: 3}, Data: []byte{1, 2, 3}},
+		&UnloadChunk{Pos: vec.IntVec3{X: 4, Y: -5, Z: 6}},
+		&BlockChange{Pos: vec.IntVec3{X: -100, Y: 70, Z: 9}, Block: blocks.Planks},
+		&PlayerPosition{PlayerID: 3, Pos: vec.Vec3{X: 1, Y: 2, Z: 3}},
+		&PlayerJoin{PlayerID: 3, Name: "steve", Pos: vec.Vec3{X: 1}},
+		&PlayerLeave{PlayerID: 3},
+		&Chat{Message: "hello world"},
+		&PlayerInput{Input: physics.Input{Seq: 9, Forward: 1, Strafe: -0.5, Yaw: 1.25, Jump: true, Fly: true}},
+		&PlayerState{Seq: 9, State: physics.State{Pos: vec.Vec3{X: 1, Y: 2, Z: 3}, Vel: vec.Vec3{Y: -4}, OnGround: true}},
+		&GameMode{Mode: gamemode.Spectator},
+		&Health{Health: 17},
+	}
+	a, b := net.Pipe()
+	ca, cb := NewConn(a), NewConn(b)
+	defer ca.Close()
+	defer cb.Close()
+	go func() {
+		for _, p := range packets {
+			if err := ca.WritePacket(p); err != nil {
+				t.Errorf("WritePacket(%T) = %v", p, err)
+				return
+			}
+		}
+	}()
+	for _, want := range packets {
+		got, err := cb.ReadPacket()
+		if err != nil {
+			t.Fatalf("ReadPacket() = %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ReadPacket() = %#v, want %#v", got, want)
+		}
+	}
+}
+
+func TestLongString(t *testing.T) {
+	t.Parallel()
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+	if err := NewConn(a).WritePacket(&Chat{Message: strings.Repeat("a", math.MaxUint16+1)}); err == nil {
+		t.Errorf("WritePacket() of a too long string expected error")
+	}
+}
@@ -0,0 +1,51 @@
+package rcon
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// RunCLI is the rcon subcommand. It runs the command given in args, or each line of in if there is none.
+func RunCLI(ctx context.Context, args []string, in io.Reader, out io.Writer) error {
+	fs := flag.NewFlagSet("rcon", flag.ContinueOnError)
+	addr := fs.String("addr", "localhost:25575", "server RCON address")
+	password := fs.String("password", os.Getenv("RCON_PASSWORD"), "RCON password, defaults to $RCON_PASSWORD")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	c, err := Dial(ctx, *addr, *password)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	run := func(cmd string) error {
+		resp, err := c.Command(cmd)
+		if err != nil {
+			return err
+		}
+		if resp != "" {
+			fmt.Fprintln(out, resp)
+		}
+		return nil
+	}
+	if fs.NArg() > 0 {
+		return run(strings.Join(fs.Args(), " "))
+	}
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := run(line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
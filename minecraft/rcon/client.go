@@ -0,0 +1,115 @@
+package rcon
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrAuth is returned by Dial when the server rejects the password.
+var ErrAuth = errors.New("rcon password rejected")
+
+// timeout bounds each request so a dead server doesn't hang a script.
+const timeout = 10 * time.Second
+
+// Client is an authenticated RCON connection. It is safe for concurrent use.
+type Client struct {
+	mu     sync.Mutex
+	c      net.Conn
+	r      *bufio.Reader
+	nextID int32
+}
+
+func Dial(ctx context.Context, addr, password string) (*Client, error) {
+	var d net.Dialer
+	c, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to %s: %v", addr, err)
+	}
+	cl := &Client{c: c, r: bufio.NewReader(c), nextID: 1}
+	if err := cl.auth(password); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return cl, nil
+}
+
+func (c *Client) Close() error {
+	return c.c.Close()
+}
+
+func (c *Client) id() int32 {
+	id := c.nextID
+	c.nextID++
+	return id
+}
+
+func (c *Client) read() (Packet, error) {
+	return readPacket(c.r, 1<<20)
+}
+
+func (c *Client) auth(password string) error {
+	c.c.SetDeadline(time.Now().Add(timeout))
+	defer c.c.SetDeadline(time.Time{})
+	id := c.id()
+	if err := (Packet{ID: id, Type: TypeAuth, Body: password}).write(c.c); err != nil {
+		return err
+	}
+	for {
+		p, err := c.read()
+		if errors.Is(err, io.EOF) {
+			return ErrAuth
+		}
+		if err != nil {
+			return fmt.Errorf("error reading auth response: %v", err)
+		}
+		if p.Type != TypeAuthResponse {
+			continue
+		}
+		if p.ID == authFailedID {
+			return ErrAuth
+		}
+		if p.ID != id {
+			return fmt.Errorf("auth response for request %d, expected %d", p.ID, id)
+		}
+		return nil
+	}
+}
+
+// Command runs cmd on the server and returns its output.
+func (c *Client) Command(cmd string) (string, error) {
+	if len(cmd) > MaxCommandSize {
+		return "", fmt.Errorf("command is %d bytes, the limit is %d", len(cmd), MaxCommandSize)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.c.SetDeadline(time.Now().Add(timeout))
+	defer c.c.SetDeadline(time.Time{})
+	id, end := c.id(), c.id()
+	if err := (Packet{ID: id, Type: TypeExec, Body: cmd}).write(c.c); err != nil {
+		return "", err
+	}
+	// the server mirrors this back after the last packet of the response
+	if err := (Packet{ID: end, Type: TypeResponse}).write(c.c); err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	for {
+		p, err := c.read()
+		if err != nil {
+			return "", fmt.Errorf("error reading response: %v", err)
+		}
+		switch p.ID {
+		case id:
+			out.WriteString(p.Body)
+		case end:
+			return out.String(), nil
+		}
+	}
+}
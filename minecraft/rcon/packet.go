@@ -0,0 +1,68 @@
+// Package rcon implements the Source RCON protocol Minecraft servers use for remote administration.
+package rcon
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Packet types. Exec and AuthResponse share a value, which one is meant depends on the direction.
+const (
+	TypeResponse     int32 = 0
+	TypeExec         int32 = 2
+	TypeAuthResponse int32 = 2
+	TypeAuth         int32 = 3
+)
+
+// Body limits from the protocol: clients may send a command of up to 1446 bytes, and
+// responses longer than 4096 bytes are split over several packets.
+const (
+	MaxCommandSize  = 1446
+	MaxResponseSize = 4096
+)
+
+// authFailedID is the request ID of an auth response rejecting the password.
+const authFailedID = -1
+
+// Packet is a request or response. On the wire it is a little endian int32 length, ID and type,
+// then the body and two null bytes.
+type Packet struct {
+	ID   int32
+	Type int32
+	Body string
+}
+
+func (p Packet) write(w io.Writer) error {
+	b := binary.LittleEndian.AppendUint32(nil, uint32(4+4+len(p.Body)+2))
+	b = binary.LittleEndian.AppendUint32(b, uint32(p.ID))
+	b = binary.LittleEndian.AppendUint32(b, uint32(p.Type))
+	b = append(b, p.Body...)
+	b = append(b, 0, 0)
+	_, err := w.Write(b)
+	return err
+}
+
+func readPacket(r *bufio.Reader, maxBody int) (Packet, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return Packet{}, err
+	}
+	n := int(int32(binary.LittleEndian.Uint32(size[:])))
+	if n < 10 || n > 10+maxBody {
+		return Packet{}, fmt.Errorf("invalid packet size %d", n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return Packet{}, err
+	}
+	if data[n-2] != 0 || data[n-1] != 0 {
+		return Packet{}, fmt.Errorf("packet body is not null terminated")
+	}
+	return Packet{
+		ID:   int32(binary.LittleEndian.Uint32(data[0:4])),
+		Type: int32(binary.LittleEndian.Uint32(data[4:8])),
+		Body: string(data[8 : n-2]),
+	}, nil
+}
@@ -0,0 +1,127 @@
+package rcon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dragon1672/go-mine/minecraft/command"
+	"github.com/dragon1672/go-mine/minecraft/world"
+)
+
+func startServer(t *testing.T) string {
+	t.Helper()
+	reg := command.NewRegistry()
+	if err := command.RegisterCore(reg, world.New(9)); err != nil {
+		t.Fatalf("RegisterCore() = %v", err)
+	}
+	err := reg.Register(&command.Command{
+		Name: "spam",
+		Run: func(ctx *command.Context) error {
+			for i := 0; i < 1000; i++ {
+				ctx.Replyf("line %04d", i)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Register() = %v", err)
+	}
+	s, err := NewServer(reg, "secret")
+	if err != nil {
+		t.Fatalf("NewServer() = %v", err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx, ln) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Serve() = %v", err)
+		}
+	})
+	return ln.Addr().String()
+}
+
+func TestRcon(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	addr := startServer(t)
+
+	t.Run("wrong password", func(t *testing.T) {
+		if _, err := Dial(ctx, addr, "guess"); !errors.Is(err, ErrAuth) {
+			t.Errorf("Dial() = %v, want ErrAuth", err)
+		}
+	})
+
+	t.Run("commands", func(t *testing.T) {
+		c, err := Dial(ctx, addr, "secret")
+		if err != nil {
+			t.Fatalf("Dial() = %v", err)
+		}
+		defer c.Close()
+		if got, err := c.Command("seed"); got != "Seed: 9" || err != nil {
+			t.Errorf("Command(seed) = %q, %v", got, err)
+		}
+		if got, err := c.Command("/seed"); got != "Seed: 9" || err != nil {
+			t.Errorf("Command(/seed) = %q, %v", got, err)
+		}
+		if got, err := c.Command("nope"); !strings.Contains(got, "unknown command") || err != nil {
+			t.Errorf("Command(nope) = %q, %v", got, err)
+		}
+		// 1000 lines of 9 bytes plus newlines spans three packets
+		got, err := c.Command("spam")
+		if err != nil {
+			t.Fatalf("Command(spam) = %v", err)
+		}
+		lines := strings.Split(got, "\n")
+		if len(lines) != 1000 || lines[0] != "line 0000" || lines[999] != "line 0999" {
+			t.Errorf("Command(spam) gave %d lines, first %q last %q", len(lines), lines[0], lines[len(lines)-1])
+		}
+		if _, err := c.Command(strings.Repeat("x", MaxCommandSize+1)); err == nil {
+			t.Errorf("Command() of an oversized command succeeded")
+		}
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		c, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatalf("Dial() = %v", err)
+		}
+		defer c.Close()
+		if err := (Packet{ID: 1, Type: TypeExec, Body: "seed"}).write(c); err != nil {
+			t.Fatalf("write() = %v", err)
+		}
+		if p, err := readPacket(bufio.NewReader(c), MaxResponseSize); err == nil {
+			t.Errorf("got %+v, want the connection closed", p)
+		}
+	})
+
+	t.Run("cli", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := RunCLI(ctx, []string{"-addr", addr, "-password", "secret", "time", "set", "noon"}, nil, &out); err != nil {
+			t.Fatalf("RunCLI() = %v", err)
+		}
+		in := strings.NewReader("seed\n\ntime query daytime\n")
+		if err := RunCLI(ctx, []string{"-addr", addr, "-password", "secret"}, in, &out); err != nil {
+			t.Fatalf("RunCLI() = %v", err)
+		}
+		if !strings.Contains(out.String(), "Seed: 9") || strings.Count(out.String(), "\n") != 3 {
+			t.Errorf("RunCLI() wrote %q", out.String())
+		}
+	})
+
+	if _, err := NewServer(command.NewRegistry(), ""); err == nil {
+		t.Errorf("NewServer() with no password succeeded")
+	}
+}
@@ -0,0 +1,128 @@
+package rcon
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/dragon1672/go-mine/minecraft/command"
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/golang/glog"
+)
+
+// Server runs commands for authenticated RCON clients with owner permission.
+type Server struct {
+	reg      *command.Registry
+	password string
+}
+
+// NewServer makes a server for reg, password must not be empty.
+func NewServer(reg *command.Registry, password string) (*Server, error) {
+	if password == "" {
+		return nil, errors.New("rcon needs a password")
+	}
+	return &Server{reg: reg, password: password}, nil
+}
+
+// Serve accepts connections on ln until ctx is done or ln is closed.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("error accepting rcon connection: %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer c.Close()
+			// closing the connection is the only way to interrupt a blocked read
+			stop := context.AfterFunc(ctx, func() { c.Close() })
+			defer stop()
+			if err := s.handle(c); err != nil {
+				glog.Infof("RCON connection from %v ended: %v", c.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+func (s *Server) handle(c net.Conn) error {
+	r := bufio.NewReader(c)
+	authed := false
+	for {
+		p, err := readPacket(r, MaxCommandSize)
+		if err != nil {
+			return err
+		}
+		switch {
+		case p.Type == TypeAuth:
+			if subtle.ConstantTimeCompare([]byte(p.Body), []byte(s.password)) != 1 {
+				glog.Warningf("RCON login from %v with a wrong password", c.RemoteAddr())
+				Packet{ID: authFailedID, Type: TypeAuthResponse}.write(c)
+				return errors.New("wrong password")
+			}
+			authed = true
+			// clients expect an empty response before the auth result
+			if err := (Packet{ID: p.ID, Type: TypeResponse}).write(c); err != nil {
+				return err
+			}
+			if err := (Packet{ID: p.ID, Type: TypeAuthResponse}).write(c); err != nil {
+				return err
+			}
+		case !authed:
+			return errors.New("command before authenticating")
+		case p.Type == TypeExec:
+			if err := s.exec(c, p); err != nil {
+				return err
+			}
+		case p.Type == TypeResponse:
+			// mirrored back so clients can tell where a multi-packet response ends
+			if err := (Packet{ID: p.ID, Type: TypeResponse}).write(c); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown packet type %d", p.Type)
+		}
+	}
+}
+
+func (s *Server) exec(c net.Conn, p Packet) error {
+	src := &source{}
+	glog.Infof("RCON from %v: %s", c.RemoteAddr(), p.Body)
+	if err := s.reg.Execute(src, p.Body); err != nil {
+		src.Reply(err.Error())
+	}
+	out := strings.Join(src.lines, "\n")
+	// always send at least one packet, even for no output
+	for first := true; first || out != ""; first = false {
+		n := min(len(out), MaxResponseSize)
+		if err := (Packet{ID: p.ID, Type: TypeResponse, Body: out[:n]}).write(c); err != nil {
+			return err
+		}
+		out = out[n:]
+	}
+	return nil
+}
+
+// source runs RCON commands as the server owner and collects the replies.
+type source struct {
+	lines []string
+}
+
+func (s *source) Name() string               { return "Rcon" }
+func (s *source) Permission() int            { return command.PermOwner }
+func (s *source) Position() (vec.Vec3, bool) { return vec.Vec3{}, false }
+func (s *source) Reply(msg string)           { s.lines = append(s.lines, msg) }
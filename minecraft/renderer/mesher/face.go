@@ -0,0 +1,105 @@
+package mesher
+
+import "github.com/dragon1672/go-mine/minecraft/utils/vec"
+
+// Face is one side of a block.
+type Face int
+
+const (
+	Up Face = iota
+	Down
+	North // -Z
+	South // +Z
+	East  // +X
+	West  // -X
+)
+
+// Faces is every face, in order.
+var Faces = [...]Face{Up, Down, North, South, East, West}
+
+func (f Face) String() string {
+	switch f {
+	case Up:
+		return "up"
+	case Down:
+		return "down"
+	case North:
+		return "north"
+	case South:
+		return "south"
+	case East:
+		return "east"
+	case West:
+		return "west"
+	}
+	return "unknown"
+}
+
+// Normal points out of the block through the face.
+func (f Face) Normal() vec.IntVec3 {
+	return faceQuads[f].normal
+}
+
+// faceQuad places a face's quad on the unit block. The corners are origin, origin+du,
+// origin+du+dv and origin+dv, which with du × dv = normal winds them counter-clockwise
+// seen from outside.
+type faceQuad struct {
+	normal, origin, du, dv vec.IntVec3
+	// shade darkens the sides and bottom like Minecraft's directional lighting.
+	shade float32
+}
+
+var faceQuads = [...]faceQuad{
+	Up:    {normal: vec.IntVec3{Y: 1}, origin: vec.IntVec3{Y: 1}, du: vec.IntVec3{Z: 1}, dv: vec.IntVec3{X: 1}, shade: 1},
+	Down:  {normal: vec.IntVec3{Y: -1}, du: vec.IntVec3{X: 1}, dv: vec.IntVec3{Z: 1}, shade: 0.5},
+	North: {normal: vec.IntVec3{Z: -1}, du: vec.IntVec3{Y: 1}, dv: vec.IntVec3{X: 1}, shade: 0.8},
+	South: {normal: vec.IntVec3{Z: 1}, origin: vec.IntVec3{Z: 1}, du: vec.IntVec3{X: 1}, dv: vec.IntVec3{Y: 1}, shade: 0.8},
+	East:  {normal: vec.IntVec3{X: 1}, origin: vec.IntVec3{X: 1}, du: vec.IntVec3{Y: 1}, dv: vec.IntVec3{Z: 1}, shade: 0.6},
+	West:  {normal: vec.IntVec3{X: -1}, du: vec.IntVec3{Z: 1}, dv: vec.IntVec3{Y: 1}, shade: 0.6},
+}
+
+// texCoord maps a corner p of a quad size blocks across on face f to texture space,
+// in blocks so the texture repeats once per block. The texture is upright on the sides
+// and V runs down the image.
+func texCoord(f Face, p, size vec.IntVec3) (u, v float32) {
+	x, y, z := float32(p.X), float32(p.Y), float32(p.Z)
+	w, h, d := float32(size.X), float32(size.Y), float32(size.Z)
+	switch f {
+	case North:
+		return w - x, h - y
+	case South:
+		return x, h - y
+	case East:
+		return d - z, h - y
+	case West:
+		return z, h - y
+	}
+	return x, z
+}
+
+// axis is which of X, Y and Z a unit vector points along.
+func axis(v vec.IntVec3) int {
+	switch {
+	case v.X != 0:
+		return 0
+	case v.Y != 0:
+		return 1
+	}
+	return 2
+}
+
+func withAxis(v vec.IntVec3, axis, n int) vec.IntVec3 {
+	switch axis {
+	case 0:
+		v.X = n
+	case 1:
+		v.Y = n
+	default:
+		v.Z = n
+	}
+	return v
+}
+
+func scale(v vec.IntVec3, n int) vec.IntVec3 {
+	return vec.IntVec3{X: v.X * n, Y: v.Y * n, Z: v.Z * n}
+}
@@ -0,0 +1,119 @@
+package mesher
+
+import (
+	"math"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+)
+
+// MaxLight is the light level of open sky.
+const MaxLight = 15
+
+// brightness maps light levels to colour, each level a fifth darker than the one above
+// like Minecraft's light curve.
+var brightness = func() (b [MaxLight + 1]float32) {
+	for l := range b {
+		b[l] = float32(math.Pow(0.8, float64(MaxLight-l)))
+	}
+	return b
+}()
+
+// occlusion maps how many of a corner's neighbours are open, 0 to 3, to how lit it is.
+var occlusion = [4]float32{0.4, 0.6, 0.8, 1}
+
+// lightMap is a light level for every block in a Volume.
+type lightMap [padded * padded * padded]uint8
+
+func (l *lightMap) at(p vec.IntVec3) uint8 {
+	return l[paddedIndex(p.X, p.Y, p.Z)]
+}
+
+// edge is the last coordinate of the volume's border.
+const edge = world.ChunkSize
+
+func inVolume(p vec.IntVec3) bool {
+	return p.X >= -1 && p.X <= edge && p.Y >= -1 && p.Y <= edge && p.Z >= -1 && p.Z <= edge
+}
+
+var neighbours = [...]vec.IntVec3{{X: 1}, {X: -1}, {Y: 1}, {Y: -1}, {Z: 1}, {Z: -1}}
+
+// skylight lights v from what it alone holds: columns open to the top of the volume get
+// full sky light, which then spreads sideways and down losing a level per block. It doesn't
+// know what lies further up, so caves just below a chunk boundary come out too bright.
+func skylight(v *Volume) *lightMap {
+	l := &lightMap{}
+	var queue []vec.IntVec3
+	for z := -1; z <= edge; z++ {
+		for x := -1; x <= edge; x++ {
+			for y := edge; y >= -1 && !v.At(x, y, z).IsOpaque(); y-- {
+				l[paddedIndex(x, y, z)] = MaxLight
+				queue = append(queue, vec.IntVec3{X: x, Y: y, Z: z})
+			}
+		}
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		next := l.at(p) - 1
+		if next == 0 {
+			continue
+		}
+		for _, d := range neighbours {
+			n := p.Add(d)
+			if !inVolume(n) || v.At(n.X, n.Y, n.Z).IsOpaque() || l.at(n) >= next {
+				continue
+			}
+			l[paddedIndex(n.X, n.Y, n.Z)] = next
+			queue = append(queue, n)
+		}
+	}
+	return l
+}
+
+// corner is the lighting at one corner of a face.
+type corner struct {
+	// ao is how many of the three blocks around the corner are open.
+	ao uint8
+	// light is the brightness averaged over the open blocks touching the corner.
+	light float32
+}
+
+// corners lights the four corners of face f of the block at pos, in the order quad places
+// them. Each corner looks at the block in front of the face and the three around it that
+// share the corner, two along the sides and one diagonal.
+func corners(v *Volume, l *lightMap, pos vec.IntVec3, q faceQuad) (c [4]corner) {
+	front := pos.Add(q.normal)
+	for i, s := range [4][2]int{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}} {
+		side1 := front.Add(scale(q.du, s[0]))
+		side2 := front.Add(scale(q.dv, s[1]))
+		diag := side1.Add(scale(q.dv, s[1]))
+		open1, open2 := !v.At(side1.X, side1.Y, side1.Z).IsOpaque(), !v.At(side2.X, side2.Y, side2.Z).IsOpaque()
+		// light can't get round into the corner between two solid sides
+		openDiag := (open1 || open2) && !v.At(diag.X, diag.Y, diag.Z).IsOpaque()
+		sum, n := brightness[l.at(front)], 1
+		for _, o := range []struct {
+			open bool
+			p    vec.IntVec3
+		}{{open1, side1}, {open2, side2}, {openDiag, diag}} {
+			if o.open {
+				c[i].ao++
+				sum += brightness[l.at(o.p)]
+				n++
+			}
+		}
+		c[i].light = sum / float32(n)
+	}
+	return c
+}
+
+// uniform reports whether every corner is lit the same, so the face can be merged with
+// its neighbours without changing how it looks.
+func uniform(c [4]corner) bool {
+	return c[0] == c[1] && c[0] == c[2] && c[0] == c[3]
+}
+
+// lit is how bright the corner is, 0 to 1.
+func (c corner) lit() float32 {
+	return c.light * occlusion[c.ao]
+}
@@ -0,0 +1,230 @@
+// Package mesher turns chunks of blocks into vertex arrays ready to upload to the GPU.
+// It makes no GL calls so meshes can be built on any goroutine and tested headlessly.
+package mesher
+
+import (
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// Vertex is laid out to be uploaded as is, positions are relative to the chunk's origin.
+type Vertex struct {
+	Pos    [3]float32
+	Normal [3]float32
+	// UV counts blocks across the quad, so it runs past 1 on merged quads and the texture
+	// repeats once per block.
+	UV [2]float32
+	// Tile is the face's part of the texture as U0, V0, U1, V1. To keep repeats inside an
+	// atlas tile sample at Tile.xy + fract(UV) * (Tile.zw - Tile.xy).
+	Tile  [4]float32
+	Color [4]float32
+}
+
+// Mesh is a chunk's geometry as indexed triangles, two per quad.
+type Mesh struct {
+	Vertices []Vertex
+	Indices  []uint32
+	// Translucent is where the translucent triangles start in Indices. They need drawing
+	// after everything opaque, with blending on.
+	Translucent int
+}
+
+// Quads is how many quads the mesh holds.
+func (m *Mesh) Quads() int {
+	return len(m.Vertices) / 4
+}
+
+// UVRect is the part of the texture a face is drawn with.
+type UVRect struct {
+	U0, V0, U1, V1 float32
+}
+
+// Textures picks each block face's part of the texture.
+type Textures interface {
+	UV(b blocks.SimpleBlockType, f Face) UVRect
+	// Named finds a texture by name for a Shape.
+	Named(name string) (UVRect, bool)
+}
+
+// wholeTexture draws every face with the full texture.
+type wholeTexture struct{}
+
+func (wholeTexture) UV(blocks.SimpleBlockType, Face) UVRect { return UVRect{U1: 1, V1: 1} }
+
+func (wholeTexture) Named(string) (UVRect, bool) { return UVRect{U1: 1, V1: 1}, true }
+
+// Mode is how faces are turned into quads.
+type Mode int
+
+const (
+	// Culled draws one quad per visible face.
+	Culled Mode = iota
+	// Greedy merges neighbouring faces of the same block type into larger quads, which
+	// cuts vertex counts a lot on flat terrain.
+	Greedy
+)
+
+// Options configures Build, the zero value culls hidden faces and merges nothing.
+type Options struct {
+	Mode Mode
+	// BlockModes overrides Mode for particular block types.
+	BlockModes map[blocks.SimpleBlockType]Mode
+	// Textures maps faces into a texture atlas, nil draws every face with the whole texture.
+	Textures Textures
+	// Shapes draws blocks that aren't cubes.
+	Shapes map[blocks.SimpleBlockType]*Shape
+}
+
+func (o Options) mode(b blocks.SimpleBlockType) Mode {
+	if m, ok := o.BlockModes[b]; ok {
+		return m
+	}
+	return o.Mode
+}
+
+// visible reports whether a face of b against neighbour n can be seen. Faces between two
+// blocks of the same type are hidden so water and leaves don't draw their insides.
+func visible(b, n blocks.SimpleBlockType) bool {
+	return n != b && !n.IsOpaque()
+}
+
+// builder collects quads into separate opaque and translucent meshes.
+type builder struct {
+	opts                Options
+	light               *lightMap
+	opaque, translucent Mesh
+}
+
+// cell is a face waiting to be merged, only faces lit the same all over are merged.
+type cell struct {
+	b blocks.SimpleBlockType
+	c corner
+}
+
+// Build meshes the chunk in v, drawing only faces that aren't hidden by a neighbouring block.
+// Ambient occlusion and smooth lighting are baked into the vertex colours.
+func Build(v *Volume, opts Options) *Mesh {
+	if opts.Textures == nil {
+		opts.Textures = wholeTexture{}
+	}
+	bl := &builder{opts: opts, light: skylight(v)}
+	if len(opts.Shapes) > 0 {
+		bl.shapes(v)
+	}
+	for _, f := range Faces {
+		for d := 0; d < world.ChunkSize; d++ {
+			bl.slice(v, f, d)
+		}
+	}
+	return bl.finish()
+}
+
+// slice meshes face f of the blocks in one layer, d along the face's normal.
+// Faces to be merged are collected into a mask indexed along the quad's du and dv axes.
+func (bl *builder) slice(v *Volume, f Face, d int) {
+	q := faceQuads[f]
+	na, ua, va := axis(q.normal), axis(q.du), axis(q.dv)
+	var mask [world.ChunkSize * world.ChunkSize]cell
+	merging := false
+	for j := 0; j < world.ChunkSize; j++ {
+		for i := 0; i < world.ChunkSize; i++ {
+			pos := withAxis(withAxis(withAxis(vec.IntVec3{}, na, d), ua, i), va, j)
+			b := v.At(pos.X, pos.Y, pos.Z)
+			if b == blocks.Air || bl.opts.Shapes[b] != nil {
+				continue
+			}
+			n := pos.Add(q.normal)
+			if !visible(b, v.At(n.X, n.Y, n.Z)) {
+				continue
+			}
+			c := corners(v, bl.light, pos, q)
+			if bl.opts.mode(b) != Greedy || !uniform(c) {
+				bl.quad(pos, b, f, 1, 1, c)
+				continue
+			}
+			mask[j*world.ChunkSize+i] = cell{b, c[0]}
+			merging = true
+		}
+	}
+	if !merging {
+		return
+	}
+	for j := 0; j < world.ChunkSize; j++ {
+		for i := 0; i < world.ChunkSize; {
+			b := mask[j*world.ChunkSize+i]
+			if b.b == blocks.Air {
+				i++
+				continue
+			}
+			w := 1
+			for i+w < world.ChunkSize && mask[j*world.ChunkSize+i+w] == b {
+				w++
+			}
+			h := 1
+		grow:
+			for j+h < world.ChunkSize {
+				for k := i; k < i+w; k++ {
+					if mask[(j+h)*world.ChunkSize+k] != b {
+						break grow
+					}
+				}
+				h++
+			}
+			for dj := 0; dj < h; dj++ {
+				for k := i; k < i+w; k++ {
+					mask[(j+dj)*world.ChunkSize+k] = cell{}
+				}
+			}
+			bl.quad(withAxis(withAxis(withAxis(vec.IntVec3{}, na, d), ua, i), va, j), b.b, f, w, h, [4]corner{b.c, b.c, b.c, b.c})
+			i += w
+		}
+	}
+}
+
+// quad adds face f of a w by h run of blocks starting at pos, w along the face's du and h along dv,
+// with corners lit by c.
+func (bl *builder) quad(pos vec.IntVec3, b blocks.SimpleBlockType, f Face, w, h int, c [4]corner) {
+	q := faceQuads[f]
+	m := &bl.opaque
+	if b.IsTranslucent() {
+		m = &bl.translucent
+	}
+	uv := bl.opts.Textures.UV(b, f)
+	tile := [4]float32{uv.U0, uv.V0, uv.U1, uv.V1}
+	normal := [3]float32{float32(q.normal.X), float32(q.normal.Y), float32(q.normal.Z)}
+	du, dv := scale(q.du, w), scale(q.dv, h)
+	size := vec.IntVec3{X: 1, Y: 1, Z: 1}.Add(du).Add(dv).Sub(q.du).Sub(q.dv)
+	first := uint32(len(m.Vertices))
+	for i, corner := range [4]vec.IntVec3{q.origin, q.origin.Add(du), q.origin.Add(du).Add(dv), q.origin.Add(dv)} {
+		u, v := texCoord(f, corner, size)
+		p := pos.Add(corner)
+		l := q.shade * c[i].lit()
+		m.Vertices = append(m.Vertices, Vertex{
+			Pos:    [3]float32{float32(p.X), float32(p.Y), float32(p.Z)},
+			Normal: normal,
+			UV:     [2]float32{u, v},
+			Tile:   tile,
+			Color:  [4]float32{l, l, l, 1},
+		})
+	}
+	// split along the darker diagonal, otherwise the interpolation smears a dark
+	// corner across half the quad and the shading changes with the quad's orientation
+	if c[0].lit()+c[2].lit() > c[1].lit()+c[3].lit() {
+		m.Indices = append(m.Indices, first+1, first+2, first+3, first+1, first+3, first)
+		return
+	}
+	m.Indices = append(m.Indices, first, first+1, first+2, first, first+2, first+3)
+}
+
+// finish joins the translucent mesh onto the end of the opaque one.
+func (bl *builder) finish() *Mesh {
+	m := &bl.opaque
+	m.Translucent = len(m.Indices)
+	offset := uint32(len(m.Vertices))
+	m.Vertices = append(m.Vertices, bl.translucent.Vertices...)
+	for _, i := range bl.translucent.Indices {
+		m.Indices = append(m.Indices, i+offset)
+	}
+	return m
+}
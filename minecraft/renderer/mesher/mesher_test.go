@@ -0,0 +1,357 @@
+package mesher
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// skyChunk is far above generated terrain so it starts as all air.
+var skyChunk = vec.IntVec3{Y: 12}
+
+// volume builds a sky volume holding blocks, which may reach into the border.
+func volume(blocks map[vec.IntVec3]blocks.SimpleBlockType) *Volume {
+	v := &Volume{Pos: skyChunk}
+	for p, b := range blocks {
+		v.Set(p.X, p.Y, p.Z, b)
+	}
+	return v
+}
+
+func sub(a, b [3]float32) [3]float32 {
+	return [3]float32{a[0] - b[0], a[1] - b[1], a[2] - b[2]}
+}
+
+func cross(a, b [3]float32) [3]float32 {
+	return [3]float32{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+func TestSingleBlock(t *testing.T) {
+	t.Parallel()
+	m := Build(volume(map[vec.IntVec3]blocks.SimpleBlockType{{X: 3, Y: 4, Z: 5}: blocks.Stone}), Options{})
+	if m.Quads() != 6 || len(m.Indices) != 36 || m.Translucent != 36 {
+		t.Fatalf("got %d quads, %d indices, translucent from %d, want a cube", m.Quads(), len(m.Indices), m.Translucent)
+	}
+	for i := 0; i < len(m.Indices); i += 3 {
+		a, b, c := m.Vertices[m.Indices[i]], m.Vertices[m.Indices[i+1]], m.Vertices[m.Indices[i+2]]
+		// counter-clockwise from outside means the winding agrees with the normal
+		if n := cross(sub(b.Pos, a.Pos), sub(c.Pos, a.Pos)); n != a.Normal {
+			t.Errorf("triangle %d winds towards %v, normal is %v", i/3, n, a.Normal)
+		}
+		for _, v := range []Vertex{a, b, c} {
+			for axis, want := range []float32{3, 4, 5} {
+				if v.Pos[axis] != want && v.Pos[axis] != want+1 {
+					t.Errorf("vertex %v is off the block", v.Pos)
+				}
+			}
+		}
+	}
+	// the top face's texture should cover the whole texture once
+	seen := map[[2]float32]bool{}
+	for _, v := range m.Vertices[:4] {
+		seen[v.UV] = true
+	}
+	if len(seen) != 4 || !seen[[2]float32{0, 0}] || !seen[[2]float32{1, 1}] {
+		t.Errorf("top face UVs are %v", seen)
+	}
+}
+
+func TestCulling(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name            string
+		blocks          map[vec.IntVec3]blocks.SimpleBlockType
+		opaque, translu int
+	}{
+		{
+			name:   "Touching stone",
+			blocks: map[vec.IntVec3]blocks.SimpleBlockType{{X: 1}: blocks.Stone, {X: 2}: blocks.Stone},
+			opaque: 10,
+		},
+		{
+			name:   "Stone seen through leaves",
+			blocks: map[vec.IntVec3]blocks.SimpleBlockType{{X: 1}: blocks.Stone, {X: 2}: blocks.Leaves},
+			opaque: 11,
+		},
+		{
+			name:    "Water against water and stone",
+			blocks:  map[vec.IntVec3]blocks.SimpleBlockType{{X: 1}: blocks.Water, {X: 2}: blocks.Water, {X: 3}: blocks.Stone},
+			opaque:  6,
+			translu: 9,
+		},
+		{
+			name:   "Hidden by the neighbouring chunk",
+			blocks: map[vec.IntVec3]blocks.SimpleBlockType{{X: 15}: blocks.Stone, {X: 16}: blocks.Dirt},
+			opaque: 5,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := Build(volume(tc.blocks), Options{})
+			if got := m.Translucent / 6; got != tc.opaque {
+				t.Errorf("got %d opaque quads, want %d", got, tc.opaque)
+			}
+			if got := (len(m.Indices) - m.Translucent) / 6; got != tc.translu {
+				t.Errorf("got %d translucent quads, want %d", got, tc.translu)
+			}
+		})
+	}
+}
+
+func TestLighting(t *testing.T) {
+	t.Parallel()
+	block := vec.IntVec3{X: 5, Y: 5, Z: 5}
+	top := func(extra ...vec.IntVec3) *Mesh {
+		bs := map[vec.IntVec3]blocks.SimpleBlockType{block: blocks.Stone}
+		for _, p := range extra {
+			bs[p] = blocks.Stone
+		}
+		// the block's top comes first, it's the lowest up face
+		return Build(volume(bs), Options{})
+	}
+	open := top().Vertices[0].Color
+	if open != [4]float32{1, 1, 1, 1} {
+		t.Errorf("open top is %v, want full brightness", open)
+	}
+	t.Run("Occluded side", func(t *testing.T) {
+		m := top(block.Add(vec.IntVec3{X: 1, Y: 1}))
+		for _, v := range m.Vertices[:4] {
+			if dark := v.Pos[0] == 6; dark != (v.Color[0] < open[0]) {
+				t.Errorf("vertex %v is %v", v.Pos, v.Color)
+			}
+		}
+	})
+	for _, d := range []vec.IntVec3{{X: 1, Y: 1, Z: 1}, {X: -1, Y: 1, Z: 1}} {
+		t.Run(fmt.Sprintf("Diagonal %v", d), func(t *testing.T) {
+			m := top(block.Add(d))
+			want := [3]float32{5.5 + float32(d.X)/2, 6, 6}
+			dark := -1
+			for i, v := range m.Vertices[:4] {
+				if v.Color[0] < open[0] {
+					if dark >= 0 || v.Pos != want {
+						t.Fatalf("vertex %v is %v, only %v should be dark", v.Pos, v.Color, want)
+					}
+					dark = i
+				}
+			}
+			// both triangles share the diagonal through the dark corner
+			for tri := 0; tri < 2; tri++ {
+				idx := m.Indices[tri*3 : tri*3+3]
+				if idx[0] != uint32(dark) && idx[1] != uint32(dark) && idx[2] != uint32(dark) {
+					t.Errorf("triangle %v misses the dark corner %d", idx, dark)
+				}
+			}
+		})
+	}
+	t.Run("Smooth light", func(t *testing.T) {
+		// a roof two blocks up shades the top, light still creeps in from the sides
+		m := top(block.Add(vec.IntVec3{Y: 2}))
+		for _, v := range m.Vertices[:4] {
+			if c := v.Color[0]; c >= open[0] || c < open[0]/2 {
+				t.Errorf("vertex %v is %v, want a little darker than %v", v.Pos, v.Color, open)
+			}
+		}
+	})
+}
+
+func TestShapes(t *testing.T) {
+	t.Parallel()
+	// a slab top that hides against a block above, and a plane through the middle
+	slab := &Shape{Quads: []ShapeQuad{
+		{Corners: [4][3]float32{{0, 1, 0}, {0, 1, 1}, {1, 1, 1}, {1, 1, 0}}, Normal: [3]float32{0, 1, 0}, Cull: Up, Culls: true, Shade: true},
+		{Corners: [4][3]float32{{0, 1, 0.5}, {0, 0, 0.5}, {1, 0, 0.5}, {1, 1, 0.5}}, Normal: [3]float32{0, 0, 1}},
+	}}
+	opts := Options{Shapes: map[blocks.SimpleBlockType]*Shape{blocks.Planks: slab}}
+	open := Build(volume(map[vec.IntVec3]blocks.SimpleBlockType{{X: 2}: blocks.Planks}), opts)
+	if open.Quads() != 2 {
+		t.Errorf("got %d quads, want 2", open.Quads())
+	}
+	if v := open.Vertices[0]; v.Pos != [3]float32{2, 1, 0} || v.Color != [4]float32{1, 1, 1, 1} {
+		t.Errorf("first vertex is %+v", v)
+	}
+	covered := Build(volume(map[vec.IntVec3]blocks.SimpleBlockType{{X: 2}: blocks.Planks, {X: 2, Y: 1}: blocks.Stone}), opts)
+	// the plane, plus the stone without its bottom
+	if covered.Quads() != 6 {
+		t.Errorf("got %d quads, want 6 with the top culled", covered.Quads())
+	}
+}
+
+func TestFromWorld(t *testing.T) {
+	t.Parallel()
+	w := world.New(1)
+	origin := world.ChunkOrigin(skyChunk)
+	w.SetBlock(origin, blocks.Stone)
+	// across the border in the chunk below, which then hides the stone's bottom face
+	w.SetBlock(origin.Down(), blocks.Stone)
+	// in a chunk that isn't loaded, which counts as air
+	if _, ok := FromWorld(w, skyChunk.Up().Up()); ok {
+		t.Errorf("FromWorld() of an unloaded chunk should fail")
+	}
+	v, ok := FromWorld(w, skyChunk)
+	if !ok {
+		t.Fatalf("FromWorld() failed")
+	}
+	if v.At(0, 0, 0) != blocks.Stone || v.At(0, -1, 0) != blocks.Stone || v.At(-1, 0, 0) != blocks.Air {
+		t.Errorf("volume didn't copy the chunk and its border")
+	}
+	if m := Build(v, Options{}); m.Quads() != 5 {
+		t.Errorf("got %d quads, want 5 with the bottom hidden", m.Quads())
+	}
+}
+
+func TestGreedy(t *testing.T) {
+	t.Parallel()
+	plane := map[vec.IntVec3]blocks.SimpleBlockType{}
+	for z := 0; z < world.ChunkSize; z++ {
+		for x := 0; x < world.ChunkSize; x++ {
+			plane[vec.IntVec3{X: x, Z: z}] = blocks.Stone
+		}
+	}
+	// only the top is checked, the shade under the plane keeps its other faces apart
+	t.Run("Flat plane", func(t *testing.T) {
+		m := Build(volume(plane), Options{Mode: Greedy})
+		if got := upQuads(m); got != 1 {
+			t.Fatalf("got %d quads on top, want 1", got)
+		}
+		seen := map[[2]float32]bool{}
+		for _, v := range m.Vertices[:4] {
+			seen[v.UV] = true
+			if v.Tile != [4]float32{0, 0, 1, 1} {
+				t.Errorf("tile is %v, want the whole texture", v.Tile)
+			}
+		}
+		// the texture repeats once per block across the top
+		if len(seen) != 4 || !seen[[2]float32{0, 0}] || !seen[[2]float32{16, 16}] {
+			t.Errorf("top face UVs are %v", seen)
+		}
+	})
+	t.Run("Mixed types", func(t *testing.T) {
+		mixed := map[vec.IntVec3]blocks.SimpleBlockType{}
+		for p, b := range plane {
+			mixed[p] = b
+		}
+		mixed[vec.IntVec3{X: 5, Z: 5}] = blocks.Dirt
+		m := Build(volume(mixed), Options{Mode: Greedy})
+		// the dirt splits the top into 4 quads around it, plus its own
+		if got := upQuads(m); got != 5 {
+			t.Errorf("got %d quads on top, want 5", got)
+		}
+	})
+	t.Run("Per block mode", func(t *testing.T) {
+		mixed := map[vec.IntVec3]blocks.SimpleBlockType{}
+		for p, b := range plane {
+			if p.X < 8 {
+				b = blocks.Dirt
+			}
+			mixed[p] = b
+		}
+		// stone stays merged, dirt is drawn face by face
+		m := Build(volume(mixed), Options{Mode: Greedy, BlockModes: map[blocks.SimpleBlockType]Mode{blocks.Dirt: Culled}})
+		if got, want := upQuads(m), 8*16+1; got != want {
+			t.Errorf("got %d quads on top, want %d", got, want)
+		}
+	})
+}
+
+func upQuads(m *Mesh) int {
+	n := 0
+	for i := 0; i < len(m.Vertices); i += 4 {
+		if m.Vertices[i].Normal == [3]float32{0, 1, 0} {
+			n++
+		}
+	}
+	return n
+}
+
+// area sums the blocks each face type and block type's quads cover.
+func area(m *Mesh, v *Volume) map[[2]int]float32 {
+	got := map[[2]int]float32{}
+	for i := 0; i < len(m.Vertices); i += 4 {
+		q := m.Vertices[i : i+4]
+		n := q[0].Normal
+		f := 0
+		for ; faceQuads[f].normal != (vec.IntVec3{X: int(n[0]), Y: int(n[1]), Z: int(n[2])}); f++ {
+		}
+		// any block under the quad, just behind its plane
+		var p [3]float32
+		for axis := range p {
+			p[axis] = min(q[0].Pos[axis], q[2].Pos[axis]) + 0.5 - n[axis]*0.5
+			if n[axis] < 0 {
+				p[axis] = q[0].Pos[axis] + 0.5
+			}
+		}
+		b := v.At(int(p[0]), int(p[1]), int(p[2]))
+		du, dv := sub(q[1].Pos, q[0].Pos), sub(q[3].Pos, q[0].Pos)
+		c := cross(du, dv)
+		got[[2]int{f, int(b)}] += c[0]*n[0] + c[1]*n[1] + c[2]*n[2]
+	}
+	return got
+}
+
+func TestGreedyMatchesCulled(t *testing.T) {
+	t.Parallel()
+	v := terrain(t, vec.IntVec3{})
+	culled, greedy := Build(v, Options{}), Build(v, Options{Mode: Greedy})
+	want, got := area(culled, v), area(greedy, v)
+	for k, a := range want {
+		if got[k] != a {
+			t.Errorf("%v faces of %v cover %v blocks, want %v", Face(k[0]), blocks.SimpleBlockType(k[1]), got[k], a)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("greedy mesh covers %d face and block types, want %d", len(got), len(want))
+	}
+}
+
+func TestGreedyVertexCount(t *testing.T) {
+	t.Parallel()
+	var culled, greedy int
+	for z := -1; z <= 1; z++ {
+		for x := -1; x <= 1; x++ {
+			v := terrain(t, vec.IntVec3{X: x, Z: z})
+			culled += len(Build(v, Options{}).Vertices)
+			greedy += len(Build(v, Options{Mode: Greedy}).Vertices)
+		}
+	}
+	t.Logf("9 chunks of seed 42 terrain: %d vertices culled, %d greedy", culled, greedy)
+	if greedy*3 > culled*2 {
+		t.Errorf("greedy meshing only cut %d vertices to %d", culled, greedy)
+	}
+}
+
+// terrain is a chunk of generated surface at chunk position pos with its neighbours loaded.
+func terrain(tb testing.TB, pos vec.IntVec3) *Volume {
+	w := world.New(42)
+	for dy := -1; dy <= 1; dy++ {
+		for dz := -1; dz <= 1; dz++ {
+			for dx := -1; dx <= 1; dx++ {
+				w.Chunk(pos.Add(vec.IntVec3{X: dx, Y: dy, Z: dz}))
+			}
+		}
+	}
+	v, ok := FromWorld(w, pos)
+	if !ok {
+		tb.Fatalf("FromWorld() failed")
+	}
+	return v
+}
+
+func BenchmarkBuild(b *testing.B) {
+	v := terrain(b, vec.IntVec3{})
+	for _, mode := range []struct {
+		name string
+		mode Mode
+	}{
+		{"Culled", Culled},
+		{"Greedy", Greedy},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m := Build(v, Options{Mode: mode.mode})
+				b.ReportMetric(float64(len(m.Vertices)), "vertices")
+			}
+		})
+	}
+}
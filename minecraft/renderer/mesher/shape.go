@@ -0,0 +1,92 @@
+package mesher
+
+import (
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// Shape is a block drawn from its own quads rather than as a cube, like a flower.
+// Shapes are compiled from block models by the model package.
+type Shape struct {
+	Quads []ShapeQuad
+}
+
+// ShapeQuad is one quad of a Shape.
+type ShapeQuad struct {
+	// Corners are in block space, 0 to 1, counter-clockwise seen from the front.
+	Corners [4][3]float32
+	// UV places each corner on the texture, 0 to 1 across it.
+	UV     [4][2]float32
+	Normal [3]float32
+	// Texture is looked up with Textures.Named.
+	Texture string
+	// Cull is the side of the block the quad lies on, if Culls it's hidden whenever that
+	// face of a cube would be.
+	Cull  Face
+	Culls bool
+	// Shade darkens the quad by its direction like the faces of a cube.
+	Shade bool
+}
+
+// shapes draws every block in v that has a Shape.
+func (bl *builder) shapes(v *Volume) {
+	for y := 0; y < world.ChunkSize; y++ {
+		for z := 0; z < world.ChunkSize; z++ {
+			for x := 0; x < world.ChunkSize; x++ {
+				b := v.At(x, y, z)
+				if s := bl.opts.Shapes[b]; s != nil {
+					bl.shape(v, vec.IntVec3{X: x, Y: y, Z: z}, b, s)
+				}
+			}
+		}
+	}
+}
+
+func (bl *builder) shape(v *Volume, pos vec.IntVec3, b blocks.SimpleBlockType, s *Shape) {
+	m := &bl.opaque
+	if b.IsTranslucent() {
+		m = &bl.translucent
+	}
+	for _, q := range s.Quads {
+		// lit by the block the quad faces into, or the block itself for quads inside it
+		lightAt := pos
+		if q.Culls {
+			n := pos.Add(q.Cull.Normal())
+			if !visible(b, v.At(n.X, n.Y, n.Z)) {
+				continue
+			}
+			lightAt = n
+		}
+		l := brightness[bl.light.at(lightAt)]
+		if q.Shade {
+			l *= shadeOf(q.Normal)
+		}
+		uv, ok := bl.opts.Textures.Named(q.Texture)
+		if !ok {
+			uv = bl.opts.Textures.UV(b, Up)
+		}
+		first := uint32(len(m.Vertices))
+		for i, c := range q.Corners {
+			m.Vertices = append(m.Vertices, Vertex{
+				Pos:    [3]float32{float32(pos.X) + c[0], float32(pos.Y) + c[1], float32(pos.Z) + c[2]},
+				Normal: q.Normal,
+				UV:     q.UV[i],
+				Tile:   [4]float32{uv.U0, uv.V0, uv.U1, uv.V1},
+				Color:  [4]float32{l, l, l, 1},
+			})
+		}
+		m.Indices = append(m.Indices, first, first+1, first+2, first, first+2, first+3)
+	}
+}
+
+// shadeOf is the directional shade of the cube face closest to normal.
+func shadeOf(normal [3]float32) float32 {
+	best, shade := float32(-2), float32(1)
+	for _, q := range faceQuads {
+		if d := normal[0]*float32(q.normal.X) + normal[1]*float32(q.normal.Y) + normal[2]*float32(q.normal.Z); d > best {
+			best, shade = d, q.shade
+		}
+	}
+	return shade
+}
@@ -0,0 +1,78 @@
+package mesher
+
+import (
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// padded is a chunk's edge length plus a one block border on each side.
+const padded = world.ChunkSize + 2
+
+// Volume is a copy of one chunk plus a one block border from its neighbours, which is
+// everything needed to decide which faces are visible.
+type Volume struct {
+	Pos    vec.IntVec3
+	blocks [padded * padded * padded]blocks.SimpleBlockType
+}
+
+func paddedIndex(x, y, z int) int {
+	return ((y+1)*padded+z+1)*padded + x + 1
+}
+
+// At reads a block relative to the chunk's origin, each coordinate may be -1 to ChunkSize.
+func (v *Volume) At(x, y, z int) blocks.SimpleBlockType {
+	return v.blocks[paddedIndex(x, y, z)]
+}
+
+// Set changes a block, coordinates are as for At.
+func (v *Volume) Set(x, y, z int, t blocks.SimpleBlockType) {
+	v.blocks[paddedIndex(x, y, z)] = t
+}
+
+// FromWorld copies the chunk at chunk position pos and its border out of w. Neighbours that
+// aren't loaded count as air. ok is false if the chunk itself isn't loaded.
+func FromWorld(w *world.World, pos vec.IntVec3) (v *Volume, ok bool) {
+	v = &Volume{Pos: pos}
+	for dy := -1; dy <= 1; dy++ {
+		for dz := -1; dz <= 1; dz++ {
+			for dx := -1; dx <= 1; dx++ {
+				d := vec.IntVec3{X: dx, Y: dy, Z: dz}
+				found := w.ViewChunk(pos.Add(d), func(c *world.Chunk) { v.copyFrom(c, d) })
+				if d == (vec.IntVec3{}) {
+					ok = found
+				}
+			}
+		}
+	}
+	if !ok {
+		return nil, false
+	}
+	return v, true
+}
+
+// span is the range of local coordinates a neighbour at offset d along one axis contributes.
+func span(d int) (lo, hi int) {
+	switch d {
+	case -1:
+		return world.ChunkSize - 1, world.ChunkSize - 1
+	case 1:
+		return 0, 0
+	}
+	return 0, world.ChunkSize - 1
+}
+
+// copyFrom copies the part of c, the neighbour at offset d, that falls inside the volume.
+func (v *Volume) copyFrom(c *world.Chunk, d vec.IntVec3) {
+	x0, x1 := span(d.X)
+	y0, y1 := span(d.Y)
+	z0, z1 := span(d.Z)
+	for y := y0; y <= y1; y++ {
+		for z := z0; z <= z1; z++ {
+			for x := x0; x <= x1; x++ {
+				b := c.Get(vec.IntVec3{X: x, Y: y, Z: z})
+				v.Set(x+d.X*world.ChunkSize, y+d.Y*world.ChunkSize, z+d.Z*world.ChunkSize, b)
+			}
+		}
+	}
+}
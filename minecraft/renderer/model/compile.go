@@ -0,0 +1,168 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/dragon1672/go-mine/minecraft/renderer/mesher"
+)
+
+// sides places each face on its element. corners are top left, bottom left, bottom right
+// and top right of the texture seen from outside, which is counter-clockwise, and pick
+// From (0) or To (1) on each axis.
+var sides = map[string]struct {
+	face    mesher.Face
+	corners [4][3]int
+}{
+	"north": {mesher.North, [4][3]int{{1, 1, 0}, {1, 0, 0}, {0, 0, 0}, {0, 1, 0}}},
+	"south": {mesher.South, [4][3]int{{0, 1, 1}, {0, 0, 1}, {1, 0, 1}, {1, 1, 1}}},
+	"east":  {mesher.East, [4][3]int{{1, 1, 1}, {1, 0, 1}, {1, 0, 0}, {1, 1, 0}}},
+	"west":  {mesher.West, [4][3]int{{0, 1, 0}, {0, 0, 0}, {0, 0, 1}, {0, 1, 1}}},
+	"up":    {mesher.Up, [4][3]int{{0, 1, 0}, {0, 1, 1}, {1, 1, 1}, {1, 1, 0}}},
+	"down":  {mesher.Down, [4][3]int{{0, 0, 1}, {0, 0, 0}, {1, 0, 0}, {1, 0, 1}}},
+}
+
+// sideOrder keeps compiled quads in a fixed order.
+var sideOrder = []string{"down", "up", "north", "south", "west", "east"}
+
+// defaultUV is the part of the texture a face shows when its model doesn't say, as if
+// the texture were wrapped around the block.
+func defaultUV(side string, from, to [3]float32) [4]float32 {
+	switch side {
+	case "north":
+		return [4]float32{16 - to[0], 16 - to[1], 16 - from[0], 16 - from[1]}
+	case "south":
+		return [4]float32{from[0], 16 - to[1], to[0], 16 - from[1]}
+	case "east":
+		return [4]float32{16 - to[2], 16 - to[1], 16 - from[2], 16 - from[1]}
+	case "west":
+		return [4]float32{from[2], 16 - to[1], to[2], 16 - from[1]}
+	case "up":
+		return [4]float32{from[0], from[2], to[0], to[2]}
+	}
+	return [4]float32{from[0], 16 - to[2], to[0], 16 - from[2]}
+}
+
+// Compile turns a loaded model into a mesher shape.
+func Compile(m *Model) (*mesher.Shape, error) {
+	s := &mesher.Shape{}
+	for i, e := range m.Elements {
+		for side := range e.Faces {
+			if _, ok := sides[side]; !ok {
+				return nil, fmt.Errorf("element %d: unknown face %q", i, side)
+			}
+		}
+		for _, side := range sideOrder {
+			f, ok := e.Faces[side]
+			if !ok {
+				continue
+			}
+			q, err := compileFace(m, e, side, f)
+			if err != nil {
+				return nil, fmt.Errorf("element %d %s: %v", i, side, err)
+			}
+			s.Quads = append(s.Quads, q)
+		}
+	}
+	return s, nil
+}
+
+func compileFace(m *Model, e Element, side string, f Face) (mesher.ShapeQuad, error) {
+	sd := sides[side]
+	q := mesher.ShapeQuad{Shade: e.Shade == nil || *e.Shade}
+	texture, err := resolve(m, f.Texture)
+	if err != nil {
+		return q, err
+	}
+	q.Texture = texture
+	if f.CullFace != "" {
+		cull, ok := sides[f.CullFace]
+		if !ok {
+			return q, fmt.Errorf("unknown cullface %q", f.CullFace)
+		}
+		q.Cull, q.Culls = cull.face, true
+	}
+
+	for i, c := range sd.corners {
+		var p [3]float32
+		for axis, to := range c {
+			p[axis] = e.From[axis]
+			if to == 1 {
+				p[axis] = e.To[axis]
+			}
+		}
+		if e.Rotation != nil {
+			if p, err = rotate(p, *e.Rotation); err != nil {
+				return q, err
+			}
+		}
+		q.Corners[i] = [3]float32{p[0] / 16, p[1] / 16, p[2] / 16}
+	}
+	q.Normal = normal(q.Corners)
+
+	uv := defaultUV(side, e.From, e.To)
+	if f.UV != nil {
+		uv = *f.UV
+	}
+	if f.Rotation%90 != 0 {
+		return q, fmt.Errorf("texture rotation %d isn't a multiple of 90", f.Rotation)
+	}
+	corners := [4][2]float32{{uv[0], uv[1]}, {uv[0], uv[3]}, {uv[2], uv[3]}, {uv[2], uv[1]}}
+	// turning the texture clockwise moves each corner's texel on to the next corner
+	turn := (f.Rotation/90%4 + 4) % 4
+	for i := range q.UV {
+		c := corners[(i+turn)%4]
+		q.UV[i] = [2]float32{c[0] / 16, c[1] / 16}
+	}
+	return q, nil
+}
+
+// resolve follows "#variable" texture references to a texture name, dropping the
+// namespace and "block/" to match the atlas's tile names.
+func resolve(m *Model, texture string) (string, error) {
+	for depth := 0; strings.HasPrefix(texture, "#"); depth++ {
+		next, ok := m.Textures[texture[1:]]
+		if !ok || depth == maxDepth {
+			return "", fmt.Errorf("texture variable %s isn't set", texture)
+		}
+		texture = next
+	}
+	if texture == "" {
+		return "", fmt.Errorf("no texture")
+	}
+	_, path := location(texture)
+	return strings.TrimPrefix(path, "block/"), nil
+}
+
+// rotate turns p about r's axis, first stretching it away from the axis if r rescales.
+func rotate(p [3]float32, r Rotation) ([3]float32, error) {
+	axis := strings.Index("xyz", r.Axis)
+	if axis < 0 || len(r.Axis) != 1 {
+		return p, fmt.Errorf("unknown rotation axis %q", r.Axis)
+	}
+	// the two axes turned, in right handed order
+	a, b := (axis+1)%3, (axis+2)%3
+	rad := float64(r.Angle) * math.Pi / 180
+	sin, cos := float32(math.Sin(rad)), float32(math.Cos(rad))
+	da, db := p[a]-r.Origin[a], p[b]-r.Origin[b]
+	if r.Rescale {
+		da, db = da/cos, db/cos
+	}
+	p[a] = r.Origin[a] + da*cos - db*sin
+	p[b] = r.Origin[b] + da*sin + db*cos
+	return p, nil
+}
+
+func normal(c [4][3]float32) [3]float32 {
+	var u, v [3]float32
+	for i := range u {
+		u[i], v[i] = c[1][i]-c[0][i], c[2][i]-c[0][i]
+	}
+	n := [3]float32{u[1]*v[2] - u[2]*v[1], u[2]*v[0] - u[0]*v[2], u[0]*v[1] - u[1]*v[0]}
+	l := float32(math.Sqrt(float64(n[0]*n[0] + n[1]*n[1] + n[2]*n[2])))
+	if l == 0 {
+		return n
+	}
+	return [3]float32{n[0] / l, n[1] / l, n[2] / l}
+}
@@ -0,0 +1,142 @@
+// Package model reads Minecraft's block model JSON and compiles it into mesher shapes for
+// blocks that aren't cubes.
+package model
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/dragon1672/go-mine/minecraft/renderer/mesher"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+//go:embed assets
+var builtin embed.FS
+
+// Builtin holds the models that ship with the game, laid out like a resource pack.
+var Builtin fs.FS = builtin
+
+// Model is a block model file. Loader.Load fills in everything inherited from parents.
+type Model struct {
+	Parent   string            `json:"parent"`
+	Textures map[string]string `json:"textures"`
+	Elements []Element         `json:"elements"`
+}
+
+// Element is a box, From and To are in sixteenths of a block.
+type Element struct {
+	From     [3]float32      `json:"from"`
+	To       [3]float32      `json:"to"`
+	Rotation *Rotation       `json:"rotation"`
+	Shade    *bool           `json:"shade"`
+	Faces    map[string]Face `json:"faces"`
+}
+
+// Rotation turns an element about one axis through Origin. Angle is in degrees and
+// Rescale stretches the element back out to fill the block.
+type Rotation struct {
+	Origin  [3]float32 `json:"origin"`
+	Axis    string     `json:"axis"`
+	Angle   float32    `json:"angle"`
+	Rescale bool       `json:"rescale"`
+}
+
+// Face is one side of an element. UV is a rectangle in sixteenths of the texture, left
+// out it's taken from where the face sits on the block.
+type Face struct {
+	UV       *[4]float32 `json:"uv"`
+	Texture  string      `json:"texture"`
+	CullFace string      `json:"cullface"`
+	Rotation int         `json:"rotation"`
+}
+
+// Loader finds models in resource packs, earlier packs first, then Builtin.
+type Loader struct {
+	packs []fs.FS
+}
+
+func NewLoader(packs ...fs.FS) *Loader {
+	return &Loader{packs: append(packs, Builtin)}
+}
+
+// maxDepth stops parent loops.
+const maxDepth = 16
+
+// Load reads the model at resource location name, as in "minecraft:block/cross", and
+// merges in its parents: textures are combined with the child's winning, and elements
+// come from the nearest model that has any.
+func (l *Loader) Load(name string) (*Model, error) {
+	m := &Model{Textures: map[string]string{}}
+	for depth := 0; name != ""; depth++ {
+		if depth == maxDepth {
+			return nil, fmt.Errorf("model %s: too many parents", name)
+		}
+		parent, err := l.read(name)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range parent.Textures {
+			if _, ok := m.Textures[k]; !ok {
+				m.Textures[k] = v
+			}
+		}
+		if m.Elements == nil {
+			m.Elements = parent.Elements
+		}
+		name = parent.Parent
+	}
+	return m, nil
+}
+
+func (l *Loader) read(name string) (*Model, error) {
+	ns, path := location(name)
+	file := "assets/" + ns + "/models/" + path + ".json"
+	for _, p := range l.packs {
+		b, err := fs.ReadFile(p, file)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("model %s: %v", name, err)
+		}
+		var m Model
+		if err := json.Unmarshal(b, &m); err != nil {
+			return nil, fmt.Errorf("model %s: %v", name, err)
+		}
+		return &m, nil
+	}
+	return nil, fmt.Errorf("model %s: %w", name, fs.ErrNotExist)
+}
+
+// location splits a resource location into namespace and path, minecraft by default.
+func location(name string) (ns, path string) {
+	ns, path, ok := strings.Cut(name, ":")
+	if !ok {
+		return "minecraft", name
+	}
+	return ns, path
+}
+
+// Shaped lists the blocks drawn from models, everything else is a cube.
+var Shaped = []blocks.SimpleBlockType{blocks.Flower}
+
+// Shapes compiles the model "block/<name>" of each Shaped block, ready for mesher.Options.
+func (l *Loader) Shapes() (map[blocks.SimpleBlockType]*mesher.Shape, error) {
+	shapes := map[blocks.SimpleBlockType]*mesher.Shape{}
+	for _, b := range Shaped {
+		m, err := l.Load("block/" + strings.ToLower(b.String()))
+		if err != nil {
+			return nil, err
+		}
+		s, err := Compile(m)
+		if err != nil {
+			return nil, fmt.Errorf("model for %v: %v", b, err)
+		}
+		shapes[b] = s
+	}
+	return shapes, nil
+}
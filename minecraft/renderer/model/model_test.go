@@ -0,0 +1,165 @@
+package model
+
+import (
+	"errors"
+	"io/fs"
+	"math"
+	"testing"
+	"testing/fstest"
+
+	"github.com/dragon1672/go-mine/minecraft/renderer/mesher"
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+const cube = `{
+	"textures": {"particle": "#all"},
+	"elements": [{
+		"from": [0, 0, 0], "to": [16, 16, 16],
+		"faces": {
+			"down":  {"texture": "#all", "cullface": "down"},
+			"up":    {"texture": "#all", "cullface": "up", "rotation": 90},
+			"north": {"texture": "#all", "cullface": "north"},
+			"south": {"texture": "#all", "cullface": "south"},
+			"west":  {"texture": "#all", "cullface": "west"},
+			"east":  {"texture": "#all", "cullface": "east", "uv": [0, 0, 8, 8]}
+		}
+	}]
+}`
+
+func pack(models map[string]string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for name, json := range models {
+		fsys["assets/minecraft/models/"+name+".json"] = &fstest.MapFile{Data: []byte(json)}
+	}
+	return fsys
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+	t.Run("Builtin", func(t *testing.T) {
+		m, err := NewLoader().Load("minecraft:block/flower")
+		if err != nil {
+			t.Fatalf("Load() = %v", err)
+		}
+		// the texture from flower, the rest from cross
+		if m.Textures["cross"] != "block/flower" || m.Textures["particle"] != "#cross" || len(m.Elements) != 2 {
+			t.Errorf("got textures %v and %d elements", m.Textures, len(m.Elements))
+		}
+	})
+	t.Run("Pack overrides", func(t *testing.T) {
+		l := NewLoader(pack(map[string]string{
+			"block/cube":   cube,
+			"block/flower": `{"parent": "block/cube", "textures": {"all": "block/poppy"}}`,
+		}))
+		m, err := l.Load("block/flower")
+		if err != nil {
+			t.Fatalf("Load() = %v", err)
+		}
+		if m.Textures["all"] != "block/poppy" || len(m.Elements) != 1 {
+			t.Errorf("got textures %v and %d elements", m.Textures, len(m.Elements))
+		}
+	})
+	t.Run("Errors", func(t *testing.T) {
+		l := NewLoader(pack(map[string]string{
+			"block/a":      `{"parent": "block/b"}`,
+			"block/b":      `{"parent": "block/a"}`,
+			"block/broken": `{"elements": 5}`,
+		}))
+		if _, err := l.Load("block/a"); err == nil {
+			t.Errorf("Load() of a parent loop should fail")
+		}
+		if _, err := l.Load("block/broken"); err == nil {
+			t.Errorf("Load() of bad JSON should fail")
+		}
+		if _, err := l.Load("block/missing"); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Load() of a missing model = %v, want not exist", err)
+		}
+	})
+}
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-4
+}
+
+func TestCompile(t *testing.T) {
+	t.Parallel()
+	m, err := NewLoader(pack(map[string]string{"block/cube": cube})).Load("block/cube")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Textures["all"] = "minecraft:block/stone"
+	s, err := Compile(m)
+	if err != nil {
+		t.Fatalf("Compile() = %v", err)
+	}
+	if len(s.Quads) != 6 {
+		t.Fatalf("got %d quads, want 6", len(s.Quads))
+	}
+	for i, f := range []mesher.Face{mesher.Down, mesher.Up, mesher.North, mesher.South, mesher.West, mesher.East} {
+		q := s.Quads[i]
+		n := f.Normal()
+		if q.Normal != [3]float32{float32(n.X), float32(n.Y), float32(n.Z)} {
+			t.Errorf("%v faces %v", f, q.Normal)
+		}
+		if !q.Culls || q.Cull != f || q.Texture != "stone" || !q.Shade {
+			t.Errorf("%v is %+v", f, q)
+		}
+		// every corner lies on the face's side of the block
+		for _, c := range q.Corners {
+			want := float32(max(n.X+n.Y+n.Z, 0))
+			if got := c[0]*float32(n.X*n.X) + c[1]*float32(n.Y*n.Y) + c[2]*float32(n.Z*n.Z); got != want {
+				t.Errorf("%v corner %v is off its side", f, c)
+			}
+		}
+	}
+	if uv := s.Quads[2].UV; uv != [4][2]float32{{0, 0}, {0, 1}, {1, 1}, {1, 0}} {
+		t.Errorf("north UVs are %v, want the whole texture upright", uv)
+	}
+	if uv := s.Quads[1].UV; uv != [4][2]float32{{0, 1}, {1, 1}, {1, 0}, {0, 0}} {
+		t.Errorf("up UVs turned 90 degrees are %v", uv)
+	}
+	if uv := s.Quads[5].UV; uv[2] != [2]float32{0.5, 0.5} {
+		t.Errorf("east UVs are %v, want the top left quarter", uv)
+	}
+
+	m.Elements[0].Faces["up"] = Face{Texture: "#missing"}
+	if _, err := Compile(m); err == nil {
+		t.Errorf("Compile() with an unset texture variable should fail")
+	}
+}
+
+func TestFlower(t *testing.T) {
+	t.Parallel()
+	shapes, err := NewLoader().Shapes()
+	if err != nil {
+		t.Fatalf("Shapes() = %v", err)
+	}
+	s := shapes[blocks.Flower]
+	if s == nil || len(s.Quads) != 4 {
+		t.Fatalf("flower shape is %+v, want two double sided planes", s)
+	}
+	for _, q := range s.Quads {
+		if q.Texture != "flower" || q.Culls || q.Shade {
+			t.Errorf("quad is %+v", q)
+		}
+		// rescaled planes run corner to corner across the block
+		for _, c := range q.Corners {
+			if !(near(c[0], 0.05) || near(c[0], 0.95)) || !(near(c[2], 0.05) || near(c[2], 0.95)) {
+				t.Errorf("corner %v isn't on the block's diagonal", c)
+			}
+		}
+		if q.Normal[1] != 0 || !near(q.Normal[0]*q.Normal[0]+q.Normal[2]*q.Normal[2], 1) {
+			t.Errorf("normal %v isn't horizontal", q.Normal)
+		}
+	}
+
+	v := &mesher.Volume{Pos: vec.IntVec3{Y: 12}}
+	v.Set(3, 3, 3, blocks.Flower)
+	if got := mesher.Build(v, mesher.Options{}).Quads(); got != 6 {
+		t.Errorf("flower as a cube has %d quads, want 6", got)
+	}
+	if got := mesher.Build(v, mesher.Options{Shapes: shapes}).Quads(); got != 4 {
+		t.Errorf("flower has %d quads, want 4", got)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/dragon1672/go-mine/minecraft/world/worldtime"
 	"github.com/go-gl/gl/v2.1/gl"
 	"github.com/go-gl/glfw/v3.3/glfw"
 )
@@ -22,9 +23,15 @@ type Drawable interface {
 	Cleanup()
 }
 
+// Sky provides the background colour each frame is cleared to.
+type Sky interface {
+	SkyColor() worldtime.RGB
+}
+
 type Window struct {
 	window     *glfw.Window
 	items      []Drawable
+	sky        Sky
 	lastRender time.Time
 }
 
@@ -67,6 +74,10 @@ func GetWindow() (*Window, error) {
 func (w *Window) drawAll(t time.Time) error {
 	// All Open GL calls need to be on the main thread :(
 	// Might be able to figure out a dispatcher or something to make this more sane to work with
+	if w.sky != nil {
+		c := w.sky.SkyColor()
+		gl.ClearColor(c.R, c.G, c.B, 0.0)
+	}
 	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 
 	dt := t.Sub(w.lastRender)
@@ -96,6 +107,11 @@ func (w *Window) AddItem(obj Drawable) {
 	w.items = append(w.items, obj) // TODO thread safety
 }
 
+// SetSky makes the background follow s instead of a fixed clear colour.
+func (w *Window) SetSky(s Sky) {
+	w.sky = s
+}
+
 func (w *Window) GetWindow() *glfw.Window {
 	return w.window
 }
@@ -0,0 +1,132 @@
+package textures
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+)
+
+// Animation is the animation section of a texture's .png.mcmeta. The texture holds its
+// frames stacked top to bottom.
+type Animation struct {
+	// FrameTime is how many ticks each frame shows for, 1 if unset.
+	FrameTime int `json:"frametime"`
+	// Interpolate blends each frame into the next rather than cutting.
+	Interpolate bool `json:"interpolate"`
+	// Frames is the order to show frames in, every frame in order if empty.
+	Frames []Frame `json:"frames"`
+	// Width and Height are a frame's size, the texture's width by default.
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Frame is one step of an animation, Time overrides the animation's FrameTime if set.
+type Frame struct {
+	Index int `json:"index"`
+	Time  int `json:"time"`
+}
+
+// UnmarshalJSON accepts a frame as just its index, or as an object with a time.
+func (f *Frame) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &f.Index); err == nil {
+		return nil
+	}
+	type frame Frame
+	return json.Unmarshal(b, (*frame)(f))
+}
+
+// ParseAnimation reads a .png.mcmeta, returning nil if it has no animation.
+func ParseAnimation(b []byte) (*Animation, error) {
+	var meta struct {
+		Animation *Animation `json:"animation"`
+	}
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return nil, fmt.Errorf("bad animation: %v", err)
+	}
+	return meta.Animation, nil
+}
+
+// animated is an animation playing in an atlas tile.
+type animated struct {
+	frames   []*image.RGBA
+	sequence []Frame
+	period   int
+	blend    bool
+	rect     image.Rectangle
+	scratch  *image.RGBA
+	shown    frameState
+}
+
+// frameState is what a tile shows: frame a blended towards frame b by mix.
+type frameState struct {
+	a, b int
+	mix  float32
+}
+
+// newAnimated cuts strip into frames, checking them against the animation.
+func newAnimated(strip *image.RGBA, anim *Animation) (*animated, error) {
+	size := strip.Bounds().Size()
+	w, h := anim.Width, anim.Height
+	if w == 0 {
+		w = size.X
+	}
+	if h == 0 {
+		h = size.X
+	}
+	if w != size.X || h <= 0 || size.Y%h != 0 {
+		return nil, fmt.Errorf("%v texture doesn't split into %dx%d frames", size, w, h)
+	}
+	an := &animated{blend: anim.Interpolate, scratch: image.NewRGBA(image.Rect(0, 0, w, h))}
+	for y := 0; y < size.Y; y += h {
+		an.frames = append(an.frames, strip.SubImage(image.Rect(0, y, w, y+h)).(*image.RGBA))
+	}
+	an.sequence = anim.Frames
+	if len(an.sequence) == 0 {
+		for i := range an.frames {
+			an.sequence = append(an.sequence, Frame{Index: i})
+		}
+	}
+	frameTime := max(anim.FrameTime, 1)
+	for i, f := range an.sequence {
+		if f.Index < 0 || f.Index >= len(an.frames) {
+			return nil, fmt.Errorf("frame %d is out of range, there are %d", f.Index, len(an.frames))
+		}
+		if f.Time <= 0 {
+			an.sequence[i].Time = frameTime
+		}
+		an.period += an.sequence[i].Time
+	}
+	return an, nil
+}
+
+// at is what the animation shows at tick.
+func (an *animated) at(tick int) frameState {
+	t := tick % an.period
+	for i, f := range an.sequence {
+		if t >= f.Time {
+			t -= f.Time
+			continue
+		}
+		s := frameState{a: f.Index, b: f.Index}
+		if an.blend {
+			s.b = an.sequence[(i+1)%len(an.sequence)].Index
+			s.mix = float32(t) / float32(f.Time)
+		}
+		return s
+	}
+	panic("unreachable")
+}
+
+// image draws s.
+func (an *animated) image(s frameState) *image.RGBA {
+	if s.mix == 0 {
+		return an.frames[s.a]
+	}
+	a, b := an.frames[s.a], an.frames[s.b]
+	for i := range an.scratch.Pix {
+		pa := a.Pix[a.PixOffset(a.Rect.Min.X, a.Rect.Min.Y)+i/an.scratch.Stride*a.Stride+i%an.scratch.Stride]
+		pb := b.Pix[b.PixOffset(b.Rect.Min.X, b.Rect.Min.Y)+i/an.scratch.Stride*b.Stride+i%an.scratch.Stride]
+		an.scratch.Pix[i] = uint8(float32(pa)*(1-s.mix) + float32(pb)*s.mix + 0.5)
+	}
+	return an.scratch
+}
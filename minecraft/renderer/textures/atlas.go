@@ -0,0 +1,276 @@
+package textures
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"io/fs"
+	"maps"
+	"math"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/dragon1672/go-mine/minecraft/renderer/mesher"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// Atlas is many block textures packed into one image so a chunk draws with a single bind.
+// Each tile is surrounded by padding copied from its edges so filtering near a tile's
+// border doesn't pick up its neighbours.
+type Atlas struct {
+	Image    *image.RGBA
+	tiles    map[string]image.Rectangle
+	uvs      map[faceKey]mesher.UVRect
+	padding  int
+	animated []*animated
+	tick     int
+}
+
+// Missing is the tile drawn on faces without a texture, a checkerboard unless the
+// atlas is given one.
+const Missing = "missing"
+
+const missingSize = 16
+
+type faceKey struct {
+	b blocks.SimpleBlockType
+	f mesher.Face
+}
+
+// LoadAtlas packs every PNG at the top of fsys, use os.DirFS to load a directory. Tiles are
+// named after their file without the extension, see FaceNames for how faces find theirs.
+// A .png.mcmeta beside a texture animates it.
+func LoadAtlas(fsys fs.FS, padding int) (*Atlas, error) {
+	files, err := fs.Glob(fsys, "*.png")
+	if err != nil {
+		return nil, err
+	}
+	tiles := map[string]*image.RGBA{}
+	anims := map[string]*Animation{}
+	for _, file := range files {
+		img, anim, err := loadAnimated(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.ToLower(strings.TrimSuffix(file, path.Ext(file)))
+		tiles[name] = img
+		if anim != nil {
+			anims[name] = anim
+		}
+	}
+	return newAtlas(tiles, anims, padding)
+}
+
+// loadAnimated loads a texture and its animation, which is nil if it has no .png.mcmeta.
+func loadAnimated(fsys fs.FS, file string) (*image.RGBA, *Animation, error) {
+	img, err := LoadTexture(fsys, file)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := fs.ReadFile(fsys, file+".mcmeta")
+	if errors.Is(err, fs.ErrNotExist) {
+		return img, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	anim, err := ParseAnimation(b)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return img, anim, nil
+}
+
+// NewAtlas packs tiles by name into rows, largest first, in a power of two sized image.
+func NewAtlas(tiles map[string]*image.RGBA, padding int) (*Atlas, error) {
+	return newAtlas(tiles, nil, padding)
+}
+
+// newAtlas packs tiles, those with an animation are strips of frames and get a frame's
+// worth of space.
+func newAtlas(tiles map[string]*image.RGBA, anims map[string]*Animation, padding int) (*Atlas, error) {
+	if len(tiles) == 0 {
+		return nil, fmt.Errorf("no textures to pack")
+	}
+	if tiles[Missing] == nil {
+		tiles = maps.Clone(tiles)
+		tiles[Missing] = Checkerboard(missingSize)
+	}
+	animated := map[string]*animated{}
+	if len(anims) > 0 {
+		strips := tiles
+		tiles = map[string]*image.RGBA{}
+		for name, img := range strips {
+			tiles[name] = img
+			if anims[name] == nil {
+				continue
+			}
+			an, err := newAnimated(img, anims[name])
+			if err != nil {
+				return nil, fmt.Errorf("animating %s: %v", name, err)
+			}
+			animated[name] = an
+			tiles[name] = an.frames[0]
+		}
+	}
+	names := make([]string, 0, len(tiles))
+	area, widest := 0, 0
+	for name, img := range tiles {
+		names = append(names, name)
+		size := img.Bounds().Size().Add(image.Pt(2*padding, 2*padding))
+		area += size.X * size.Y
+		widest = max(widest, size.X)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		hi, hj := tiles[names[i]].Bounds().Dy(), tiles[names[j]].Bounds().Dy()
+		if hi != hj {
+			return hi > hj
+		}
+		return names[i] < names[j]
+	})
+
+	width := pow2(max(widest, int(math.Ceil(math.Sqrt(float64(area))))))
+	at := map[string]image.Point{}
+	x, y, rowHeight := 0, 0, 0
+	for _, name := range names {
+		size := tiles[name].Bounds().Size().Add(image.Pt(2*padding, 2*padding))
+		if x+size.X > width {
+			x, y, rowHeight = 0, y+rowHeight, 0
+		}
+		at[name] = image.Pt(x+padding, y+padding)
+		x += size.X
+		rowHeight = max(rowHeight, size.Y)
+	}
+
+	a := &Atlas{
+		Image:   image.NewRGBA(image.Rect(0, 0, width, pow2(y+rowHeight))),
+		tiles:   map[string]image.Rectangle{},
+		uvs:     map[faceKey]mesher.UVRect{},
+		padding: padding,
+	}
+	for _, name := range names {
+		img := tiles[name]
+		r := image.Rectangle{Min: at[name], Max: at[name].Add(img.Bounds().Size())}
+		a.tiles[name] = r
+		if an := animated[name]; an != nil {
+			an.rect = r
+			an.shown = an.at(0)
+			img = an.image(an.shown)
+			a.animated = append(a.animated, an)
+		}
+		a.blit(img, r, padding)
+	}
+	for _, b := range blocks.Types() {
+		for _, f := range mesher.Faces {
+			for _, name := range FaceNames(b, f) {
+				if r, ok := a.tiles[name]; ok {
+					a.uvs[faceKey{b, f}] = a.uv(r)
+					break
+				}
+			}
+		}
+	}
+	return a, nil
+}
+
+// Tick advances animations by one game tick, see Seek.
+func (a *Atlas) Tick() []image.Rectangle {
+	return a.Seek(a.tick + 1)
+}
+
+// Seek redraws animated tiles as they are tick ticks after the atlas was made. It returns
+// the rectangles of Image that changed, padding included, which need uploading again.
+func (a *Atlas) Seek(tick int) []image.Rectangle {
+	a.tick = tick
+	var changed []image.Rectangle
+	for _, an := range a.animated {
+		s := an.at(tick)
+		if s == an.shown {
+			continue
+		}
+		an.shown = s
+		a.blit(an.image(s), an.rect, a.padding)
+		changed = append(changed, an.rect.Inset(-a.padding))
+	}
+	return changed
+}
+
+// blit draws img into r, then fills padding pixels around it by stretching its edges out.
+func (a *Atlas) blit(img *image.RGBA, r image.Rectangle, padding int) {
+	src := img.Bounds()
+	for y := r.Min.Y - padding; y < r.Max.Y+padding; y++ {
+		sy := src.Min.Y + min(max(y-r.Min.Y, 0), src.Dy()-1)
+		for x := r.Min.X - padding; x < r.Max.X+padding; x++ {
+			sx := src.Min.X + min(max(x-r.Min.X, 0), src.Dx()-1)
+			a.Image.SetRGBA(x, y, img.RGBAAt(sx, sy))
+		}
+	}
+}
+
+func (a *Atlas) uv(r image.Rectangle) mesher.UVRect {
+	w, h := float32(a.Image.Rect.Dx()), float32(a.Image.Rect.Dy())
+	return mesher.UVRect{
+		U0: float32(r.Min.X) / w, V0: float32(r.Min.Y) / h,
+		U1: float32(r.Max.X) / w, V1: float32(r.Max.Y) / h,
+	}
+}
+
+// Tile is where the named texture is in Image, without its padding.
+func (a *Atlas) Tile(name string) (image.Rectangle, bool) {
+	r, ok := a.tiles[name]
+	return r, ok
+}
+
+// Lookup finds the texture for face f of b, ok is false if it has none.
+func (a *Atlas) Lookup(b blocks.SimpleBlockType, f mesher.Face) (uv mesher.UVRect, ok bool) {
+	uv, ok = a.uvs[faceKey{b, f}]
+	return uv, ok
+}
+
+// UV implements mesher.Textures, faces without a texture get the Missing tile.
+func (a *Atlas) UV(b blocks.SimpleBlockType, f mesher.Face) mesher.UVRect {
+	if uv, ok := a.Lookup(b, f); ok {
+		return uv
+	}
+	return a.uv(a.tiles[Missing])
+}
+
+// Named implements mesher.Textures, finding a tile by name.
+func (a *Atlas) Named(name string) (mesher.UVRect, bool) {
+	r, ok := a.tiles[strings.ToLower(name)]
+	if !ok {
+		return mesher.UVRect{}, false
+	}
+	return a.uv(r), true
+}
+
+// WritePNG dumps the atlas, which is handy for checking the packing by eye.
+func (a *Atlas) WritePNG(w io.Writer) error {
+	return png.Encode(w, a.Image)
+}
+
+// FaceNames is the texture names tried for face f of b, most specific first: the face
+// itself as in "grass_north", then "grass_top", "grass_bottom" or "grass_side", then "grass".
+func FaceNames(b blocks.SimpleBlockType, f mesher.Face) []string {
+	name := strings.ToLower(b.String())
+	general := "side"
+	switch f {
+	case mesher.Up:
+		general = "top"
+	case mesher.Down:
+		general = "bottom"
+	}
+	return []string{name + "_" + f.String(), name + "_" + general, name}
+}
+
+// pow2 is the smallest power of two at least n.
+func pow2(n int) int {
+	p := 1
+	for p < n {
+		p *= 2
+	}
+	return p
+}
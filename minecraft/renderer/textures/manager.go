@@ -0,0 +1,176 @@
+package textures
+
+import (
+	"image"
+	"io/fs"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// Uploader moves images to the GPU. GLUploader does it for real, tests fake it.
+type Uploader interface {
+	Upload(img *image.RGBA) uint32
+	// Update replaces the image of a texture Upload made, keeping its id.
+	Update(id uint32, img *image.RGBA)
+	Delete(id uint32)
+}
+
+// Manager shares textures by name, decoding each once and keeping one GPU copy for as
+// long as anything holds it. Textures are textures/<name>.png in its files.
+type Manager struct {
+	fsys     fs.FS
+	uploader Uploader
+	// HotReload is how often Draw looks for changed files, 0 never does. It's meant for
+	// working on textures with the game running.
+	HotReload time.Duration
+
+	mu         sync.Mutex
+	entries    map[string]*entry
+	lastReload time.Time
+}
+
+type entry struct {
+	img *image.RGBA
+	// stamp is the file as it was loaded, to notice it changing.
+	stamp stamp
+	id    uint32
+	refs  int
+	// upload counts uploads so holds from before a Cleanup can't release a later upload.
+	upload int
+}
+
+type stamp struct {
+	found bool
+	mod   time.Time
+	size  int64
+}
+
+func NewManager(fsys fs.FS, uploader Uploader) *Manager {
+	return &Manager{fsys: fsys, uploader: uploader, entries: map[string]*entry{}}
+}
+
+func textureFile(name string) string {
+	return "textures/" + name + ".png"
+}
+
+func (m *Manager) stat(name string) stamp {
+	info, err := fs.Stat(m.fsys, textureFile(name))
+	if err != nil {
+		return stamp{}
+	}
+	return stamp{found: true, mod: info.ModTime(), size: info.Size()}
+}
+
+// load decodes a texture, standing in a checkerboard if it's missing or broken. The stamp
+// is the file's even if it's broken, so it's only tried again once the file changes.
+func (m *Manager) load(name string) (*image.RGBA, stamp) {
+	s := m.stat(name)
+	return TextureOrPlaceholder(m.fsys, name), s
+}
+
+// entry finds or loads a texture, m.mu must be held.
+func (m *Manager) entry(name string) *entry {
+	e, ok := m.entries[name]
+	if !ok {
+		e = &entry{}
+		e.img, e.stamp = m.load(name)
+		m.entries[name] = e
+	}
+	return e
+}
+
+// Image is a texture's decoded pixels, which must not be changed.
+func (m *Manager) Image(name string) *image.RGBA {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.entry(name).img
+}
+
+// Texture is a hold on a texture on the GPU.
+type Texture struct {
+	ID      uint32
+	name    string
+	upload  int
+	m       *Manager
+	release sync.Once
+}
+
+// Acquire holds a texture, uploading it if nothing else has. It must be called on the
+// GL thread.
+func (m *Manager) Acquire(name string) *Texture {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.entry(name)
+	if e.refs == 0 {
+		e.id = m.uploader.Upload(e.img)
+		e.upload++
+	}
+	e.refs++
+	return &Texture{ID: e.id, name: name, upload: e.upload, m: m}
+}
+
+// Release lets go of the texture, it's deleted from the GPU when the last hold goes.
+// Releasing twice does nothing.
+func (t *Texture) Release() {
+	t.release.Do(func() {
+		m := t.m
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		e := m.entries[t.name]
+		// Cleanup has already deleted it
+		if e.upload != t.upload || e.refs == 0 {
+			return
+		}
+		if e.refs--; e.refs == 0 {
+			m.uploader.Delete(e.id)
+			e.id = 0
+		}
+	})
+}
+
+// Reload decodes textures whose files have changed since they were loaded and updates
+// those on the GPU in place, so holders keep working. It returns the names reloaded.
+func (m *Manager) Reload() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var reloaded []string
+	for name, e := range m.entries {
+		if m.stat(name) == e.stamp {
+			continue
+		}
+		e.img, e.stamp = m.load(name)
+		if e.refs > 0 {
+			m.uploader.Update(e.id, e.img)
+		}
+		reloaded = append(reloaded, name)
+	}
+	sort.Strings(reloaded)
+	return reloaded
+}
+
+// Draw lets the manager sit in the render loop, reloading every HotReload.
+func (m *Manager) Draw(t time.Time, dt time.Duration) error {
+	if m.HotReload <= 0 || t.Sub(m.lastReload) < m.HotReload {
+		return nil
+	}
+	m.lastReload = t
+	for _, name := range m.Reload() {
+		glog.Infof("reloaded texture %s", name)
+	}
+	return nil
+}
+
+// Cleanup deletes every texture still on the GPU.
+func (m *Manager) Cleanup() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.entries {
+		if e.refs > 0 {
+			m.uploader.Delete(e.id)
+			e.refs, e.id = 0, 0
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package textures
+
+import "image"
+
+// Mipmaps makes a mipmap chain starting with img, each level half the size of the one
+// before by averaging 2x2 boxes, down to 1x1 or until there are levels levels if levels
+// is above 0.
+//
+// regions keeps parts of img apart, like the tiles of an atlas: each box only averages
+// pixels from the region its top left pixel is in, so tiles never blur into each other
+// however they're laid out. nil treats img as one picture.
+func Mipmaps(img *image.RGBA, regions []image.Rectangle, levels int) []*image.RGBA {
+	img = toRGBA(img)
+	size := img.Rect.Size()
+	// region numbers each pixel, 0 for outside every region
+	region := make([]int, size.X*size.Y)
+	for i, r := range regions {
+		r = r.Intersect(img.Rect)
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				region[y*size.X+x] = i + 1
+			}
+		}
+	}
+
+	chain := []*image.RGBA{img}
+	for (levels <= 0 || len(chain) < levels) && (size.X > 1 || size.Y > 1) {
+		src, srcRegion, srcSize := chain[len(chain)-1], region, size
+		size = image.Pt(max(size.X/2, 1), max(size.Y/2, 1))
+		dst := image.NewRGBA(image.Rectangle{Max: size})
+		region = make([]int, size.X*size.Y)
+		for y := 0; y < size.Y; y++ {
+			for x := 0; x < size.X; x++ {
+				x0, y0 := x*srcSize.X/size.X, y*srcSize.Y/size.Y
+				own := srcRegion[y0*srcSize.X+x0]
+				var sum [4]int
+				n := 0
+				for sy := y0; sy < min(y0+2, srcSize.Y); sy++ {
+					for sx := x0; sx < min(x0+2, srcSize.X); sx++ {
+						if srcRegion[sy*srcSize.X+sx] != own {
+							continue
+						}
+						p := src.Pix[src.PixOffset(sx, sy):]
+						for c := range sum {
+							sum[c] += int(p[c])
+						}
+						n++
+					}
+				}
+				p := dst.Pix[dst.PixOffset(x, y):]
+				for c := range sum {
+					p[c] = uint8((sum[c] + n/2) / n)
+				}
+				region[y*size.X+x] = own
+			}
+		}
+		chain = append(chain, dst)
+	}
+	return chain
+}
+
+// Mipmaps makes the atlas's mipmap chain keeping each tile, padding included, apart.
+func (a *Atlas) Mipmaps(levels int) []*image.RGBA {
+	regions := make([]image.Rectangle, 0, len(a.tiles))
+	for _, r := range a.tiles {
+		regions = append(regions, r.Inset(-a.padding))
+	}
+	return Mipmaps(a.Image, regions, levels)
+}
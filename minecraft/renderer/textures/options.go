@@ -0,0 +1,91 @@
+package textures
+
+import (
+	"image"
+
+	"github.com/go-gl/gl/v2.1/gl"
+)
+
+// Filter is how a texture is sampled when drawn smaller or larger than it is.
+type Filter int32
+
+const (
+	Nearest Filter = gl.NEAREST
+	Linear  Filter = gl.LINEAR
+	// The mipmap filters only work as MinFilter, with Mipmaps on. The first word is how
+	// each level is sampled, the second whether neighbouring levels are blended.
+	NearestMipmapNearest Filter = gl.NEAREST_MIPMAP_NEAREST
+	NearestMipmapLinear  Filter = gl.NEAREST_MIPMAP_LINEAR
+	LinearMipmapNearest  Filter = gl.LINEAR_MIPMAP_NEAREST
+	LinearMipmapLinear   Filter = gl.LINEAR_MIPMAP_LINEAR
+)
+
+func (f Filter) mipmapped() bool {
+	switch f {
+	case NearestMipmapNearest, NearestMipmapLinear, LinearMipmapNearest, LinearMipmapLinear:
+		return true
+	}
+	return false
+}
+
+// Wrap is what's sampled past a texture's edges.
+type Wrap int32
+
+const (
+	ClampToEdge    Wrap = gl.CLAMP_TO_EDGE
+	Repeat         Wrap = gl.REPEAT
+	MirroredRepeat Wrap = gl.MIRRORED_REPEAT
+)
+
+// UploadOptions says how a texture is sampled. The zero value is linear filtering clamped
+// to the edges without mipmaps.
+type UploadOptions struct {
+	MinFilter, MagFilter Filter
+	WrapS, WrapT         Wrap
+	// Mipmaps uploads a chain made by Mipmaps, limited to MipLevels levels if that's above 0.
+	Mipmaps   bool
+	MipLevels int
+	// Anisotropy sharpens textures seen at an angle, up to this many samples. It's left
+	// off if the driver can't do it, and capped at what it can.
+	Anisotropy float32
+}
+
+// PixelArt keeps block textures crisp up close and stops them shimmering far away.
+var PixelArt = UploadOptions{
+	MinFilter:  NearestMipmapLinear,
+	MagFilter:  Nearest,
+	WrapS:      Repeat,
+	WrapT:      Repeat,
+	Mipmaps:    true,
+	MipLevels:  5,
+	Anisotropy: 4,
+}
+
+// withDefaults fills in unset fields, and drops mipmap filtering without mipmaps as GL
+// wouldn't draw the texture at all.
+func (o UploadOptions) withDefaults() UploadOptions {
+	if o.MinFilter == 0 {
+		o.MinFilter = Linear
+	}
+	if o.MagFilter == 0 || o.MagFilter.mipmapped() {
+		o.MagFilter = Linear
+	}
+	if o.WrapS == 0 {
+		o.WrapS = ClampToEdge
+	}
+	if o.WrapT == 0 {
+		o.WrapT = ClampToEdge
+	}
+	if !o.Mipmaps && o.MinFilter.mipmapped() {
+		o.MinFilter = Linear
+	}
+	return o
+}
+
+// levels is what to upload for img.
+func (o UploadOptions) levels(img *image.RGBA) []*image.RGBA {
+	if !o.Mipmaps {
+		return []*image.RGBA{img}
+	}
+	return Mipmaps(img, nil, o.MipLevels)
+}
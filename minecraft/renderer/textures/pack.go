@@ -0,0 +1,235 @@
+package textures
+
+import (
+	"archive/zip"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dragon1672/go-mine/minecraft/renderer/mesher"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+//go:embed builtin
+var builtin embed.FS
+
+// Builtin is the textures that ship with the game. Every Stack falls back to it.
+var Builtin = func() *Pack {
+	p, err := NewPack("builtin", builtin)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}()
+
+// Defaults is the same built in files as a Manager reads them, textures/<name>.png with
+// the block textures under block/.
+var Defaults = func() fs.FS {
+	sub, err := fs.Sub(builtin, "builtin/assets/minecraft")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}()
+
+// Pack is a Minecraft style resource pack: pack.mcmeta plus textures under
+// assets/<namespace>/textures/block.
+type Pack struct {
+	Name        string
+	Format      int
+	Description string
+	fsys        fs.FS
+	closer      io.Closer
+}
+
+// OpenPack opens a pack from a zip file or a directory. Zips whose files are all inside
+// one folder, as made by zipping the pack's folder, work too.
+func OpenPack(file string) (*Pack, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSuffix(filepath.Base(file), ".zip")
+	if info.IsDir() {
+		return NewPack(name, os.DirFS(file))
+	}
+	z, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("opening pack %s: %v", file, err)
+	}
+	p, err := NewPack(name, z)
+	if err != nil {
+		z.Close()
+		return nil, err
+	}
+	p.closer = z
+	return p, nil
+}
+
+// NewPack reads a pack out of fsys, which must hold pack.mcmeta at its top or in its
+// only folder.
+func NewPack(name string, fsys fs.FS) (*Pack, error) {
+	fsys, err := packRoot(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("pack %s: %v", name, err)
+	}
+	b, err := fs.ReadFile(fsys, "pack.mcmeta")
+	if err != nil {
+		return nil, fmt.Errorf("pack %s: %v", name, err)
+	}
+	var meta struct {
+		Pack struct {
+			Format      int             `json:"pack_format"`
+			Description json.RawMessage `json:"description"`
+		} `json:"pack"`
+	}
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return nil, fmt.Errorf("pack %s: bad pack.mcmeta: %v", name, err)
+	}
+	p := &Pack{Name: name, Format: meta.Pack.Format, fsys: fsys}
+	// the description is usually a string but may be a chat component, kept as JSON
+	if err := json.Unmarshal(meta.Pack.Description, &p.Description); err != nil {
+		p.Description = string(meta.Pack.Description)
+	}
+	return p, nil
+}
+
+func packRoot(fsys fs.FS) (fs.FS, error) {
+	if _, err := fs.Stat(fsys, "pack.mcmeta"); err == nil {
+		return fsys, nil
+	}
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		if _, err := fs.Stat(fsys, entries[0].Name()+"/pack.mcmeta"); err == nil {
+			return fs.Sub(fsys, entries[0].Name())
+		}
+	}
+	return nil, fmt.Errorf("no pack.mcmeta")
+}
+
+// Close releases the zip file, if the pack is one.
+func (p *Pack) Close() error {
+	if p.closer == nil {
+		return nil
+	}
+	return p.closer.Close()
+}
+
+// FS is the pack's files.
+func (p *Pack) FS() fs.FS {
+	return p.fsys
+}
+
+// Texture loads a block texture by resource location, as in "minecraft:stone" or just
+// "stone", with its animation if it has one. The error wraps fs.ErrNotExist if the pack
+// doesn't have it.
+func (p *Pack) Texture(id string) (*image.RGBA, *Animation, error) {
+	ns, name, ok := strings.Cut(id, ":")
+	if !ok {
+		ns, name = "minecraft", id
+	}
+	file := "assets/" + ns + "/textures/block/" + name + ".png"
+	if _, err := fs.Stat(p.fsys, file); err != nil {
+		return nil, nil, fmt.Errorf("pack %s: %w", p.Name, err)
+	}
+	return loadAnimated(p.fsys, file)
+}
+
+// minecraftNames are the vanilla textures standing in for our tile names, so packs made
+// for Minecraft cover our blocks.
+var minecraftNames = map[string][]string{
+	"grass_top":    {"grass_block_top"},
+	"grass_side":   {"grass_block_side"},
+	"grass_bottom": {"dirt"},
+	"leaves":       {"oak_leaves"},
+	"wood_top":     {"oak_log_top"},
+	"wood_bottom":  {"oak_log_top"},
+	"wood_side":    {"oak_log"},
+	"flower":       {"poppy"},
+	"water":        {"water_still"},
+	"lava":         {"lava_still"},
+	"planks":       {"oak_planks"},
+}
+
+// Stack layers packs, earlier packs override later ones and Builtin comes after them all.
+type Stack []*Pack
+
+// packs is the stack with Builtin on the end.
+func (s Stack) packs() []*Pack {
+	return append(s[:len(s):len(s)], Builtin)
+}
+
+// Texture loads tile name, see FaceNames, from the first pack that has it under our name
+// or its vanilla one.
+func (s Stack) Texture(name string) (*image.RGBA, *Animation, error) {
+	for _, p := range s.packs() {
+		for _, id := range append([]string{name}, minecraftNames[name]...) {
+			img, anim, err := p.Texture(id)
+			if err == nil {
+				return img, anim, nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return nil, nil, err
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("texture %q: %w", name, fs.ErrNotExist)
+}
+
+// Tiles loads every texture any block face could use and their animations. Each comes
+// from the highest priority pack that has it, as Minecraft does.
+func (s Stack) Tiles() (tiles map[string]*image.RGBA, anims map[string]*Animation, err error) {
+	tiles, anims = map[string]*image.RGBA{}, map[string]*Animation{}
+	for _, b := range blocks.Types() {
+		if b == blocks.Air {
+			continue
+		}
+		for _, f := range mesher.Faces {
+			for _, name := range FaceNames(b, f) {
+				if _, ok := tiles[name]; ok {
+					continue
+				}
+				img, anim, err := s.Texture(name)
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				if err != nil {
+					return nil, nil, err
+				}
+				tiles[name] = img
+				if anim != nil {
+					anims[name] = anim
+				}
+			}
+		}
+	}
+	return tiles, anims, nil
+}
+
+// Atlas packs the stack's block textures.
+func (s Stack) Atlas(padding int) (*Atlas, error) {
+	tiles, anims, err := s.Tiles()
+	if err != nil {
+		return nil, err
+	}
+	return newAtlas(tiles, anims, padding)
+}
+
+// Close closes every pack.
+func (s Stack) Close() error {
+	var errs []error
+	for _, p := range s {
+		errs = append(errs, p.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -3,50 +3,140 @@ package textures
 import (
 	"fmt"
 	"image"
+	"image/color"
 	"image/draw"
 	_ "image/png"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/go-gl/gl/v2.1/gl"
+	"github.com/golang/glog"
 )
 
 func LoadTextureFile(file string) (*image.RGBA, error) {
-	imgFile, err := os.Open(file)
+	return LoadTexture(os.DirFS(filepath.Dir(file)), filepath.Base(file))
+}
+
+// LoadTexture reads a texture out of fsys, so zipped and embedded textures load like files.
+func LoadTexture(fsys fs.FS, file string) (*image.RGBA, error) {
+	f, err := fsys.Open(file)
 	if err != nil {
-		return nil, fmt.Errorf("texture %q not found on disk: %v\n", file, err)
+		return nil, fmt.Errorf("texture %q not found: %v", file, err)
 	}
-	img, _, err := image.Decode(imgFile)
+	defer f.Close()
+	img, _, err := image.Decode(f)
 	if err != nil {
-		return nil, fmt.Errorf("error decoding image: %v", err)
+		return nil, fmt.Errorf("error decoding image %q: %v", file, err)
+	}
+	return toRGBA(img), nil
+}
+
+// toRGBA converts img to RGBA with its origin at 0, 0.
+func toRGBA(img image.Image) *image.RGBA {
+	rgba := image.NewRGBA(image.Rectangle{Max: img.Bounds().Size()})
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	return rgba
+}
+
+// Checkerboard is Minecraft's missing texture, magenta and black squares a quarter of the
+// texture each.
+func Checkerboard(size int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	half := max(size/2, 1)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			c := color.RGBA{A: 255}
+			if (x/half+y/half)%2 == 0 {
+				c = color.RGBA{R: 248, B: 248, A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
 	}
+	return img
+}
 
-	rgba := image.NewRGBA(img.Bounds())
-	if rgba.Stride != rgba.Rect.Size().X*4 {
-		return nil, fmt.Errorf("unsupported stride %d, expected %d", rgba.Stride, rgba.Rect.Size().X*4)
+// TextureOrPlaceholder loads textures/<name>.png from fsys. Missing or broken textures are
+// logged and drawn as a Checkerboard so they're obvious without stopping the game.
+func TextureOrPlaceholder(fsys fs.FS, name string) *image.RGBA {
+	img, err := LoadTexture(fsys, textureFile(name))
+	if err != nil {
+		glog.Warningf("using a placeholder for texture %s: %v", name, err)
+		return Checkerboard(missingSize)
 	}
-	draw.Draw(rgba, rgba.Bounds(), img, image.Point{0, 0}, draw.Src)
-	return rgba, nil
+	return img
 }
 
+// LoadTextureToGPU uploads rgba with the default UploadOptions.
 func LoadTextureToGPU(rgba *image.RGBA) uint32 {
+	return UploadTexture(rgba, UploadOptions{})
+}
+
+// UploadTexture puts img on the GPU to be sampled as opts says.
+func UploadTexture(img *image.RGBA, opts UploadOptions) uint32 {
+	return UploadLevels(opts.levels(img), opts)
+}
+
+// UploadLevels uploads a mipmap chain made beforehand, like Atlas.Mipmaps, whatever
+// opts.Mipmaps says.
+func UploadLevels(levels []*image.RGBA, opts UploadOptions) uint32 {
 	var texture uint32
 	gl.Enable(gl.TEXTURE_2D)
 	gl.GenTextures(1, &texture)
+	specify(texture, levels, opts)
+	return texture
+}
+
+// specify sets texture's parameters and images.
+func specify(texture uint32, levels []*image.RGBA, opts UploadOptions) {
+	opts.Mipmaps = len(levels) > 1
+	opts = opts.withDefaults()
 	gl.BindTexture(gl.TEXTURE_2D, texture)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
-	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
-	gl.TexImage2D(
-		gl.TEXTURE_2D,
-		0,
-		gl.RGBA,
-		int32(rgba.Rect.Size().X),
-		int32(rgba.Rect.Size().Y),
-		0,
-		gl.RGBA,
-		gl.UNSIGNED_BYTE,
-		gl.Ptr(rgba.Pix))
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, int32(opts.MinFilter))
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, int32(opts.MagFilter))
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, int32(opts.WrapS))
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, int32(opts.WrapT))
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAX_LEVEL, int32(len(levels)-1))
+	if opts.Anisotropy > 1 && anisotropic() {
+		var most float32
+		gl.GetFloatv(gl.MAX_TEXTURE_MAX_ANISOTROPY, &most)
+		gl.TexParameterf(gl.TEXTURE_2D, gl.TEXTURE_MAX_ANISOTROPY, min(opts.Anisotropy, most))
+	}
+	for level, img := range levels {
+		gl.TexImage2D(
+			gl.TEXTURE_2D,
+			int32(level),
+			gl.RGBA,
+			int32(img.Rect.Size().X),
+			int32(img.Rect.Size().Y),
+			0,
+			gl.RGBA,
+			gl.UNSIGNED_BYTE,
+			gl.Ptr(img.Pix))
+	}
+}
 
-	return texture
+// anisotropic reports whether the driver can filter anisotropically, which is an
+// extension before GL 4.6.
+var anisotropic = sync.OnceValue(func() bool {
+	return strings.Contains(gl.GoStr(gl.GetString(gl.EXTENSIONS)), "texture_filter_anisotropic")
+})
+
+// GLUploader puts textures on the GPU for a Manager.
+type GLUploader struct {
+	Options UploadOptions
+}
+
+func (u GLUploader) Upload(img *image.RGBA) uint32 {
+	return UploadTexture(img, u.Options)
+}
+
+func (u GLUploader) Update(id uint32, img *image.RGBA) {
+	specify(id, u.Options.levels(img), u.Options)
+}
+
+func (GLUploader) Delete(id uint32) {
+	gl.DeleteTextures(1, &id)
 }
@@ -0,0 +1,602 @@
+package textures
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/dragon1672/go-mine/minecraft/renderer/mesher"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// solid is a size by size texture of c with a white top left pixel to check orientation.
+func solid(size int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	img.SetRGBA(0, 0, color.RGBA{255, 255, 255, 255})
+	return img
+}
+
+func encode(t *testing.T, img image.Image) *fstest.MapFile {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return &fstest.MapFile{Data: buf.Bytes()}
+}
+
+var (
+	red   = color.RGBA{255, 0, 0, 255}
+	green = color.RGBA{0, 255, 0, 255}
+	blue  = color.RGBA{0, 0, 255, 255}
+	grey  = color.RGBA{128, 128, 128, 255}
+)
+
+func TestAtlas(t *testing.T) {
+	t.Parallel()
+	fsys := fstest.MapFS{
+		"stone.png":      encode(t, solid(16, grey)),
+		"grass_top.png":  encode(t, solid(16, green)),
+		"grass_side.png": encode(t, solid(16, red)),
+		"Sand.png":       encode(t, solid(8, blue)),
+		"readme.txt":     {Data: []byte("not a texture")},
+	}
+	const padding = 2
+	a, err := LoadAtlas(fsys, padding)
+	if err != nil {
+		t.Fatalf("LoadAtlas() = %v", err)
+	}
+	if b := a.Image.Bounds(); b.Dx()&(b.Dx()-1) != 0 || b.Dy()&(b.Dy()-1) != 0 {
+		t.Errorf("atlas is %v, want power of two sides", b.Size())
+	}
+
+	t.Run("Packing", func(t *testing.T) {
+		var placed []image.Rectangle
+		for _, name := range []string{"stone", "grass_top", "grass_side", "sand"} {
+			r, ok := a.Tile(name)
+			if !ok {
+				t.Fatalf("no tile %q", name)
+			}
+			padded := r.Inset(-padding)
+			if !padded.In(a.Image.Bounds()) {
+				t.Errorf("%s at %v is outside the atlas", name, padded)
+			}
+			for _, o := range placed {
+				if padded.Overlaps(o) {
+					t.Errorf("%s at %v overlaps %v", name, padded, o)
+				}
+			}
+			placed = append(placed, padded)
+		}
+	})
+
+	t.Run("Faces", func(t *testing.T) {
+		for _, tc := range []struct {
+			b    blocks.SimpleBlockType
+			f    mesher.Face
+			want color.RGBA
+		}{
+			{blocks.Grass, mesher.Up, green},
+			{blocks.Grass, mesher.North, red},
+			{blocks.Stone, mesher.Down, grey},
+			{blocks.Sand, mesher.East, blue},
+		} {
+			uv, ok := a.Lookup(tc.b, tc.f)
+			if !ok {
+				t.Errorf("no texture for %v %v", tc.b, tc.f)
+				continue
+			}
+			w, h := float32(a.Image.Bounds().Dx()), float32(a.Image.Bounds().Dy())
+			if got := a.Image.RGBAAt(int(uv.U0*w), int(uv.V0*h)); got != (color.RGBA{255, 255, 255, 255}) {
+				t.Errorf("%v %v top left is %v, want white", tc.b, tc.f, got)
+			}
+			if got := a.Image.RGBAAt(int(uv.U1*w)-1, int(uv.V1*h)-1); got != tc.want {
+				t.Errorf("%v %v is %v, want %v", tc.b, tc.f, got, tc.want)
+			}
+		}
+		if _, ok := a.Lookup(blocks.Grass, mesher.Down); ok {
+			t.Errorf("grass has no bottom texture but Lookup() found one")
+		}
+		missing, _ := a.Named(Missing)
+		if uv := a.UV(blocks.Grass, mesher.Down); uv != missing {
+			t.Errorf("grass bottom is drawn with %v, want the missing texture at %v", uv, missing)
+		}
+		w, h := float32(a.Image.Bounds().Dx()), float32(a.Image.Bounds().Dy())
+		if got := a.Image.RGBAAt(int(missing.U0*w), int(missing.V0*h)); got != (color.RGBA{248, 0, 248, 255}) {
+			t.Errorf("missing texture starts %v, want magenta", got)
+		}
+	})
+
+	t.Run("Bleeding", func(t *testing.T) {
+		r, _ := a.Tile("stone")
+		for d := 1; d <= padding; d++ {
+			for _, p := range []struct{ in, out image.Point }{
+				{r.Min, r.Min.Sub(image.Pt(d, d))},
+				{image.Pt(r.Max.X-1, r.Min.Y+5), image.Pt(r.Max.X-1+d, r.Min.Y+5)},
+				{image.Pt(r.Min.X+5, r.Max.Y-1), image.Pt(r.Min.X+5, r.Max.Y-1+d)},
+			} {
+				if in, out := a.Image.RGBAAt(p.in.X, p.in.Y), a.Image.RGBAAt(p.out.X, p.out.Y); in != out {
+					t.Errorf("padding at %v is %v, want %v from the edge at %v", p.out, out, in, p.in)
+				}
+			}
+		}
+	})
+
+	t.Run("PNG", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := a.WritePNG(&buf); err != nil {
+			t.Fatalf("WritePNG() = %v", err)
+		}
+		img, err := png.Decode(&buf)
+		if err != nil || img.Bounds() != a.Image.Bounds() {
+			t.Errorf("dumped atlas decoded as %v, %v", img.Bounds(), err)
+		}
+	})
+}
+
+func TestAtlasErrors(t *testing.T) {
+	t.Parallel()
+	if _, err := LoadAtlas(fstest.MapFS{}, 1); err == nil {
+		t.Errorf("LoadAtlas() of nothing should fail")
+	}
+	if _, err := LoadAtlas(fstest.MapFS{"stone.png": {Data: []byte("junk")}}, 1); err == nil {
+		t.Errorf("LoadAtlas() of a broken PNG should fail")
+	}
+}
+
+const mcmeta = `{"pack": {"pack_format": 15, "description": "Test pack"}}`
+
+// pack makes a resource pack holding block textures by name.
+func pack(t *testing.T, prefix string, textures map[string]*image.RGBA) fstest.MapFS {
+	fsys := fstest.MapFS{prefix + "pack.mcmeta": {Data: []byte(mcmeta)}}
+	for name, img := range textures {
+		fsys[prefix+"assets/minecraft/textures/block/"+name+".png"] = encode(t, img)
+	}
+	return fsys
+}
+
+func TestPack(t *testing.T) {
+	t.Parallel()
+	t.Run("Metadata", func(t *testing.T) {
+		p, err := NewPack("test", pack(t, "", nil))
+		if err != nil {
+			t.Fatalf("NewPack() = %v", err)
+		}
+		if p.Format != 15 || p.Description != "Test pack" {
+			t.Errorf("got format %d, description %q", p.Format, p.Description)
+		}
+		component := fstest.MapFS{"pack.mcmeta": {Data: []byte(`{"pack": {"pack_format": 1, "description": {"text": "hi"}}}`)}}
+		if p, err := NewPack("test", component); err != nil || p.Description != `{"text": "hi"}` {
+			t.Errorf("chat component description came out as %q, %v", p.Description, err)
+		}
+	})
+	t.Run("Not a pack", func(t *testing.T) {
+		if _, err := NewPack("test", fstest.MapFS{"stone.png": encode(t, solid(16, grey))}); err == nil {
+			t.Errorf("NewPack() without pack.mcmeta should fail")
+		}
+	})
+	t.Run("Nested folder", func(t *testing.T) {
+		p, err := NewPack("test", pack(t, "My Pack/", map[string]*image.RGBA{"stone": solid(16, grey)}))
+		if err != nil {
+			t.Fatalf("NewPack() = %v", err)
+		}
+		if _, _, err := p.Texture("minecraft:stone"); err != nil {
+			t.Errorf("Texture() = %v", err)
+		}
+	})
+	t.Run("Zip", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "pack.zip")
+		f, err := os.Create(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		z := zip.NewWriter(f)
+		for name, data := range pack(t, "", map[string]*image.RGBA{"stone": solid(16, grey)}) {
+			w, err := z.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			w.Write(data.Data)
+		}
+		if err := z.Close(); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+		p, err := OpenPack(file)
+		if err != nil {
+			t.Fatalf("OpenPack() = %v", err)
+		}
+		defer p.Close()
+		if p.Name != "pack" {
+			t.Errorf("pack is named %q, want pack", p.Name)
+		}
+		if img, _, err := p.Texture("stone"); err != nil || img.RGBAAt(1, 1) != grey {
+			t.Errorf("Texture() = %v", err)
+		}
+		if _, _, err := p.Texture("dirt"); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Texture() of a missing texture = %v, want not exist", err)
+		}
+	})
+}
+
+func TestStack(t *testing.T) {
+	t.Parallel()
+	open := func(textures map[string]*image.RGBA) *Pack {
+		p, err := NewPack("test", pack(t, "", textures))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	// a vanilla style pack over one using our names, over Builtin
+	s := Stack{
+		open(map[string]*image.RGBA{"grass_block_top": solid(16, green), "stone": solid(16, blue)}),
+		open(map[string]*image.RGBA{"grass_top": solid(16, red), "stone": solid(16, grey), "dirt": solid(16, red)}),
+	}
+	a, err := s.Atlas(1)
+	if err != nil {
+		t.Fatalf("Atlas() = %v", err)
+	}
+	for _, tc := range []struct {
+		b    blocks.SimpleBlockType
+		f    mesher.Face
+		want color.RGBA
+	}{
+		{blocks.Grass, mesher.Up, green},
+		{blocks.Stone, mesher.North, blue},
+		// falls back to the second pack
+		{blocks.Dirt, mesher.Up, red},
+		// and to the built in textures
+		{blocks.Lava, mesher.Up, builtinPixel(t, "lava")},
+	} {
+		uv, ok := a.Lookup(tc.b, tc.f)
+		if !ok {
+			t.Errorf("no texture for %v %v", tc.b, tc.f)
+			continue
+		}
+		x, y := int(uv.U1*float32(a.Image.Rect.Dx()))-1, int(uv.V1*float32(a.Image.Rect.Dy()))-1
+		if got := a.Image.RGBAAt(x, y); got != tc.want {
+			t.Errorf("%v %v is %v, want %v", tc.b, tc.f, got, tc.want)
+		}
+	}
+
+	// the built in textures alone cover every face
+	a, err = Stack{}.Atlas(1)
+	if err != nil {
+		t.Fatalf("Atlas() = %v", err)
+	}
+	for _, b := range blocks.Types()[1:] {
+		for _, f := range mesher.Faces {
+			if _, ok := a.Lookup(b, f); !ok {
+				t.Errorf("no built in texture for %v %v", b, f)
+			}
+		}
+	}
+}
+
+// builtinPixel is the bottom right pixel of a built in texture.
+func builtinPixel(t *testing.T, name string) color.RGBA {
+	t.Helper()
+	img, _, err := Builtin.Texture(name)
+	if err != nil {
+		t.Fatalf("Builtin.Texture(%s) = %v", name, err)
+	}
+	return img.RGBAAt(img.Rect.Max.X-1, img.Rect.Max.Y-1)
+}
+
+// strip stacks size by size frames of each colour.
+func strip(size int, colors ...color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size*len(colors)))
+	for i, c := range colors {
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				img.SetRGBA(x, i*size+y, c)
+			}
+		}
+	}
+	return img
+}
+
+func TestAnimation(t *testing.T) {
+	t.Parallel()
+	const padding = 1
+	t.Run("Frames", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"stone.png":        encode(t, solid(4, grey)),
+			"lava.png":         encode(t, strip(4, red, green, blue)),
+			"lava.png.mcmeta":  {Data: []byte(`{"animation": {"frametime": 2, "frames": [0, {"index": 2, "time": 3}, 1]}}`)},
+			"stone.png.mcmeta": {Data: []byte(`{"villager": "not an animation"}`)},
+		}
+		a, err := LoadAtlas(fsys, padding)
+		if err != nil {
+			t.Fatalf("LoadAtlas() = %v", err)
+		}
+		r, _ := a.Tile("lava")
+		if r.Dx() != 4 || r.Dy() != 4 {
+			t.Fatalf("lava tile is %v, want one 4x4 frame", r)
+		}
+		// red for 2 ticks, blue for 3, green for 2, then round again
+		for tick, want := range []color.RGBA{red, red, blue, blue, blue, green, green, red, red, blue} {
+			changed := a.Seek(tick)
+			if got := a.Image.RGBAAt(r.Min.X+1, r.Min.Y+1); got != want {
+				t.Errorf("tick %d shows %v, want %v", tick, got, want)
+			}
+			if got := a.Image.RGBAAt(r.Min.X-padding, r.Max.Y); got != want {
+				t.Errorf("tick %d padding is %v, want %v", tick, got, want)
+			}
+			if flip := tick == 2 || tick == 5 || tick == 7 || tick == 9; flip != (len(changed) == 1) {
+				t.Errorf("tick %d changed %v", tick, changed)
+			} else if flip && changed[0] != r.Inset(-padding) {
+				t.Errorf("tick %d changed %v, want %v", tick, changed[0], r.Inset(-padding))
+			}
+		}
+	})
+	t.Run("Interpolate", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"water.png":        encode(t, strip(4, red, blue)),
+			"water.png.mcmeta": {Data: []byte(`{"animation": {"frametime": 4, "interpolate": true}}`)},
+		}
+		a, err := LoadAtlas(fsys, padding)
+		if err != nil {
+			t.Fatalf("LoadAtlas() = %v", err)
+		}
+		r, _ := a.Tile("water")
+		for tick, want := range []color.RGBA{red, {191, 0, 64, 255}, {128, 0, 128, 255}, {64, 0, 191, 255}, blue, {64, 0, 191, 255}} {
+			// blending changes the tile every tick
+			if changed := a.Seek(tick); tick > 0 && len(changed) != 1 {
+				t.Errorf("tick %d changed %v, want the water tile", tick, changed)
+			}
+			if got := a.Image.RGBAAt(r.Min.X, r.Min.Y); got != want {
+				t.Errorf("tick %d shows %v, want %v", tick, got, want)
+			}
+		}
+	})
+	t.Run("Pack", func(t *testing.T) {
+		fsys := pack(t, "", map[string]*image.RGBA{"water_still": strip(4, red, blue)})
+		fsys["assets/minecraft/textures/block/water_still.png.mcmeta"] = &fstest.MapFile{Data: []byte(`{"animation": {}}`)}
+		p, err := NewPack("test", fsys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, err := Stack{p}.Atlas(padding)
+		if err != nil {
+			t.Fatalf("Atlas() = %v", err)
+		}
+		if changed := a.Tick(); len(changed) != 1 {
+			t.Errorf("water didn't animate, changed %v", changed)
+		}
+	})
+	t.Run("Errors", func(t *testing.T) {
+		for name, meta := range map[string]string{
+			"Bad frame":  `{"animation": {"frames": [0, 3]}}`,
+			"Bad height": `{"animation": {"height": 3}}`,
+			"Bad JSON":   `{"animation": 1}`,
+		} {
+			fsys := fstest.MapFS{"lava.png": encode(t, strip(4, red, green)), "lava.png.mcmeta": {Data: []byte(meta)}}
+			if _, err := LoadAtlas(fsys, padding); err == nil {
+				t.Errorf("%s: LoadAtlas() should fail", name)
+			}
+		}
+	})
+}
+
+// fakeUploader records what a Manager does to the GPU.
+type fakeUploader struct {
+	next    uint32
+	live    map[uint32]*image.RGBA
+	deleted []uint32
+}
+
+func (f *fakeUploader) Upload(img *image.RGBA) uint32 {
+	f.next++
+	f.live[f.next] = img
+	return f.next
+}
+
+func (f *fakeUploader) Update(id uint32, img *image.RGBA) {
+	if f.live[id] == nil {
+		panic("updating a deleted texture")
+	}
+	f.live[id] = img
+}
+
+func (f *fakeUploader) Delete(id uint32) {
+	if f.live[id] == nil {
+		panic("deleting a deleted texture")
+	}
+	delete(f.live, id)
+	f.deleted = append(f.deleted, id)
+}
+
+func TestManager(t *testing.T) {
+	t.Parallel()
+	start := time.Unix(1000, 0)
+	fsys := fstest.MapFS{"textures/stone.png": {Data: encode(t, solid(4, grey)).Data, ModTime: start}}
+	up := &fakeUploader{live: map[uint32]*image.RGBA{}}
+	m := NewManager(fsys, up)
+
+	if a, b := m.Image("stone"), m.Image("stone"); a != b || a.RGBAAt(1, 1) != grey {
+		t.Errorf("Image() decoded the texture twice or wrongly")
+	}
+	a, b := m.Acquire("stone"), m.Acquire("stone")
+	if a.ID != b.ID || len(up.live) != 1 {
+		t.Fatalf("two holds made %d uploads, ids %d and %d", len(up.live), a.ID, b.ID)
+	}
+	a.Release()
+	a.Release()
+	if len(up.deleted) != 0 {
+		t.Errorf("texture deleted while still held")
+	}
+	b.Release()
+	if len(up.deleted) != 1 || len(up.live) != 0 {
+		t.Errorf("last release left %v live, deleted %v", up.live, up.deleted)
+	}
+
+	t.Run("Missing", func(t *testing.T) {
+		if got := m.Image("nope").RGBAAt(0, 0); got != (color.RGBA{248, 0, 248, 255}) {
+			t.Errorf("missing texture starts %v, want the magenta placeholder", got)
+		}
+	})
+
+	t.Run("Hot reload", func(t *testing.T) {
+		m.HotReload = time.Second
+		stone := m.Acquire("stone")
+		defer stone.Release()
+		m.Draw(start, 0)
+		fsys["textures/stone.png"] = &fstest.MapFile{Data: encode(t, solid(4, red)).Data, ModTime: start.Add(time.Minute)}
+		fsys["textures/nope.png"] = encode(t, solid(2, blue))
+		m.Draw(start.Add(time.Second/2), 0)
+		if got := up.live[stone.ID].RGBAAt(1, 1); got != grey {
+			t.Errorf("reloaded before HotReload was up")
+		}
+		m.Draw(start.Add(time.Second), 0)
+		if got := up.live[stone.ID].RGBAAt(1, 1); got != red {
+			t.Errorf("GPU copy is %v after reloading, want %v", got, red)
+		}
+		if got := m.Image("nope").RGBAAt(1, 1); got != blue {
+			t.Errorf("texture that turned up is %v, want %v", got, blue)
+		}
+		if reloaded := m.Reload(); len(reloaded) != 0 {
+			t.Errorf("reloaded %v with nothing changed", reloaded)
+		}
+	})
+
+	t.Run("Broken", func(t *testing.T) {
+		fsys["textures/broken.png"] = &fstest.MapFile{Data: []byte("not a png"), ModTime: start}
+		if got := m.Image("broken").RGBAAt(0, 0); got != (color.RGBA{248, 0, 248, 255}) {
+			t.Errorf("broken texture starts %v, want the magenta placeholder", got)
+		}
+		if reloaded := m.Reload(); len(reloaded) != 0 {
+			t.Errorf("reloaded %v though the broken file hasn't changed", reloaded)
+		}
+		fsys["textures/broken.png"] = &fstest.MapFile{Data: encode(t, solid(2, blue)).Data, ModTime: start.Add(time.Minute)}
+		if reloaded := m.Reload(); len(reloaded) != 1 || reloaded[0] != "broken" {
+			t.Errorf("Reload() = %v once fixed, want [broken]", reloaded)
+		}
+		if got := m.Image("broken").RGBAAt(1, 1); got != blue {
+			t.Errorf("fixed texture is %v, want %v", got, blue)
+		}
+	})
+
+	t.Run("Cleanup", func(t *testing.T) {
+		held := m.Acquire("stone")
+		m.Cleanup()
+		if len(up.live) != 0 {
+			t.Errorf("Cleanup() left %v on the GPU", up.live)
+		}
+		// a hold from before Cleanup mustn't free a later upload
+		again := m.Acquire("stone")
+		held.Release()
+		if up.live[again.ID] == nil {
+			t.Errorf("stale release deleted a live texture")
+		}
+		again.Release()
+	})
+}
+
+// quads is a 2 by 2 grid of size by size squares of each colour, left to right then down.
+func quads(size int, colors ...color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 2*size, 2*size))
+	for y := 0; y < 2*size; y++ {
+		for x := 0; x < 2*size; x++ {
+			img.SetRGBA(x, y, colors[y/size*2+x/size])
+		}
+	}
+	return img
+}
+
+func TestMipmaps(t *testing.T) {
+	t.Parallel()
+	purple := color.RGBA{128, 0, 128, 255}
+	t.Run("Chain", func(t *testing.T) {
+		levels := Mipmaps(quads(2, red, blue, blue, red), nil, 0)
+		var sizes []image.Point
+		for _, l := range levels {
+			sizes = append(sizes, l.Bounds().Size())
+		}
+		if len(sizes) != 3 || sizes[1] != image.Pt(2, 2) || sizes[2] != image.Pt(1, 1) {
+			t.Fatalf("levels are %v, want 4x4, 2x2 and 1x1", sizes)
+		}
+		if got := levels[1].RGBAAt(1, 0); got != blue {
+			t.Errorf("level 1 top right is %v, want %v", got, blue)
+		}
+		if got := levels[2].RGBAAt(0, 0); got != purple {
+			t.Errorf("last level is %v, want %v", got, purple)
+		}
+		if got := len(Mipmaps(quads(2, red, blue, blue, red), nil, 2)); got != 2 {
+			t.Errorf("got %d levels, want 2", got)
+		}
+		if got := Mipmaps(image.NewRGBA(image.Rect(0, 0, 4, 1)), nil, 0); len(got) != 3 || got[2].Bounds().Size() != image.Pt(1, 1) {
+			t.Errorf("4x1 image made %d levels", len(got))
+		}
+	})
+	t.Run("Regions", func(t *testing.T) {
+		img := quads(2, red, blue, red, blue)
+		// the left tile is 3 wide so the boxes straddle the tiles
+		img.SetRGBA(2, 0, red)
+		img.SetRGBA(2, 1, red)
+		img.SetRGBA(2, 2, red)
+		img.SetRGBA(2, 3, red)
+		tiles := []image.Rectangle{image.Rect(0, 0, 3, 4), image.Rect(3, 0, 4, 4)}
+		if got := Mipmaps(img, nil, 0)[1].RGBAAt(1, 0); got != purple {
+			t.Errorf("without regions the straddling box is %v, want %v", got, purple)
+		}
+		levels := Mipmaps(img, tiles, 0)
+		if got := levels[1].RGBAAt(1, 0); got != red {
+			t.Errorf("straddling box is %v, want %v from the tile it starts in", got, red)
+		}
+		if got := levels[2].RGBAAt(0, 0); got != red {
+			t.Errorf("last level is %v, want %v", got, red)
+		}
+	})
+	t.Run("Atlas", func(t *testing.T) {
+		a, err := NewAtlas(map[string]*image.RGBA{"red": strip(4, red), "blue": strip(4, blue)}, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, level := range a.Mipmaps(3) {
+			for _, name := range []string{"red", "blue"} {
+				r, _ := a.Tile(name)
+				want := a.Image.RGBAAt(r.Min.X, r.Min.Y)
+				scale := 1 << i
+				if got := level.RGBAAt(r.Min.X/scale, r.Min.Y/scale); got != want {
+					t.Errorf("level %d %s tile is %v, want %v", i, name, got, want)
+				}
+			}
+		}
+	})
+}
+
+func TestUploadOptions(t *testing.T) {
+	t.Parallel()
+	if got := (UploadOptions{}).withDefaults(); got.MinFilter != Linear || got.MagFilter != Linear || got.WrapS != ClampToEdge || got.WrapT != ClampToEdge {
+		t.Errorf("defaults are %+v, want linear and clamped like before", got)
+	}
+	if got := (UploadOptions{MinFilter: LinearMipmapLinear}).withDefaults(); got.MinFilter != Linear {
+		t.Errorf("mipmap filter without mipmaps became %v", got.MinFilter)
+	}
+	px := PixelArt.withDefaults()
+	if px.MinFilter != NearestMipmapLinear || px.MagFilter != Nearest || px.WrapS != Repeat {
+		t.Errorf("pixel art is %+v", px)
+	}
+	if got := len(PixelArt.levels(image.NewRGBA(image.Rect(0, 0, 64, 64)))); got != PixelArt.MipLevels {
+		t.Errorf("pixel art made %d levels, want %d", got, PixelArt.MipLevels)
+	}
+	if got := len((UploadOptions{}).levels(image.NewRGBA(image.Rect(0, 0, 64, 64)))); got != 1 {
+		t.Errorf("no mipmaps made %d levels", got)
+	}
+}
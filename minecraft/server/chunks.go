@@ -0,0 +1,195 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/dragon1672/go-mine/minecraft/protocol"
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/golang/glog"
+)
+
+// unloadInterval is how many ticks pass between sweeps for chunks nobody can see.
+const unloadInterval = 20
+
+// spiral lists chunk offsets within h chunks horizontally and v vertically, nearest first.
+// Columns go in rings spiralling out from the centre, and each column from the middle out.
+func spiral(h, v int) []vec.IntVec3 {
+	column := func(out []vec.IntVec3, x, z int) []vec.IntVec3 {
+		out = append(out, vec.IntVec3{X: x, Z: z})
+		for dy := 1; dy <= v; dy++ {
+			out = append(out, vec.IntVec3{X: x, Y: -dy, Z: z}, vec.IntVec3{X: x, Y: dy, Z: z})
+		}
+		return out
+	}
+	out := column(nil, 0, 0)
+	for r := 1; r <= h; r++ {
+		for x := -r; x < r; x++ {
+			out = column(out, x, -r)
+		}
+		for z := -r; z < r; z++ {
+			out = column(out, r, z)
+		}
+		for x := r; x > -r; x-- {
+			out = column(out, x, r)
+		}
+		for z := r; z > -r; z-- {
+			out = column(out, -r, z)
+		}
+	}
+	return out
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// inView reports whether a player in chunk center should have chunk pos.
+func (s *Server) inView(center, pos vec.IntVec3) bool {
+	return abs(pos.X-center.X) <= s.cfg.ViewDistance &&
+		abs(pos.Z-center.Z) <= s.cfg.ViewDistance &&
+		abs(pos.Y-center.Y) <= s.cfg.VerticalViewDistance
+}
+
+// updateChunks rebuilds the player's chunk queue around their current chunk,
+// and tells the client to drop chunks that are now out of range.
+func (p *Player) updateChunks() {
+	p.mu.Lock()
+	center := p.lastChunk
+	queue := make([]vec.IntVec3, 0, len(p.s.offsets))
+	for _, off := range p.s.offsets {
+		if pos := center.Add(off); !p.sentChunks[pos] {
+			queue = append(queue, pos)
+		}
+	}
+	p.chunkQueue = queue
+	var unload []vec.IntVec3
+	for pos := range p.sentChunks {
+		if !p.s.inView(center, pos) {
+			delete(p.sentChunks, pos)
+			unload = append(unload, pos)
+		}
+	}
+	p.mu.Unlock()
+	for _, pos := range unload {
+		p.send(&protocol.UnloadChunk{Pos: pos})
+	}
+}
+
+// sendChunks sends up to limit chunks from the front of the queue, fewer if the client is
+// falling behind. limit <= 0 sends them all.
+func (p *Player) sendChunks(limit int) {
+	for n := 0; limit <= 0 || n < limit; n++ {
+		if len(p.out) > sendQueueSize/2 {
+			return
+		}
+		if !p.sendChunk() {
+			return
+		}
+	}
+}
+
+// sendChunk sends the chunk at the front of the queue, returning false if it's empty.
+// Holding stream while marking, snapshotting and queueing it means a block change lands
+// either before the snapshot, so it's in it, or after the chunk is queued, so
+// sendBlockChange queues it behind; the client drops changes to chunks it doesn't have.
+func (p *Player) sendChunk() bool {
+	p.stream.Lock()
+	defer p.stream.Unlock()
+	p.mu.Lock()
+	if len(p.chunkQueue) == 0 {
+		p.mu.Unlock()
+		return false
+	}
+	pos := p.chunkQueue[0]
+	p.chunkQueue = p.chunkQueue[1:]
+	p.sentChunks[pos] = true
+	p.chunksSent++
+	p.mu.Unlock()
+	data, err := world.EncodeChunk(p.s.world.Snapshot(pos))
+	if err != nil {
+		glog.Errorf("Error encoding chunk %v: %v", pos, err)
+		return true
+	}
+	p.send(&protocol.ChunkData{Pos: pos, Data: data})
+	return true
+}
+
+// sendBlockChange passes a change on if the client has its chunk, see sendChunk.
+func (p *Player) sendBlockChange(pkt *protocol.BlockChange) {
+	chunk, _ := world.ChunkOf(pkt.Pos)
+	p.stream.Lock()
+	defer p.stream.Unlock()
+	if p.hasChunk(chunk) {
+		p.send(pkt)
+	}
+}
+
+// PlayerStats are a player's queue depths, for tuning Config.ChunksPerTick.
+type PlayerStats struct {
+	ID   uint32
+	Name string
+	// ChunkQueue is how many chunks in view haven't been sent yet.
+	ChunkQueue int
+	// SendQueue is how many packets are waiting to be written to the connection.
+	SendQueue int
+	// ChunksLoaded is how many chunks the client has now, ChunksSent counts every chunk ever sent.
+	ChunksLoaded int
+	ChunksSent   uint64
+}
+
+func (p *Player) Stats() PlayerStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return PlayerStats{
+		ID:           p.ID,
+		Name:         p.name,
+		ChunkQueue:   len(p.chunkQueue),
+		SendQueue:    len(p.out),
+		ChunksLoaded: len(p.sentChunks),
+		ChunksSent:   p.chunksSent,
+	}
+}
+
+func (st PlayerStats) String() string {
+	return fmt.Sprintf("%s: %d chunks queued, %d packets queued, %d chunks loaded, %d sent",
+		st.Name, st.ChunkQueue, st.SendQueue, st.ChunksLoaded, st.ChunksSent)
+}
+
+// Stats lists every player's stats, ordered by ID.
+func (s *Server) Stats() []PlayerStats {
+	players := s.Players()
+	out := make([]PlayerStats, len(players))
+	for i, p := range players {
+		out[i] = p.Stats()
+	}
+	return out
+}
+
+// unloadUnused drops world chunks outside every player's view.
+func (s *Server) unloadUnused(players []*Player) {
+	centers := make([]vec.IntVec3, len(players))
+	for i, p := range players {
+		p.mu.Lock()
+		centers[i] = p.lastChunk
+		p.mu.Unlock()
+	}
+	for _, pos := range s.world.LoadedChunks() {
+		seen := false
+		for _, c := range centers {
+			if s.inView(c, pos) {
+				seen = true
+				break
+			}
+		}
+		if seen {
+			continue
+		}
+		if _, err := s.world.Unload(pos); err != nil {
+			glog.Errorf("Error unloading chunk %v: %v", pos, err)
+		}
+	}
+}
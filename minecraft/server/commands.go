@@ -0,0 +1,58 @@
+package server
+
+import (
+	"github.com/dragon1672/go-mine/minecraft/command"
+	"github.com/dragon1672/go-mine/minecraft/gamemode"
+)
+
+// RegisterCommands adds the server's own commands to reg.
+func RegisterCommands(reg *command.Registry, s *Server) error {
+	if err := reg.Register(gamemodeCommand(s)); err != nil {
+		return err
+	}
+	return reg.Register(&command.Command{
+		Name:        "players",
+		Description: "lists players with their chunk and packet queue depths",
+		Permission:  command.PermModerator,
+		Run: func(ctx *command.Context) error {
+			stats := s.Stats()
+			ctx.Replyf("%d players online", len(stats))
+			for _, st := range stats {
+				ctx.Replyf("%v", st)
+			}
+			return nil
+		},
+	})
+}
+
+func gamemodeCommand(s *Server) *command.Command {
+	var names []string
+	for _, m := range gamemode.Modes {
+		names = append(names, m.String())
+	}
+	return &command.Command{
+		Name:        "gamemode",
+		Description: "changes your or another player's game mode",
+		Permission:  command.PermGameMaster,
+		Args:        []command.Arg{command.Word("mode", names...), command.Optional(command.Word("player"))},
+		Run: func(ctx *command.Context) error {
+			m, err := gamemode.Parse(ctx.String("mode"))
+			if err != nil {
+				return command.Failed("%v", err)
+			}
+			p, ok := ctx.Source.(*Player)
+			if ctx.Has("player") {
+				p, ok = s.Player(ctx.String("player"))
+				if !ok {
+					return command.Failed("%s is not online", ctx.String("player"))
+				}
+			}
+			if !ok {
+				return command.Failed("%s is not a player, name one", ctx.Source.Name())
+			}
+			p.SetGameMode(m)
+			ctx.Replyf("Set %s's game mode to %v", p.Name(), m)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dragon1672/go-mine/minecraft/command"
+	"github.com/dragon1672/go-mine/minecraft/gamemode"
+	"github.com/dragon1672/go-mine/minecraft/rcon"
+	"github.com/dragon1672/go-mine/minecraft/utils/tickers"
+	"github.com/dragon1672/go-mine/minecraft/webmap"
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/dragon1672/go-mine/minecraft/world/edit"
+	"github.com/dragon1672/go-mine/minecraft/world/journal"
+	"github.com/dragon1672/go-mine/minecraft/world/save"
+	"github.com/dragon1672/go-mine/minecraft/world/worldtime"
+	"github.com/golang/glog"
+)
+
+// journalCompactInterval is how often, in seconds, old journal entries are dropped.
+const journalCompactInterval = 60 * 60
+
+func compactJournal(j *journal.Journal, retention time.Duration) {
+	n, err := j.Compact(time.Now().Add(-retention))
+	if err != nil {
+		glog.Errorf("Error compacting journal: %v", err)
+		return
+	}
+	if n > 0 {
+		glog.Infof("Dropped %d journal entries older than %v", n, retention)
+	}
+}
+
+// RunDedicated runs a headless server until ctx is done, args are its command line flags.
+func RunDedicated(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("server", flag.ContinueOnError)
+	addr := fs.String("addr", ":25565", "address to listen on")
+	saveDir := fs.String("save_dir", "", "directory to load and save the level from, empty to not persist")
+	seed := fs.Int64("seed", time.Now().UnixNano(), "world seed for a new level")
+	password := fs.String("password", "", "password clients must send to join")
+	rconAddr := fs.String("rcon_addr", ":25575", "address to listen for RCON on")
+	rconPassword := fs.String("rcon_password", "", "RCON password, RCON is off if empty")
+	mapAddr := fs.String("map_addr", "", "address to serve the web map on, off if empty")
+	retention := fs.Duration("journal_retention", 7*24*time.Hour, "how long block changes are kept in the journal for rollbacks")
+	ops := fs.String("ops", "", "comma separated player names allowed to run every command")
+	dayLength := fs.Int64("day_length", worldtime.DefaultDayLength, "ticks in a full day/night cycle for a new level")
+	cfg := DefaultConfig()
+	mode := fs.String("gamemode", cfg.GameMode.String(), "game mode new players start in")
+	fs.IntVar(&cfg.ViewDistance, "view_distance", cfg.ViewDistance, "chunks sent around each player horizontally")
+	fs.IntVar(&cfg.VerticalViewDistance, "vertical_view_distance", cfg.VerticalViewDistance, "chunks sent above and below each player")
+	fs.StringVar(&cfg.Motd, "motd", cfg.Motd, "message shown in the Java Edition server list")
+	fs.IntVar(&cfg.MaxPlayers, "max_players", cfg.MaxPlayers, "most players online at once, 0 for no limit")
+	fs.IntVar(&cfg.ChunksPerTick, "chunks_per_tick", cfg.ChunksPerTick, "most chunks sent to each player per tick, 0 for no limit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg.Password = *password
+	var err error
+	if cfg.GameMode, err = gamemode.Parse(*mode); err != nil {
+		return err
+	}
+	if *ops != "" {
+		cfg.Ops = strings.Split(*ops, ",")
+	}
+
+	level := save.Level{Seed: *seed, DayLength: *dayLength}
+	if *saveDir != "" {
+		l, ok, err := save.ReadLevel(*saveDir)
+		if err != nil {
+			return fmt.Errorf("error loading level: %v", err)
+		}
+		if ok {
+			level = l
+		}
+	}
+	w := world.New(level.Seed)
+	clock := worldtime.NewClock(level.DayLength)
+	clock.SetTicks(level.Time)
+	w.SetClock(clock)
+	if *saveDir != "" {
+		w.SetStorage(save.NewChunkStore(*saveDir))
+	}
+	glog.Infof("Loaded world with seed %d", level.Seed)
+
+	reg := command.NewRegistry()
+	if err := command.RegisterCore(reg, w); err != nil {
+		return err
+	}
+	editor := edit.NewEditor(w, edit.DefaultBatchSize)
+	if err := edit.RegisterCommands(reg, editor); err != nil {
+		return err
+	}
+	var jrnl *journal.Journal
+	if *saveDir != "" {
+		if jrnl, err = journal.Open(*saveDir); err != nil {
+			return err
+		}
+		defer jrnl.Close()
+		compactJournal(jrnl, *retention)
+		jrnl.Attach(w)
+		if err := journal.RegisterCommands(reg, jrnl, w); err != nil {
+			return err
+		}
+	}
+	srv := New(w, reg, cfg)
+	if *saveDir != "" {
+		srv.SetPlayerStore(save.NewPlayerStore(*saveDir))
+	}
+	if err := RegisterCommands(reg, srv); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var ticks int
+	tickCleanup := tickers.StartTicker(ctx, time.Second/worldtime.TicksPerSecond, func(t time.Time, dt time.Duration) (bool, error) {
+		w.Clock().Tick()
+		editor.Tick()
+		srv.Tick()
+		ticks++
+		if jrnl != nil && ticks%worldtime.TicksPerSecond == 0 {
+			if err := jrnl.Flush(); err != nil {
+				glog.Errorf("Error flushing journal: %v", err)
+			}
+			if ticks%(journalCompactInterval*worldtime.TicksPerSecond) == 0 {
+				compactJournal(jrnl, *retention)
+			}
+		}
+		return true, nil
+	})
+	// stopTicking is also called before the final save, so it must only run once
+	stopTicking := sync.OnceFunc(tickCleanup)
+	defer stopTicking()
+	go func() {
+		if err := command.RunConsole(ctx, os.Stdin, reg, &command.ConsoleSource{Out: os.Stdout}); err != nil {
+			glog.Errorf("Console stopped: %v", err)
+		}
+	}()
+
+	if *rconPassword != "" {
+		rc, err := rcon.NewServer(reg, *rconPassword)
+		if err != nil {
+			return err
+		}
+		rln, err := net.Listen("tcp", *rconAddr)
+		if err != nil {
+			return fmt.Errorf("error listening for rcon on %s: %v", *rconAddr, err)
+		}
+		glog.Infof("RCON listening on %v", rln.Addr())
+		go func() {
+			if err := rc.Serve(ctx, rln); err != nil {
+				glog.Errorf("RCON stopped: %v", err)
+			}
+		}()
+	}
+
+	if *mapAddr != "" {
+		markers := func() []webmap.Marker {
+			var out []webmap.Marker
+			for _, p := range srv.Players() {
+				pos := p.Pos()
+				out = append(out, webmap.Marker{Name: p.Name(), X: pos.X, Y: pos.Y, Z: pos.Z})
+			}
+			return out
+		}
+		hs := &http.Server{Addr: *mapAddr, Handler: webmap.New(w, markers, webmap.DefaultConfig())}
+		go func() {
+			<-ctx.Done()
+			hs.Close()
+		}()
+		go func() {
+			glog.Infof("Web map on http://%s", *mapAddr)
+			if err := hs.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				glog.Errorf("Web map stopped: %v", err)
+			}
+		}()
+	}
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %v", *addr, err)
+	}
+	glog.Infof("Listening on %v", ln.Addr())
+	serveErr := srv.Serve(ctx, ln)
+
+	// nothing may change the world while it is saved, edits still queued are applied first
+	stopTicking()
+	editor.Applier().Flush()
+	if *saveDir != "" {
+		if err := w.SaveAll(); err != nil {
+			return fmt.Errorf("error saving chunks: %v", err)
+		}
+		level.Time = w.Clock().Ticks()
+		level.DayLength = w.Clock().DayLength()
+		if err := save.WriteLevel(*saveDir, level); err != nil {
+			return fmt.Errorf("error saving level: %v", err)
+		}
+	}
+	return serveErr
+}
@@ -0,0 +1,429 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/dragon1672/go-mine/minecraft/command"
+	"github.com/dragon1672/go-mine/minecraft/gamemode"
+	"github.com/dragon1672/go-mine/minecraft/items"
+	"github.com/dragon1672/go-mine/minecraft/mining"
+	"github.com/dragon1672/go-mine/minecraft/physics"
+	"github.com/dragon1672/go-mine/minecraft/protocol"
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+	"github.com/dragon1672/go-mine/minecraft/world/save"
+	"github.com/dragon1672/go-mine/minecraft/world/worldtime"
+	"github.com/golang/glog"
+)
+
+// sendQueueSize is how many packets can be waiting on a slow client before it is dropped.
+const sendQueueSize = 4096
+
+// inputBurst is how many movement inputs a client may bank up. Each server tick allows one
+// more, so a client can't move faster than the server simulates, while a second of inputs
+// bunched up by a laggy link still all get through.
+const inputBurst = worldtime.TicksPerSecond
+
+// maxChatLength is the longest chat message in bytes, so the broadcast with the name in front
+// still fits in a packet string.
+const maxChatLength = 256
+
+// maxReach is how far from their position a player may change blocks.
+const maxReach = 8
+
+// Player is a connected client. It is a command.Source so chat commands run as the player.
+type Player struct {
+	ID   uint32
+	name string
+
+	s    *Server
+	conn *protocol.Conn
+	out  chan protocol.Packet
+	done chan struct{}
+	once sync.Once
+	// stream keeps chunk data and block changes in order, see sendChunk.
+	stream sync.Mutex
+
+	mu     sync.Mutex
+	mode   gamemode.Mode
+	health int
+	state  physics.State
+	// fallFrom is the highest point since the player was last on the ground or flying.
+	fallFrom  float64
+	lastInput uint32
+	// inputBudget is how many inputs may run before the next tick, see inputBurst.
+	inputBudget int
+	lastChunk   vec.IntVec3
+	sentChunks  map[vec.IntVec3]bool
+	// chunkQueue is what the client still needs, nearest first, see sendChunks.
+	chunkQueue []vec.IntVec3
+	chunksSent uint64
+
+	// hands guards what the player holds and is mining. It is separate from mu since
+	// breaking a block calls back into every player to send the change.
+	hands     sync.Mutex
+	inventory *items.PlayerInventory
+	breaker   *mining.Breaker
+}
+
+var (
+	_ command.Source     = (*Player)(nil)
+	_ command.Teleporter = (*Player)(nil)
+)
+
+func newPlayer(s *Server, conn *protocol.Conn, id uint32, name string, pos vec.Vec3, saved save.Player) *Player {
+	chunk, _ := world.ChunkOf(pos.Floor())
+	inv := items.NewPlayerInventory(s.items)
+	if len(saved.Inventory) > 0 {
+		if err := json.Unmarshal(saved.Inventory, inv); err != nil {
+			glog.Errorf("Error loading %s's inventory, starting it empty: %v", name, err)
+		}
+	}
+	return &Player{
+		ID:          id,
+		name:        name,
+		s:           s,
+		conn:        conn,
+		out:         make(chan protocol.Packet, sendQueueSize),
+		done:        make(chan struct{}),
+		mode:        saved.GameMode,
+		health:      saved.Health,
+		state:       physics.State{Pos: pos},
+		fallFrom:    pos.Y,
+		inputBudget: inputBurst,
+		lastChunk:   chunk,
+		sentChunks:  map[vec.IntVec3]bool{},
+		inventory:   inv,
+		breaker:     mining.NewBreaker(s.mining, rand.New(rand.NewSource(time.Now().UnixNano()))),
+	}
+}
+
+func (p *Player) Pos() vec.Vec3 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state.Pos
+}
+
+// command.Source
+
+func (p *Player) Name() string               { return p.name }
+func (p *Player) Permission() int            { return p.s.permission(p.name) }
+func (p *Player) Position() (vec.Vec3, bool) { return p.Pos(), true }
+func (p *Player) Reply(msg string)           { p.send(&protocol.Chat{Message: msg}) }
+
+// Teleport moves the player and tells their client.
+func (p *Player) Teleport(pos vec.Vec3) error {
+	p.mu.Lock()
+	p.state = physics.State{Pos: pos}
+	p.fallFrom = pos.Y
+	p.lastChunk, _ = world.ChunkOf(pos.Floor())
+	p.mu.Unlock()
+	pkt := &protocol.PlayerPosition{PlayerID: p.ID, Pos: pos}
+	p.send(pkt)
+	p.s.broadcast(pkt, p.ID)
+	p.updateChunks()
+	return nil
+}
+
+func (p *Player) GameMode() gamemode.Mode {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.mode
+}
+
+// SetGameMode switches the player's mode, tells their client and saves it.
+func (p *Player) SetGameMode(m gamemode.Mode) {
+	p.mu.Lock()
+	p.mode = m
+	p.fallFrom = p.state.Pos.Y
+	p.mu.Unlock()
+	p.send(&protocol.GameMode{Mode: m})
+	p.s.savePlayer(p)
+}
+
+func (p *Player) Health() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.health
+}
+
+// saved is what is kept between sessions.
+func (p *Player) saved() save.Player {
+	p.hands.Lock()
+	inv, err := json.Marshal(p.inventory)
+	p.hands.Unlock()
+	if err != nil {
+		glog.Errorf("Error saving %s's inventory: %v", p.name, err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return save.Player{GameMode: p.mode, Health: p.health, Inventory: inv}
+}
+
+// damage takes health if the player's mode allows it, respawning them if it runs out.
+func (p *Player) damage(n int) {
+	p.mu.Lock()
+	if n <= 0 || p.mode.Abilities().Invulnerable {
+		p.mu.Unlock()
+		return
+	}
+	p.health -= n
+	died := p.health <= 0
+	if died {
+		p.health = gamemode.MaxHealth
+	}
+	health := p.health
+	p.mu.Unlock()
+
+	p.send(&protocol.Health{Health: int32(health)})
+	if died {
+		glog.Infof("%s died", p.name)
+		p.s.broadcast(&protocol.Chat{Message: fmt.Sprintf("%s died", p.name)}, 0)
+		p.Teleport(p.s.cfg.Spawn)
+	}
+}
+
+// Kick sends reason to the client and closes its connection.
+func (p *Player) Kick(reason string) {
+	p.send(&protocol.Disconnect{Reason: reason})
+	p.close()
+}
+
+// send queues pkt for the writer goroutine. A client that can't keep up is dropped.
+func (p *Player) send(pkt protocol.Packet) {
+	select {
+	case <-p.done:
+	case p.out <- pkt:
+	default:
+		glog.Warningf("Dropping %s, send queue is full", p.name)
+		p.close()
+	}
+}
+
+// close stops the writer after it flushes what is already queued.
+func (p *Player) close() {
+	p.once.Do(func() { close(p.done) })
+}
+
+func (p *Player) writeLoop() {
+	defer p.conn.Close()
+	for {
+		select {
+		case pkt := <-p.out:
+			if err := p.conn.WritePacket(pkt); err != nil {
+				p.close()
+				return
+			}
+		case <-p.done:
+			// flush anything queued, such as a Disconnect
+			for {
+				select {
+				case pkt := <-p.out:
+					if p.conn.WritePacket(pkt) != nil {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (p *Player) hasChunk(pos vec.IntVec3) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.sentChunks[pos]
+}
+
+func (p *Player) readLoop(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.done:
+			return nil
+		default:
+		}
+		pkt, err := p.conn.ReadPacket()
+		if err != nil {
+			return err
+		}
+		if err := p.handlePacket(pkt); err != nil {
+			p.Kick(err.Error())
+			return err
+		}
+	}
+}
+
+func (p *Player) handlePacket(pkt protocol.Packet) error {
+	switch pkt := pkt.(type) {
+	case *protocol.PlayerInput:
+		p.handleInput(pkt.Input)
+	case *protocol.BlockChange:
+		p.handleBlockChange(pkt)
+	case *protocol.Chat:
+		p.handleChat(pkt.Message)
+	default:
+		return fmt.Errorf("unexpected packet %d", pkt.ID())
+	}
+	return nil
+}
+
+// handleInput runs one tick of the client's movement. The server's result is authoritative,
+// the client replays its newer inputs on top of the PlayerState sent back.
+func (p *Player) handleInput(in physics.Input) {
+	p.mu.Lock()
+	if in.Seq <= p.lastInput {
+		// a duplicate or replay, running it again would let a client move faster
+		p.mu.Unlock()
+		return
+	}
+	p.lastInput = in.Seq
+	if p.inputBudget == 0 {
+		// sending faster than the server ticks, acknowledge it without moving so the
+		// client's prediction snaps back
+		state := p.state
+		p.mu.Unlock()
+		p.send(&protocol.PlayerState{Seq: in.Seq, State: state})
+		return
+	}
+	p.inputBudget--
+	ab := p.mode.Abilities()
+	p.state = physics.Step(p.s.world, physics.PlayerBody, p.state, in, ab.Movement)
+	state := p.state
+	fall := 0
+	switch {
+	case ab.Movement.NoClip, ab.Movement.Fly && in.Fly:
+		p.fallFrom = state.Pos.Y
+	case state.OnGround:
+		fall = gamemode.FallDamage(p.fallFrom - state.Pos.Y)
+		p.fallFrom = state.Pos.Y
+	default:
+		p.fallFrom = max(p.fallFrom, state.Pos.Y)
+	}
+	chunk, _ := world.ChunkOf(state.Pos.Floor())
+	moved := chunk != p.lastChunk
+	p.lastChunk = chunk
+	p.mu.Unlock()
+
+	p.send(&protocol.PlayerState{Seq: in.Seq, State: state})
+	p.s.broadcast(&protocol.PlayerPosition{PlayerID: p.ID, Pos: state.Pos}, p.ID)
+	if moved {
+		p.updateChunks()
+	}
+	p.damage(fall)
+}
+
+// refillInputs allows one more input, called every server tick.
+func (p *Player) refillInputs() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inputBudget = min(p.inputBudget+1, inputBurst)
+}
+
+// handleBlockChange applies a client's edit if it is valid, otherwise resends the real block
+// so the client's copy doesn't drift. Air breaks the block, anything else places it.
+func (p *Player) handleBlockChange(pkt *protocol.BlockChange) {
+	chunk, _ := world.ChunkOf(pkt.Pos)
+	ab := p.GameMode().Abilities()
+	valid := ab.Interact && pkt.Block.String() != "unknown" && !pkt.Block.IsFluid() &&
+		p.hasChunk(chunk) && p.inReach(pkt.Pos)
+	if valid && pkt.Block == blocks.Air {
+		valid = p.dig(pkt.Pos, ab)
+	} else if valid {
+		valid = p.place(pkt.Pos, pkt.Block, ab)
+	}
+	if !valid {
+		p.send(&protocol.BlockChange{Pos: pkt.Pos, Block: p.s.world.BlockAt(pkt.Pos)})
+	}
+}
+
+func (p *Player) inReach(pos vec.IntVec3) bool {
+	return p.Pos().Sub(pos.Center()).Length() <= maxReach
+}
+
+// dig starts mining pos, it reports whether the block is already gone. Anything slower than
+// instant breaks over the following ticks, see mine.
+func (p *Player) dig(pos vec.IntVec3, ab gamemode.Abilities) bool {
+	if p.s.world.BlockAt(pos) == blocks.Air {
+		return true
+	}
+	p.hands.Lock()
+	defer p.hands.Unlock()
+	p.breaker.Start(pos)
+	return p.mineLocked(ab)
+}
+
+// place puts b at pos using up one of its item from the hotbar. The client doesn't say which
+// slot it is holding, so the first one with the item is selected.
+func (p *Player) place(pos vec.IntVec3, b blocks.SimpleBlockType, ab gamemode.Abilities) bool {
+	if !ab.InfiniteItems {
+		it, ok := p.s.items.ForBlock(b)
+		if !ok {
+			return false
+		}
+		p.hands.Lock()
+		ok = p.inventory.SelectItem(it.ID) && p.inventory.ConsumeHeld(1, ab) == nil
+		p.hands.Unlock()
+		if !ok {
+			return false
+		}
+	}
+	p.s.world.SetBlockBy(pos, b, p.name)
+	return true
+}
+
+// mine advances the block being broken by a tick, called every server tick.
+func (p *Player) mine() {
+	ab := p.GameMode().Abilities()
+	p.hands.Lock()
+	defer p.hands.Unlock()
+	if pos, ok := p.breaker.Target(); ok && !p.inReach(pos) {
+		p.breaker.Stop()
+	}
+	p.mineLocked(ab)
+}
+
+// mineLocked ticks the breaker with the held tool, the drops go into the inventory.
+// p.hands must be held.
+func (p *Player) mineLocked(ab gamemode.Abilities) (broken bool) {
+	var tool items.Tool
+	if it, ok := p.s.items.Get(p.inventory.Held().Item); ok {
+		tool = it.Tool
+	}
+	return p.breaker.Tick(playerEdits{p}, tool, ab, func(d mining.ItemDrop) {
+		p.inventory.Add(d.Stack)
+	})
+}
+
+// playerEdits is the world as a mining.World, with changes logged as the player's.
+type playerEdits struct {
+	p *Player
+}
+
+func (e playerEdits) BlockAt(pos vec.IntVec3) blocks.SimpleBlockType {
+	return e.p.s.world.BlockAt(pos)
+}
+
+func (e playerEdits) SetBlock(pos vec.IntVec3, t blocks.SimpleBlockType) {
+	e.p.s.world.SetBlockBy(pos, t, e.p.name)
+}
+
+func (p *Player) handleChat(msg string) {
+	if len(msg) > maxChatLength {
+		p.Reply(fmt.Sprintf("message is too long, the limit is %d bytes", maxChatLength))
+		return
+	}
+	if p.s.commands != nil && command.HandleChat(p.s.commands, p, msg) {
+		return
+	}
+	glog.Infof("<%s> %s", p.name, msg)
+	p.s.broadcast(&protocol.Chat{Message: fmt.Sprintf("<%s> %s", p.name, msg)}, 0)
+}
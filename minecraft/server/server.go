@@ -0,0 +1,376 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dragon1672/go-mine/minecraft/command"
+	"github.com/dragon1672/go-mine/minecraft/gamemode"
+	"github.com/dragon1672/go-mine/minecraft/items"
+	"github.com/dragon1672/go-mine/minecraft/mining"
+	"github.com/dragon1672/go-mine/minecraft/protocol"
+	"github.com/dragon1672/go-mine/minecraft/protocol/javaping"
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/dragon1672/go-mine/minecraft/world/save"
+	"github.com/golang/glog"
+)
+
+const (
+	handshakeTimeout = 5 * time.Second
+	maxNameLength    = 16
+)
+
+type Config struct {
+	// Password is required from clients in their Hello if set.
+	Password string
+	// ViewDistance is the radius of chunks sent around a player horizontally.
+	ViewDistance int
+	// VerticalViewDistance is the same radius for chunks above and below.
+	VerticalViewDistance int
+	// ChunksPerTick limits how many chunks each player is sent per Tick, 0 for no limit.
+	ChunksPerTick int
+	// Ops can run every command, everyone else gets command.PermAll.
+	Ops   []string
+	Spawn vec.Vec3
+	// Motd is shown in the Java Edition server list, see javaping.
+	Motd string
+	// MaxPlayers turns away players once the server is full, 0 for no limit.
+	MaxPlayers int
+	// GameMode is what players start in the first time they join.
+	GameMode gamemode.Mode
+}
+
+func DefaultConfig() Config {
+	return Config{
+		ViewDistance:         6,
+		VerticalViewDistance: 3,
+		ChunksPerTick:        8,
+		Motd:                 "A go-mine server",
+		MaxPlayers:           20,
+		GameMode:             gamemode.Survival,
+		Spawn:                vec.Vec3{X: 0.5, Y: 64, Z: 0.5},
+	}
+}
+
+// PlayerStore remembers players between sessions, such as their game mode.
+type PlayerStore interface {
+	// LoadPlayer returns a previously saved player, ok is false if there isn't one.
+	LoadPlayer(name string) (p save.Player, ok bool, err error)
+	SavePlayer(name string, p save.Player) error
+}
+
+// Server accepts players over TCP and keeps them in sync with one world.
+type Server struct {
+	cfg      Config
+	world    *world.World
+	commands *command.Registry
+	items    *items.Registry
+	mining   mining.Table
+	// offsets is spiral for the view distance, worked out once.
+	offsets []vec.IntVec3
+	ticks   uint64
+
+	mu      sync.Mutex
+	players map[uint32]*Player
+	nextID  uint32
+	store   PlayerStore
+}
+
+// New makes a server for w. commands may be nil to disable chat commands.
+func New(w *world.World, commands *command.Registry, cfg Config) *Server {
+	s := &Server{
+		cfg:      cfg,
+		world:    w,
+		commands: commands,
+		items:    items.DefaultRegistry(),
+		mining:   mining.DefaultTable(),
+		offsets:  spiral(cfg.ViewDistance, cfg.VerticalViewDistance),
+		players:  map[uint32]*Player{},
+		nextID:   1,
+	}
+	w.OnChange(s.broadcastBlockChange)
+	return s
+}
+
+func (s *Server) World() *world.World {
+	return s.world
+}
+
+// SetPlayerStore makes players keep their game mode and health between sessions.
+func (s *Server) SetPlayerStore(ps PlayerStore) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store = ps
+}
+
+func (s *Server) playerStore() PlayerStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.store
+}
+
+// loadPlayer is name's saved state, or a fresh one for a new player.
+func (s *Server) loadPlayer(name string) save.Player {
+	fresh := save.Player{GameMode: s.cfg.GameMode, Health: gamemode.MaxHealth}
+	store := s.playerStore()
+	if store == nil {
+		return fresh
+	}
+	saved, ok, err := store.LoadPlayer(name)
+	if err != nil {
+		glog.Errorf("Error loading %s, starting them fresh: %v", name, err)
+	}
+	if !ok || err != nil {
+		return fresh
+	}
+	// a broken or hand edited save mustn't spawn them dead or overhealed
+	saved.Health = min(max(saved.Health, 1), gamemode.MaxHealth)
+	return saved
+}
+
+func (s *Server) savePlayer(p *Player) {
+	store := s.playerStore()
+	if store == nil {
+		return
+	}
+	if err := store.SavePlayer(p.name, p.saved()); err != nil {
+		glog.Errorf("Error saving %s: %v", p.name, err)
+	}
+}
+
+// Player finds an online player by name.
+func (s *Server) Player(name string) (*Player, bool) {
+	for _, p := range s.Players() {
+		if strings.EqualFold(p.name, name) {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Players lists everyone online, ordered by ID.
+func (s *Server) Players() []*Player {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*Player, 0, len(s.players))
+	for _, p := range s.players {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Tick streams queued chunks to players, allows each another movement input, advances
+// their mining and unloads chunks nobody can see.
+// Call it once per game tick from a single goroutine.
+func (s *Server) Tick() {
+	players := s.Players()
+	for _, p := range players {
+		p.refillInputs()
+		p.mine()
+		p.sendChunks(s.cfg.ChunksPerTick)
+	}
+	s.ticks++
+	if s.ticks%unloadInterval == 0 {
+		s.unloadUnused(players)
+	}
+}
+
+// Serve accepts connections on ln until ctx is done or ln is closed.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				s.disconnectAll("server shutting down")
+				return nil
+			}
+			return fmt.Errorf("error accepting connection: %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.route(ctx, c)
+		}()
+	}
+}
+
+func (s *Server) disconnectAll(reason string) {
+	for _, p := range s.Players() {
+		p.Kick(reason)
+	}
+}
+
+func (s *Server) permission(name string) int {
+	for _, op := range s.cfg.Ops {
+		if op == name {
+			return command.PermOwner
+		}
+	}
+	return command.PermAll
+}
+
+// bufferedConn reads through r, which may hold bytes already peeked from the connection.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// route tells go-mine clients from Java Edition server list pings. Our frames start with a
+// 32 bit big endian length under MaxPacketSize so their first byte is always 0, while a Java
+// handshake starts with its VarInt length which never is.
+func (s *Server) route(ctx context.Context, c net.Conn) {
+	r := bufio.NewReader(c)
+	c.SetReadDeadline(time.Now().Add(handshakeTimeout))
+	first, err := r.Peek(1)
+	if err != nil {
+		c.Close()
+		return
+	}
+	if first[0] != 0 {
+		if err := javaping.Handle(c, r, s.javaStatus); err != nil {
+			glog.V(1).Infof("Java ping from %v failed: %v", c.RemoteAddr(), err)
+		}
+		return
+	}
+	s.handle(ctx, protocol.NewConn(&bufferedConn{Conn: c, r: r}))
+}
+
+// maxSample is how many names the Java server list shows when hovering over the player count.
+const maxSample = 12
+
+func (s *Server) javaStatus() javaping.Status {
+	players := s.Players()
+	st := javaping.Status{
+		// no Java protocol number matches, so the list shows our name in place of ping bars
+		Version:     javaping.Version{Name: "go-mine", Protocol: -1},
+		Players:     javaping.Players{Max: s.cfg.MaxPlayers, Online: len(players)},
+		Description: javaping.Description{Text: s.cfg.Motd},
+	}
+	for i, p := range players {
+		if i == maxSample {
+			break
+		}
+		st.Players.Sample = append(st.Players.Sample, javaping.PlayerInfo{Name: p.name, ID: javaping.OfflineUUID(p.name)})
+	}
+	return st
+}
+
+// handshake checks the client's Hello and registers it as a player.
+func (s *Server) handshake(conn *protocol.Conn) (*Player, error) {
+	conn.NetConn().SetReadDeadline(time.Now().Add(handshakeTimeout))
+	defer conn.NetConn().SetReadDeadline(time.Time{})
+	pkt, err := conn.ReadPacket()
+	if err != nil {
+		return nil, fmt.Errorf("error reading hello: %v", err)
+	}
+	hello, ok := pkt.(*protocol.Hello)
+	if !ok {
+		return nil, fmt.Errorf("expected hello, got packet %d", pkt.ID())
+	}
+	reject := func(format string, args ...any) (*Player, error) {
+		reason := fmt.Sprintf(format, args...)
+		_ = conn.WritePacket(&protocol.Disconnect{Reason: reason})
+		return nil, errors.New(reason)
+	}
+	if hello.Version != protocol.Version {
+		return reject("protocol version %d is not supported, server is on %d", hello.Version, protocol.Version)
+	}
+	if subtle.ConstantTimeCompare([]byte(hello.Token), []byte(s.cfg.Password)) != 1 {
+		return reject("wrong password")
+	}
+	if !save.ValidName.MatchString(hello.Name) || len(hello.Name) > maxNameLength {
+		return reject("name must be 1 to %d letters, digits or underscores", maxNameLength)
+	}
+	saved := s.loadPlayer(hello.Name)
+
+	s.mu.Lock()
+	if s.cfg.MaxPlayers > 0 && len(s.players) >= s.cfg.MaxPlayers {
+		s.mu.Unlock()
+		return reject("server is full")
+	}
+	for _, p := range s.players {
+		if p.name == hello.Name {
+			s.mu.Unlock()
+			return reject("%s is already online", hello.Name)
+		}
+	}
+	p := newPlayer(s, conn, s.nextID, hello.Name, s.cfg.Spawn, saved)
+	s.nextID++
+	others := make([]*Player, 0, len(s.players))
+	for _, o := range s.players {
+		others = append(others, o)
+	}
+	s.players[p.ID] = p
+	s.mu.Unlock()
+
+	p.send(&protocol.Welcome{PlayerID: p.ID, Spawn: s.cfg.Spawn, Seed: s.world.Seed()})
+	p.send(&protocol.GameMode{Mode: saved.GameMode})
+	p.send(&protocol.Health{Health: int32(saved.Health)})
+	join := &protocol.PlayerJoin{PlayerID: p.ID, Name: p.name, Pos: s.cfg.Spawn}
+	for _, o := range others {
+		p.send(&protocol.PlayerJoin{PlayerID: o.ID, Name: o.name, Pos: o.Pos()})
+		o.send(join)
+	}
+	return p, nil
+}
+
+func (s *Server) handle(ctx context.Context, conn *protocol.Conn) {
+	defer conn.Close()
+	p, err := s.handshake(conn)
+	if err != nil {
+		glog.Infof("Rejected %v: %v", conn.NetConn().RemoteAddr(), err)
+		return
+	}
+	glog.Infof("%s joined from %v", p.name, conn.NetConn().RemoteAddr())
+	defer s.remove(p)
+	go p.writeLoop()
+	p.updateChunks()
+	if err := p.readLoop(ctx); err != nil {
+		glog.Infof("%s disconnected: %v", p.name, err)
+	}
+}
+
+func (s *Server) remove(p *Player) {
+	s.mu.Lock()
+	delete(s.players, p.ID)
+	s.mu.Unlock()
+	p.close()
+	s.savePlayer(p)
+	s.broadcast(&protocol.PlayerLeave{PlayerID: p.ID}, p.ID)
+}
+
+// broadcast sends pkt to everyone except the player with ID skip.
+func (s *Server) broadcast(pkt protocol.Packet, skip uint32) {
+	for _, p := range s.Players() {
+		if p.ID != skip {
+			p.send(pkt)
+		}
+	}
+}
+
+func (s *Server) broadcastBlockChange(c world.BlockChange) {
+	pkt := &protocol.BlockChange{Pos: c.Pos, Block: c.New}
+	for _, p := range s.Players() {
+		p.sendBlockChange(pkt)
+	}
+}
@@ -0,0 +1,760 @@
+package server
+
+import (
+	"context"
+	"math"
+	"net"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dragon1672/go-mine/minecraft/client"
+	"github.com/dragon1672/go-mine/minecraft/command"
+	"github.com/dragon1672/go-mine/minecraft/gamemode"
+	"github.com/dragon1672/go-mine/minecraft/items"
+	"github.com/dragon1672/go-mine/minecraft/physics"
+	"github.com/dragon1672/go-mine/minecraft/protocol"
+	"github.com/dragon1672/go-mine/minecraft/protocol/javaping"
+	"github.com/dragon1672/go-mine/minecraft/protocol/netsim"
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+	"github.com/dragon1672/go-mine/minecraft/world/save"
+)
+
+func startServer(t *testing.T, cfg Config) (*Server, string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() = %v", err)
+	}
+	return serve(t, ln, cfg)
+}
+
+func serve(t *testing.T, ln net.Listener, cfg Config) (*Server, string) {
+	t.Helper()
+	w := world.New(1)
+	reg := command.NewRegistry()
+	if err := command.RegisterCore(reg, w); err != nil {
+		t.Fatalf("RegisterCore() = %v", err)
+	}
+	s := New(w, reg, cfg)
+	if err := RegisterCommands(reg, s); err != nil {
+		t.Fatalf("RegisterCommands() = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx, ln) }()
+	// tick faster than real time so tests don't wait on chunk streaming
+	ticked := make(chan struct{})
+	go func() {
+		defer close(ticked)
+		tick := time.NewTicker(5 * time.Millisecond)
+		defer tick.Stop()
+		for {
+			select {
+			case <-tick.C:
+				s.Tick()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-ticked
+		if err := <-done; err != nil {
+			t.Errorf("Serve() = %v", err)
+		}
+	})
+	return s, ln.Addr().String()
+}
+
+func testConfig() Config {
+	cfg := DefaultConfig()
+	cfg.ViewDistance = 1
+	cfg.VerticalViewDistance = 1
+	return cfg
+}
+
+func dial(t *testing.T, ctx context.Context, addr, name, token string) *client.Client {
+	t.Helper()
+	c, err := client.Dial(ctx, addr, name, token)
+	if err != nil {
+		t.Fatalf("Dial(%s) = %v", name, err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestTwoPlayers(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cfg := testConfig()
+	cfg.Ops = []string{"alex"}
+	// creative so edits don't need items or mining time
+	cfg.GameMode = gamemode.Creative
+	s, addr := startServer(t, cfg)
+
+	alex := dial(t, ctx, addr, "alex", "")
+	steve := dial(t, ctx, addr, "steve", "")
+	seesOther := func(c *client.Client) bool { return len(c.Players()) == 1 }
+	if err := alex.WaitFor(ctx, seesOther); err != nil {
+		t.Fatalf("alex never saw steve join: %v", err)
+	}
+	if err := steve.WaitFor(ctx, seesOther); err != nil {
+		t.Fatalf("steve never saw alex: %v", err)
+	}
+	if got := steve.Players()[0].Name; got != "alex" {
+		t.Errorf("steve sees %q, want alex", got)
+	}
+	if got := alex.Seed(); got != 1 {
+		t.Errorf("Seed() = %d, want 1", got)
+	}
+
+	// 3x3x3 chunks around spawn
+	full := func(c *client.Client) bool { return c.ChunkCount() == 27 }
+	for _, c := range []*client.Client{alex, steve} {
+		if err := c.WaitFor(ctx, full); err != nil {
+			t.Fatalf("chunks never arrived, have %d: %v", c.ChunkCount(), err)
+		}
+	}
+
+	t.Run("block changes", func(t *testing.T) {
+		pos := vec.IntVec3{X: 1, Y: 70, Z: 1}
+		if err := alex.SetBlock(pos, blocks.Stone); err != nil {
+			t.Fatalf("SetBlock() = %v", err)
+		}
+		isStone := func(c *client.Client) bool {
+			b, _ := c.BlockAt(pos)
+			return b == blocks.Stone
+		}
+		for _, c := range []*client.Client{alex, steve} {
+			if err := c.WaitFor(ctx, isStone); err != nil {
+				t.Errorf("block change never arrived: %v", err)
+			}
+		}
+		if got := s.World().BlockAt(pos); got != blocks.Stone {
+			t.Errorf("server BlockAt() = %v, want Stone", got)
+		}
+	})
+
+	t.Run("out of reach", func(t *testing.T) {
+		pos := vec.IntVec3{X: 10, Y: 75, Z: 10}
+		before := s.World().BlockAt(pos)
+		if err := steve.SetBlock(pos, blocks.Planks); err != nil {
+			t.Fatalf("SetBlock() = %v", err)
+		}
+		// a chat round trip means the server has handled the edit
+		if err := steve.Chat("sync"); err != nil {
+			t.Fatalf("Chat() = %v", err)
+		}
+		if err := steve.WaitFor(ctx, func(c *client.Client) bool { return len(c.Messages()) > 0 }); err != nil {
+			t.Fatalf("chat never arrived: %v", err)
+		}
+		if got := s.World().BlockAt(pos); got != before {
+			t.Errorf("BlockAt() = %v after out of reach edit, want %v", got, before)
+		}
+	})
+
+	t.Run("positions", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			if err := steve.Step(physics.Input{Forward: 1}); err != nil {
+				t.Fatalf("Step() = %v", err)
+			}
+		}
+		if err := steve.WaitFor(ctx, func(c *client.Client) bool { return c.PendingInputs() == 0 }); err != nil {
+			t.Fatalf("inputs never acknowledged: %v", err)
+		}
+		to := s.Players()[1].Pos()
+		if got := steve.Pos(); got != to {
+			t.Errorf("steve predicted %v, server has %v", got, to)
+		}
+		if err := alex.WaitFor(ctx, func(c *client.Client) bool { return c.Players()[0].Pos == to }); err != nil {
+			t.Errorf("alex never saw steve move: %v", err)
+		}
+	})
+
+	t.Run("commands", func(t *testing.T) {
+		if err := alex.Chat("/seed"); err != nil {
+			t.Fatalf("Chat() = %v", err)
+		}
+		hasSeed := func(c *client.Client) bool {
+			for _, m := range c.Messages() {
+				if m == "Seed: 1" {
+					return true
+				}
+			}
+			return false
+		}
+		if err := alex.WaitFor(ctx, hasSeed); err != nil {
+			t.Errorf("no reply to /seed, got %q: %v", alex.Messages(), err)
+		}
+		// steve isn't an op
+		if err := steve.Chat("/seed"); err != nil {
+			t.Fatalf("Chat() = %v", err)
+		}
+		denied := func(c *client.Client) bool {
+			for _, m := range c.Messages() {
+				if strings.Contains(m, "permission denied") {
+					return true
+				}
+			}
+			return false
+		}
+		if err := steve.WaitFor(ctx, denied); err != nil {
+			t.Errorf("steve ran /seed, got %q: %v", steve.Messages(), err)
+		}
+	})
+
+	t.Run("long chat", func(t *testing.T) {
+		// with the name in front this would overflow the string length if broadcast
+		if err := alex.Chat(strings.Repeat("a", math.MaxUint16)); err != nil {
+			t.Fatalf("Chat() = %v", err)
+		}
+		tooLong := func(c *client.Client) bool {
+			for _, m := range c.Messages() {
+				if strings.Contains(m, "too long") {
+					return true
+				}
+			}
+			return false
+		}
+		if err := alex.WaitFor(ctx, tooLong); err != nil {
+			t.Errorf("no reply to a long chat, got %q: %v", alex.Messages(), err)
+		}
+		// steve is still connected and hears the next message
+		if err := alex.Chat("still here"); err != nil {
+			t.Fatalf("Chat() = %v", err)
+		}
+		heard := func(c *client.Client) bool {
+			for _, m := range c.Messages() {
+				if m == "<alex> still here" {
+					return true
+				}
+			}
+			return false
+		}
+		if err := steve.WaitFor(ctx, heard); err != nil {
+			t.Errorf("steve never heard alex, got %q: %v", steve.Messages(), err)
+		}
+	})
+
+	t.Run("leave", func(t *testing.T) {
+		steve.Close()
+		if err := alex.WaitFor(ctx, func(c *client.Client) bool { return len(c.Players()) == 0 }); err != nil {
+			t.Errorf("alex never saw steve leave: %v", err)
+		}
+	})
+}
+
+// runClock advances clock in step every millisecond until cond holds, so a link's delay
+// only passes while the test waits on it.
+func runClock(ctx context.Context, clock *netsim.ManualClock, step time.Duration, cond func() bool) error {
+	for !cond() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Millisecond):
+			clock.Advance(step)
+		}
+	}
+	return nil
+}
+
+func TestPredictionWithLatency(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	clock := netsim.NewManualClock(time.Unix(0, 0))
+	link := netsim.Link{Latency: 50 * time.Millisecond, Jitter: 20 * time.Millisecond, Seed: 1, Clock: clock}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() = %v", err)
+	}
+	s, addr := serve(t, &netsim.Listener{Listener: ln, Link: link}, testConfig())
+	nc, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial() = %v", err)
+	}
+	type login struct {
+		c   *client.Client
+		err error
+	}
+	done := make(chan login, 1)
+	go func() {
+		c, err := client.Login(netsim.Wrap(nc, link), "alex", "")
+		done <- login{c, err}
+	}()
+	var l login
+	if err := runClock(ctx, clock, 5*time.Millisecond, func() bool {
+		select {
+		case l = <-done:
+			return true
+		default:
+			return false
+		}
+	}); err != nil {
+		t.Fatalf("never logged in: %v", err)
+	}
+	if l.err != nil {
+		t.Fatalf("Login() = %v", l.err)
+	}
+	c := l.c
+	defer c.Close()
+	if err := runClock(ctx, clock, 5*time.Millisecond, func() bool { return c.ChunkCount() == 27 }); err != nil {
+		t.Fatalf("chunks never arrived: %v", err)
+	}
+
+	start := c.Pos()
+	in := physics.Input{Strafe: 1}
+	for i := 0; i < 10; i++ {
+		if err := c.Step(in); err != nil {
+			t.Fatalf("Step() = %v", err)
+		}
+	}
+	// the link's clock is stopped, so none of these can have been acknowledged yet
+	if got := c.PendingInputs(); got != 10 {
+		t.Errorf("PendingInputs() = %d straight after sending, want 10", got)
+	}
+	if c.Pos() == start {
+		t.Errorf("Pos() didn't move until the server answered")
+	}
+	clock.Advance(link.Latency - time.Millisecond)
+	if got := c.PendingInputs(); got != 10 {
+		t.Errorf("PendingInputs() = %d before a round trip, want 10", got)
+	}
+	if err := runClock(ctx, clock, 5*time.Millisecond, func() bool { return c.PendingInputs() == 0 }); err != nil {
+		t.Fatalf("inputs never acknowledged: %v", err)
+	}
+	if got, want := c.Pos(), s.Players()[0].Pos(); got != want {
+		t.Errorf("predicted %v, server has %v", got, want)
+	}
+}
+
+func TestInputRateLimit(t *testing.T) {
+	t.Parallel()
+	s := New(world.New(1), command.NewRegistry(), testConfig())
+	spectator := save.Player{GameMode: gamemode.Spectator, Health: gamemode.MaxHealth}
+	flood := newPlayer(s, nil, 1, "flood", s.cfg.Spawn, spectator)
+	steady := newPlayer(s, nil, 2, "steady", s.cfg.Spawn, spectator)
+	in := physics.Input{Strafe: 1}
+	for seq := uint32(1); seq <= 10*inputBurst; seq++ {
+		in.Seq = seq
+		flood.handleInput(in)
+		if seq <= inputBurst {
+			steady.handleInput(in)
+		}
+	}
+	if steady.Pos() == s.cfg.Spawn {
+		t.Fatalf("inputs didn't move the player")
+	}
+	if got, want := flood.Pos(), steady.Pos(); got != want {
+		t.Errorf("after a flood of inputs at %v, want %v as if only %d ran", got, want, inputBurst)
+	}
+	// every input is still acknowledged so the client's prediction snaps back
+	if got := len(flood.out); got != 10*inputBurst {
+		t.Errorf("flood got %d PlayerStates, want %d", got, 10*inputBurst)
+	}
+
+	flood.refillInputs()
+	steady.refillInputs()
+	in.Seq = 10*inputBurst + 1
+	flood.handleInput(in)
+	steady.handleInput(in)
+	if got, want := flood.Pos(), steady.Pos(); got != want {
+		t.Errorf("after a tick at %v, want %v", got, want)
+	}
+}
+
+func TestSpiral(t *testing.T) {
+	t.Parallel()
+	got := spiral(2, 1)
+	if len(got) != 5*5*3 {
+		t.Fatalf("spiral(2, 1) has %d offsets, want 75", len(got))
+	}
+	if got[0] != (vec.IntVec3{}) {
+		t.Errorf("spiral starts at %v, want the centre", got[0])
+	}
+	seen := map[vec.IntVec3]bool{}
+	ring := 0
+	for _, off := range got {
+		if seen[off] {
+			t.Errorf("%v repeated", off)
+		}
+		seen[off] = true
+		r := abs(off.X)
+		if abs(off.Z) > r {
+			r = abs(off.Z)
+		}
+		if r < ring {
+			t.Errorf("%v in ring %d comes after ring %d", off, r, ring)
+		}
+		ring = r
+	}
+}
+
+func TestChunkStreaming(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cfg := testConfig()
+	cfg.Ops = []string{"alex"}
+	cfg.ChunksPerTick = 2
+	cfg.GameMode = gamemode.Creative
+	s, addr := startServer(t, cfg)
+	store := save.NewChunkStore(t.TempDir())
+	s.World().SetStorage(store)
+
+	c := dial(t, ctx, addr, "alex", "")
+	if err := c.WaitFor(ctx, func(c *client.Client) bool { return c.ChunkCount() == 27 }); err != nil {
+		t.Fatalf("chunks never arrived: %v", err)
+	}
+	if st := s.Stats()[0]; st.ChunkQueue != 0 || st.ChunksLoaded != 27 || st.ChunksSent != 27 {
+		t.Errorf("Stats() = %+v, want 27 chunks sent and none queued", st)
+	}
+
+	edit := vec.IntVec3{X: 2, Y: 66, Z: 2}
+	if err := c.SetBlock(edit, blocks.Planks); err != nil {
+		t.Fatalf("SetBlock() = %v", err)
+	}
+	if err := c.WaitFor(ctx, func(c *client.Client) bool { b, _ := c.BlockAt(edit); return b == blocks.Planks }); err != nil {
+		t.Fatalf("edit never arrived: %v", err)
+	}
+	home, _ := world.ChunkOf(edit)
+
+	if err := c.Chat("/tp 1000 64 0"); err != nil {
+		t.Fatalf("Chat() = %v", err)
+	}
+	away := vec.IntVec3{X: 62, Y: 4, Z: 0}
+	moved := func(c *client.Client) bool { return c.HasChunk(away) && !c.HasChunk(home) && c.ChunkCount() == 27 }
+	if err := c.WaitFor(ctx, moved); err != nil {
+		t.Fatalf("chunks never followed the teleport, have %d: %v", c.ChunkCount(), err)
+	}
+	for s.World().IsLoaded(home) {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("chunk %v was never unloaded", home)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	saved, ok, err := store.LoadChunk(home)
+	if err != nil || !ok {
+		t.Fatalf("LoadChunk(%v) = %v, %v, want the saved chunk", home, ok, err)
+	}
+	_, local := world.ChunkOf(edit)
+	if got := saved.Get(local); got != blocks.Planks {
+		t.Errorf("saved chunk has %v, want Planks", got)
+	}
+	if got := s.World().BlockAt(edit); got != blocks.Planks {
+		t.Errorf("BlockAt() after reload = %v, want Planks", got)
+	}
+}
+
+func TestBlockChangesWhileStreaming(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cfg := testConfig()
+	cfg.ChunksPerTick = 1
+	s, addr := startServer(t, cfg)
+	w := s.World()
+
+	c := dial(t, ctx, addr, "alex", "")
+	if err := c.WaitFor(ctx, func(c *client.Client) bool { return c.ID() != 0 }); err != nil {
+		t.Fatalf("never joined: %v", err)
+	}
+	p := s.Players()[0]
+	// edit a layer of each chunk as soon as the server starts sending it, which lands
+	// while it's being snapshotted and encoded
+	spawn, _ := world.ChunkOf(cfg.Spawn.Floor())
+	var edits []vec.IntVec3
+	for _, off := range spiral(1, 1) {
+		chunk := spawn.Add(off)
+		for !p.hasChunk(chunk) {
+			if ctx.Err() != nil {
+				t.Fatalf("chunk %v was never sent", chunk)
+			}
+			runtime.Gosched()
+		}
+		for x := 0; x < world.ChunkSize; x++ {
+			for z := 0; z < world.ChunkSize; z++ {
+				pos := world.ChunkOrigin(chunk).Add(vec.IntVec3{X: x, Y: 1, Z: z})
+				w.SetBlock(pos, blocks.Planks)
+				edits = append(edits, pos)
+			}
+		}
+	}
+	if err := c.WaitFor(ctx, func(c *client.Client) bool { return c.ChunkCount() == 27 }); err != nil {
+		t.Fatalf("chunks never arrived: %v", err)
+	}
+	// changes arrive in order, so once this one has every earlier one has too
+	last := edits[0].Add(vec.IntVec3{Y: 1})
+	w.SetBlock(last, blocks.Wood)
+	if err := c.WaitFor(ctx, func(c *client.Client) bool { b, _ := c.BlockAt(last); return b == blocks.Wood }); err != nil {
+		t.Fatalf("last edit never arrived: %v", err)
+	}
+	lost := 0
+	for _, pos := range edits {
+		if b, _ := c.BlockAt(pos); b != blocks.Planks {
+			lost++
+		}
+	}
+	if lost > 0 {
+		t.Errorf("client lost %d of %d block changes made while chunks streamed", lost, len(edits))
+	}
+}
+
+func TestDedicatedDayLength(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	// a done context saves and returns as soon as the server is up
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	run := func(args ...string) save.Level {
+		t.Helper()
+		args = append([]string{"-addr", "127.0.0.1:0", "-save_dir", dir}, args...)
+		if err := RunDedicated(ctx, args); err != nil {
+			t.Fatalf("RunDedicated(%v) = %v", args, err)
+		}
+		l, ok, err := save.ReadLevel(dir)
+		if err != nil || !ok {
+			t.Fatalf("ReadLevel() = %v, %v", ok, err)
+		}
+		return l
+	}
+	if got := run("-day_length", "1200").DayLength; got != 1200 {
+		t.Errorf("new level day length = %d, want 1200", got)
+	}
+	if got := run().DayLength; got != 1200 {
+		t.Errorf("reloaded level day length = %d, want 1200", got)
+	}
+}
+
+func TestGameModes(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	cfg := testConfig()
+	cfg.Ops = []string{"alex"}
+	s, addr := startServer(t, cfg)
+	s.SetPlayerStore(save.NewPlayerStore(t.TempDir()))
+
+	c := dial(t, ctx, addr, "alex", "")
+	chat := func(c *client.Client, msg string) {
+		t.Helper()
+		if err := c.Chat(msg); err != nil {
+			t.Fatalf("Chat(%q) = %v", msg, err)
+		}
+	}
+	mode := func(m gamemode.Mode) func(c *client.Client) bool {
+		return func(c *client.Client) bool { return c.GameMode() == m }
+	}
+	if c.GameMode() != gamemode.Survival || c.Health() != gamemode.MaxHealth {
+		t.Errorf("new player has %v with %d health, want survival with full health", c.GameMode(), c.Health())
+	}
+
+	chat(c, "/gamemode spectator")
+	if err := c.WaitFor(ctx, mode(gamemode.Spectator)); err != nil {
+		t.Fatalf("mode never changed: %v", err)
+	}
+	edit := vec.IntVec3{X: 1, Y: 64, Z: 1}
+	if err := c.SetBlock(edit, blocks.Planks); err != nil {
+		t.Fatalf("SetBlock() = %v", err)
+	}
+	// packets are handled in order, so the edit has been dealt with once the mode changes
+	chat(c, "/gamemode creative")
+	if err := c.WaitFor(ctx, mode(gamemode.Creative)); err != nil {
+		t.Fatalf("mode never changed: %v", err)
+	}
+	if s.World().BlockAt(edit) == blocks.Planks {
+		t.Errorf("spectator was able to place a block")
+	}
+
+	// the mode is remembered after leaving
+	c.Close()
+	for len(s.Players()) > 0 {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("alex never left")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	c = dial(t, ctx, addr, "alex", "")
+	if err := c.WaitFor(ctx, mode(gamemode.Creative)); err != nil {
+		t.Fatalf("mode was not restored: %v", err)
+	}
+
+	// survival players take fall damage, this fall is far enough to respawn them
+	chat(c, "/gamemode survival")
+	chat(c, "/tp 0 200 0")
+	if err := c.WaitFor(ctx, func(c *client.Client) bool { return c.GameMode() == gamemode.Survival && c.Pos().Y == 200 }); err != nil {
+		t.Fatalf("never got to the sky: %v", err)
+	}
+	died := func(c *client.Client) bool {
+		msgs := c.Messages()
+		return len(msgs) > 0 && msgs[len(msgs)-1] == "alex died"
+	}
+	// step at the server's tick rate, inputs sent any faster are dropped
+	tick := time.NewTicker(5 * time.Millisecond)
+	defer tick.Stop()
+	for !died(c) {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("fall never killed alex, messages %q", c.Messages())
+		case <-tick.C:
+		}
+		if err := c.Step(physics.Input{}); err != nil {
+			t.Fatalf("Step() = %v", err)
+		}
+	}
+	if c.Health() != gamemode.MaxHealth {
+		t.Errorf("respawned with %d health, want %d", c.Health(), gamemode.MaxHealth)
+	}
+}
+
+func TestLoadPlayerHealth(t *testing.T) {
+	t.Parallel()
+	s := New(world.New(1), command.NewRegistry(), testConfig())
+	store := save.NewPlayerStore(t.TempDir())
+	s.SetPlayerStore(store)
+	for _, tc := range []struct {
+		saved, want int
+	}{
+		{saved: -3, want: 1},
+		{saved: 0, want: 1},
+		{saved: 7, want: 7},
+		{saved: gamemode.MaxHealth + 100, want: gamemode.MaxHealth},
+	} {
+		if err := store.SavePlayer("alex", save.Player{GameMode: gamemode.Survival, Health: tc.saved}); err != nil {
+			t.Fatalf("SavePlayer() = %v", err)
+		}
+		if got := s.loadPlayer("alex").Health; got != tc.want {
+			t.Errorf("saved health %d loaded as %d, want %d", tc.saved, got, tc.want)
+		}
+	}
+}
+
+func TestSurvivalInteraction(t *testing.T) {
+	t.Parallel()
+	s := New(world.New(1), command.NewRegistry(), testConfig())
+	w := s.World()
+	p := newPlayer(s, nil, 1, "alex", vec.Vec3{X: 0.5, Y: 64, Z: 0.5}, save.Player{GameMode: gamemode.Survival, Health: gamemode.MaxHealth})
+	dirt := vec.IntVec3{X: 0, Y: 64, Z: 2}
+	above := dirt.Add(vec.IntVec3{Y: 1})
+	chunk, _ := world.ChunkOf(dirt)
+	p.sentChunks[chunk] = true
+	w.SetBlock(dirt, blocks.Dirt)
+	w.SetBlock(above, blocks.Air)
+
+	p.handleBlockChange(&protocol.BlockChange{Pos: above, Block: blocks.Planks})
+	if got := w.BlockAt(above); got != blocks.Air {
+		t.Errorf("placed %v without any items", got)
+	}
+
+	p.handleBlockChange(&protocol.BlockChange{Pos: dirt, Block: blocks.Air})
+	if got := w.BlockAt(dirt); got != blocks.Dirt {
+		t.Fatalf("dirt broke instantly in survival, have %v", got)
+	}
+	ticks := 0
+	for ; w.BlockAt(dirt) == blocks.Dirt && ticks < 100; ticks++ {
+		p.mine()
+	}
+	if w.BlockAt(dirt) != blocks.Air || ticks < 2 {
+		t.Errorf("dirt took %d ticks to break, now %v", ticks, w.BlockAt(dirt))
+	}
+	if got := p.inventory.Slot(0); got != items.NewStack(items.BlockID(blocks.Dirt), 1) {
+		t.Errorf("broken dirt dropped %v, want 1 dirt", got)
+	}
+
+	p.handleBlockChange(&protocol.BlockChange{Pos: dirt, Block: blocks.Dirt})
+	if got := w.BlockAt(dirt); got != blocks.Dirt {
+		t.Errorf("placing the dirt back left %v", got)
+	}
+	if !p.inventory.Slot(0).IsEmpty() {
+		t.Errorf("placing used nothing, have %v", p.inventory.Slot(0))
+	}
+
+	p.SetGameMode(gamemode.Creative)
+	p.handleBlockChange(&protocol.BlockChange{Pos: dirt, Block: blocks.Air})
+	p.handleBlockChange(&protocol.BlockChange{Pos: above, Block: blocks.Planks})
+	if w.BlockAt(dirt) != blocks.Air || w.BlockAt(above) != blocks.Planks {
+		t.Errorf("creative edits left %v and %v, want instant break and free placement", w.BlockAt(dirt), w.BlockAt(above))
+	}
+}
+
+func TestJavaPing(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cfg := testConfig()
+	cfg.Motd = "test server"
+	_, addr := startServer(t, cfg)
+	dial(t, ctx, addr, "alex", "")
+
+	st, _, err := javaping.Ping(ctx, addr)
+	if err != nil {
+		t.Fatalf("Ping() = %v", err)
+	}
+	if st.Description.Text != "test server" || st.Players.Online != 1 || st.Players.Max != cfg.MaxPlayers {
+		t.Errorf("Ping() = %+v", st)
+	}
+	if len(st.Players.Sample) != 1 || st.Players.Sample[0].Name != "alex" {
+		t.Errorf("player sample = %+v, want alex", st.Players.Sample)
+	}
+	// go-mine clients still get in on the same port
+	dial(t, ctx, addr, "steve", "")
+}
+
+func TestRejected(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cfg := testConfig()
+	cfg.Password = "hunter2"
+	_, addr := startServer(t, cfg)
+
+	if _, err := client.Dial(ctx, addr, "alex", "wrong"); err == nil || !strings.Contains(err.Error(), "wrong password") {
+		t.Errorf("Dial() with bad password = %v, want wrong password", err)
+	}
+	c := dial(t, ctx, addr, "alex", "hunter2")
+	if _, err := client.Dial(ctx, addr, "alex", "hunter2"); err == nil || !strings.Contains(err.Error(), "already online") {
+		t.Errorf("Dial() with duplicate name = %v, want already online", err)
+	}
+	if _, err := client.Dial(ctx, addr, "../alex", "hunter2"); err == nil || !strings.Contains(err.Error(), "letters") {
+		t.Errorf("Dial() with a path for a name = %v, want refused", err)
+	}
+	c.Close()
+
+	t.Run("full", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.MaxPlayers = 1
+		_, addr := startServer(t, cfg)
+		dial(t, ctx, addr, "alex", "")
+		if _, err := client.Dial(ctx, addr, "steve", ""); err == nil || !strings.Contains(err.Error(), "full") {
+			t.Errorf("Dial() to a full server = %v, want server is full", err)
+		}
+	})
+
+	t.Run("version mismatch", func(t *testing.T) {
+		nc, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatalf("Dial() = %v", err)
+		}
+		conn := protocol.NewConn(nc)
+		defer conn.Close()
+		if err := conn.WritePacket(&protocol.Hello{Version: protocol.Version + 1, Name: "old", Token: "hunter2"}); err != nil {
+			t.Fatalf("WritePacket() = %v", err)
+		}
+		pkt, err := conn.ReadPacket()
+		if err != nil {
+			t.Fatalf("ReadPacket() = %v", err)
+		}
+		d, ok := pkt.(*protocol.Disconnect)
+		if !ok || !strings.Contains(d.Reason, "version") {
+			t.Errorf("got %#v, want a version Disconnect", pkt)
+		}
+	})
+}
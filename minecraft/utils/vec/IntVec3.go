@@ -22,3 +22,14 @@ func (v IntVec3) Front() IntVec3 {
 func (v IntVec3) Back() IntVec3 {
 	return IntVec3{v.X, v.Y, v.Z - 1}
 }
+func (v IntVec3) Add(t IntVec3) IntVec3 {
+	return IntVec3{v.X + t.X, v.Y + t.Y, v.Z + t.Z}
+}
+func (v IntVec3) Sub(t IntVec3) IntVec3 {
+	return IntVec3{v.X - t.X, v.Y - t.Y, v.Z - t.Z}
+}
+
+// Center returns the middle of the block at v.
+func (v IntVec3) Center() Vec3 {
+	return Vec3{X: float64(v.X) + 0.5, Y: float64(v.Y) + 0.5, Z: float64(v.Z) + 0.5}
+}
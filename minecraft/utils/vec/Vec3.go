@@ -1,5 +1,7 @@
 package vec
 
+import "math"
+
 type Vec3 struct {
 	X, Y, Z float64
 }
@@ -11,3 +13,16 @@ func (v Vec3) Add(t Vec3) Vec3 {
 func (v Vec3) Mul(f float64) Vec3 {
 	return Vec3{X: v.X * f, Y: v.Y * f, Z: v.Z * f}
 }
+
+func (v Vec3) Sub(t Vec3) Vec3 {
+	return Vec3{X: v.X - t.X, Y: v.Y - t.Y, Z: v.Z - t.Z}
+}
+
+func (v Vec3) Length() float64 {
+	return math.Sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z)
+}
+
+// Floor returns the block containing v.
+func (v Vec3) Floor() IntVec3 {
+	return IntVec3{X: int(math.Floor(v.X)), Y: int(math.Floor(v.Y)), Z: int(math.Floor(v.Z))}
+}
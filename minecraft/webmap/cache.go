@@ -0,0 +1,55 @@
+package webmap
+
+import (
+	"container/list"
+	"image"
+)
+
+type tileKey struct {
+	zoom, x, z int
+}
+
+type tile struct {
+	key   tileKey
+	img   *image.RGBA
+	stamp uint64
+	png   []byte
+}
+
+// tileCache is a least recently used cache of rendered tiles. It is not safe for concurrent use.
+type tileCache struct {
+	size  int
+	order *list.List
+	tiles map[tileKey]*list.Element
+}
+
+func newTileCache(size int) *tileCache {
+	return &tileCache{size: size, order: list.New(), tiles: map[tileKey]*list.Element{}}
+}
+
+func (c *tileCache) get(k tileKey) (*tile, bool) {
+	e, ok := c.tiles[k]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*tile), true
+}
+
+func (c *tileCache) put(t *tile) {
+	if e, ok := c.tiles[t.key]; ok {
+		e.Value = t
+		c.order.MoveToFront(e)
+		return
+	}
+	c.tiles[t.key] = c.order.PushFront(t)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.tiles, oldest.Value.(*tile).key)
+	}
+}
+
+func (c *tileCache) len() int {
+	return c.order.Len()
+}
@@ -0,0 +1,174 @@
+package webmap
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"image"
+	"image/color"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// TileSize is the width of a tile in pixels. At zoom 0 each pixel is one block.
+const TileSize = 128
+
+// chunksPerTile is how many chunks a zoom 0 tile spans along each axis.
+const chunksPerTile = TileSize / world.ChunkSize
+
+var blockColors = map[blocks.SimpleBlockType]color.RGBA{
+	blocks.Grass:  {R: 0x5d, G: 0x9c, B: 0x3b, A: 0xff},
+	blocks.Sand:   {R: 0xdb, G: 0xd3, B: 0x9f, A: 0xff},
+	blocks.Dirt:   {R: 0x86, G: 0x60, B: 0x43, A: 0xff},
+	blocks.Stone:  {R: 0x7f, G: 0x7f, B: 0x7f, A: 0xff},
+	blocks.Leaves: {R: 0x3a, G: 0x6b, B: 0x28, A: 0xff},
+	blocks.Wood:   {R: 0x66, G: 0x51, B: 0x32, A: 0xff},
+	blocks.Flower: {R: 0xd8, G: 0x3a, B: 0x3a, A: 0xff},
+	blocks.Water:  {R: 0x3f, G: 0x76, B: 0xe4, A: 0xff},
+	blocks.Lava:   {R: 0xe2, G: 0x5a, B: 0x10, A: 0xff},
+	blocks.Planks: {R: 0xb8, G: 0x94, B: 0x5f, A: 0xff},
+}
+
+// unknownColor is for block types added without a map colour.
+var unknownColor = color.RGBA{R: 0xff, G: 0x00, B: 0xff, A: 0xff}
+
+func blockColor(b blocks.SimpleBlockType) color.RGBA {
+	if c, ok := blockColors[b]; ok {
+		return c
+	}
+	return unknownColor
+}
+
+// shade darkens low ground and lightens high ground so hills read on a flat map.
+func shade(c color.RGBA, y, minY, maxY int) color.RGBA {
+	f := 0.7 + 0.5*float64(y-minY)/float64(maxY-minY+1)
+	scale := func(v uint8) uint8 {
+		return uint8(min(255, float64(v)*f))
+	}
+	return color.RGBA{R: scale(c.R), G: scale(c.G), B: scale(c.B), A: c.A}
+}
+
+// chunkRange is the chunk Y range holding blocks between minY and maxY.
+func (m *Map) chunkRange() (lo, hi int) {
+	l, _ := world.ChunkOf(vec.IntVec3{Y: m.cfg.MinY})
+	h, _ := world.ChunkOf(vec.IntVec3{Y: m.cfg.MaxY})
+	return l.Y, h.Y
+}
+
+// baseStamp hashes the versions of every loaded chunk under a zoom 0 tile.
+// It changes whenever a chunk under the tile is edited, loaded or unloaded.
+func (m *Map) baseStamp(tx, tz int) uint64 {
+	h := fnv.New64a()
+	lo, hi := m.chunkRange()
+	var buf []byte
+	for cz := tz * chunksPerTile; cz < (tz+1)*chunksPerTile; cz++ {
+		for cx := tx * chunksPerTile; cx < (tx+1)*chunksPerTile; cx++ {
+			for cy := lo; cy <= hi; cy++ {
+				pos := vec.IntVec3{X: cx, Y: cy, Z: cz}
+				m.world.ViewChunk(pos, func(c *world.Chunk) {
+					buf = binary.LittleEndian.AppendUint64(buf[:0], uint64(int64(cx)))
+					buf = binary.LittleEndian.AppendUint64(buf, uint64(int64(cy)))
+					buf = binary.LittleEndian.AppendUint64(buf, uint64(int64(cz)))
+					buf = binary.LittleEndian.AppendUint64(buf, c.Version)
+					h.Write(buf)
+				})
+			}
+		}
+	}
+	return h.Sum64()
+}
+
+// renderBase draws a zoom 0 tile from the top block of each column. Chunk columns with nothing
+// loaded keep their pixels from prev, so the map remembers areas nobody is near any more.
+func (m *Map) renderBase(tx, tz int, prev *image.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, TileSize, TileSize))
+	if prev != nil {
+		copy(img.Pix, prev.Pix)
+	}
+	lo, hi := m.chunkRange()
+	for i := 0; i < chunksPerTile; i++ {
+		for j := 0; j < chunksPerTile; j++ {
+			cx, cz := tx*chunksPerTile+j, tz*chunksPerTile+i
+			m.renderColumn(img, vec.IntVec3{X: cx, Z: cz}, j*world.ChunkSize, i*world.ChunkSize, lo, hi)
+		}
+	}
+	return img
+}
+
+// renderColumn draws one column of chunks at pixel offset px, pz.
+func (m *Map) renderColumn(img *image.RGBA, col vec.IntVec3, px, pz, lo, hi int) {
+	const n = world.ChunkSize
+	var found [n * n]bool
+	left := n * n
+	anyLoaded := false
+	for cy := hi; cy >= lo && left > 0; cy-- {
+		origin := world.ChunkOrigin(vec.IntVec3{X: col.X, Y: cy, Z: col.Z})
+		m.world.ViewChunk(vec.IntVec3{X: col.X, Y: cy, Z: col.Z}, func(c *world.Chunk) {
+			anyLoaded = true
+			for z := 0; z < n; z++ {
+				for x := 0; x < n; x++ {
+					if found[z*n+x] {
+						continue
+					}
+					for y := n - 1; y >= 0; y-- {
+						wy := origin.Y + y
+						if wy > m.cfg.MaxY || wy < m.cfg.MinY {
+							continue
+						}
+						b := c.Get(vec.IntVec3{X: x, Y: y, Z: z})
+						if b == blocks.Air {
+							continue
+						}
+						img.SetRGBA(px+x, pz+z, shade(blockColor(b), wy, m.cfg.MinY, m.cfg.MaxY))
+						found[z*n+x] = true
+						left--
+						break
+					}
+				}
+			}
+		})
+	}
+	if !anyLoaded {
+		return
+	}
+	// columns that are loaded but all air are cleared, something may have been dug out
+	for z := 0; z < n; z++ {
+		for x := 0; x < n; x++ {
+			if !found[z*n+x] {
+				img.SetRGBA(px+x, pz+z, color.RGBA{})
+			}
+		}
+	}
+}
+
+// downsample draws four child tiles, in the order top left, top right, bottom left,
+// bottom right, at half size into one tile. Nil children are left transparent.
+func downsample(children [4]*image.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, TileSize, TileSize))
+	const half = TileSize / 2
+	for i, child := range children {
+		if child == nil {
+			continue
+		}
+		ox, oy := (i%2)*half, (i/2)*half
+		for y := 0; y < half; y++ {
+			for x := 0; x < half; x++ {
+				// average the opaque pixels of the 2x2 block
+				var r, g, b, n int
+				for _, d := range [4][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+					c := child.RGBAAt(2*x+d[0], 2*y+d[1])
+					if c.A == 0 {
+						continue
+					}
+					r, g, b, n = r+int(c.R), g+int(c.G), b+int(c.B), n+1
+				}
+				if n == 0 {
+					continue
+				}
+				img.SetRGBA(ox+x, oy+y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: 0xff})
+			}
+		}
+	}
+	return img
+}
@@ -0,0 +1,156 @@
+// Top-down map viewer. Zoom level z draws 2^z blocks per pixel, tiles are fetched from
+// /tiles/{z}/{x}/{z}.png and revalidated every few seconds so edits show up live.
+(function () {
+  "use strict";
+
+  const TILE_REFRESH_MS = 5000;
+  const PLAYER_REFRESH_MS = 1000;
+
+  const map = document.getElementById("map");
+  const tileLayer = document.getElementById("tiles");
+  const markerLayer = document.getElementById("markers");
+  const coords = document.getElementById("coords");
+
+  const state = { tileSize: 128, maxZoom: 4, zoom: 1, x: 0, z: 0 };
+  const tiles = new Map(); // "zoom/x/z" -> img
+  let players = [];
+
+  const blocksPerPixel = () => 1 << state.zoom;
+
+  // screen position of a world position
+  function toScreen(x, z) {
+    const bpp = blocksPerPixel();
+    return [
+      map.clientWidth / 2 + (x - state.x) / bpp,
+      map.clientHeight / 2 + (z - state.z) / bpp,
+    ];
+  }
+
+  function toWorld(sx, sz) {
+    const bpp = blocksPerPixel();
+    return [
+      state.x + (sx - map.clientWidth / 2) * bpp,
+      state.z + (sz - map.clientHeight / 2) * bpp,
+    ];
+  }
+
+  async function load(img, url) {
+    try {
+      const resp = await fetch(url, { cache: "no-cache" });
+      if (!resp.ok) return;
+      const blob = await resp.blob();
+      const old = img.src;
+      img.src = URL.createObjectURL(blob);
+      if (old) URL.revokeObjectURL(old);
+    } catch (e) {
+      // the server may be restarting, the next refresh will try again
+    }
+  }
+
+  function tileURL(zoom, x, z) {
+    return "tiles/" + zoom + "/" + x + "/" + z + ".png";
+  }
+
+  function draw() {
+    const span = state.tileSize * blocksPerPixel();
+    const [x0, z0] = toWorld(0, 0);
+    const [x1, z1] = toWorld(map.clientWidth, map.clientHeight);
+    const wanted = new Set();
+    for (let tz = Math.floor(z0 / span); tz <= Math.floor(z1 / span); tz++) {
+      for (let tx = Math.floor(x0 / span); tx <= Math.floor(x1 / span); tx++) {
+        const key = state.zoom + "/" + tx + "/" + tz;
+        wanted.add(key);
+        let img = tiles.get(key);
+        if (!img) {
+          img = document.createElement("img");
+          img.width = img.height = state.tileSize;
+          img.dataset.url = tileURL(state.zoom, tx, tz);
+          tiles.set(key, img);
+          tileLayer.appendChild(img);
+          load(img, img.dataset.url);
+        }
+        const [sx, sz] = toScreen(tx * span, tz * span);
+        img.style.left = Math.round(sx) + "px";
+        img.style.top = Math.round(sz) + "px";
+      }
+    }
+    for (const [key, img] of tiles) {
+      if (!wanted.has(key)) {
+        if (img.src) URL.revokeObjectURL(img.src);
+        img.remove();
+        tiles.delete(key);
+      }
+    }
+    drawPlayers();
+  }
+
+  function drawPlayers() {
+    markerLayer.replaceChildren();
+    for (const p of players) {
+      const [sx, sz] = toScreen(p.x, p.z);
+      const m = document.createElement("div");
+      m.className = "marker";
+      m.style.left = sx + "px";
+      m.style.top = sz + "px";
+      const label = document.createElement("span");
+      label.textContent = p.name;
+      m.appendChild(label);
+      markerLayer.appendChild(m);
+    }
+  }
+
+  function setZoom(zoom) {
+    state.zoom = Math.max(0, Math.min(state.maxZoom, zoom));
+    draw();
+  }
+
+  let drag = null;
+  map.addEventListener("pointerdown", (e) => {
+    drag = { x: e.clientX, y: e.clientY };
+    map.classList.add("dragging");
+    map.setPointerCapture(e.pointerId);
+  });
+  map.addEventListener("pointermove", (e) => {
+    const [wx, wz] = toWorld(e.clientX, e.clientY);
+    coords.textContent = Math.floor(wx) + ", " + Math.floor(wz);
+    if (!drag) return;
+    state.x -= (e.clientX - drag.x) * blocksPerPixel();
+    state.z -= (e.clientY - drag.y) * blocksPerPixel();
+    drag = { x: e.clientX, y: e.clientY };
+    draw();
+  });
+  map.addEventListener("pointerup", () => {
+    drag = null;
+    map.classList.remove("dragging");
+  });
+  map.addEventListener("wheel", (e) => {
+    e.preventDefault();
+    setZoom(state.zoom + (e.deltaY > 0 ? 1 : -1));
+  }, { passive: false });
+  document.getElementById("zoom-in").onclick = () => setZoom(state.zoom - 1);
+  document.getElementById("zoom-out").onclick = () => setZoom(state.zoom + 1);
+  window.addEventListener("resize", draw);
+
+  async function refreshPlayers() {
+    try {
+      const resp = await fetch("players.json", { cache: "no-cache" });
+      players = await resp.json();
+      drawPlayers();
+    } catch (e) {
+      // try again next time
+    }
+  }
+
+  function refreshTiles() {
+    for (const img of tiles.values()) load(img, img.dataset.url);
+  }
+
+  fetch("config.json").then((r) => r.json()).then((cfg) => {
+    state.tileSize = cfg.tileSize;
+    state.maxZoom = cfg.maxZoom;
+    draw();
+    refreshPlayers();
+    setInterval(refreshPlayers, PLAYER_REFRESH_MS);
+    setInterval(refreshTiles, TILE_REFRESH_MS);
+  });
+})();
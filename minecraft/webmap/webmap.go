@@ -0,0 +1,197 @@
+// Package webmap serves a live top-down map of the world over HTTP, rendered from loaded chunks.
+package webmap
+
+import (
+	"bytes"
+	"embed"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/png"
+	"io/fs"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/golang/glog"
+)
+
+// MaxZoom is the most zoomed out level, where each pixel is 2^MaxZoom blocks across.
+const MaxZoom = 4
+
+//go:embed static
+var static embed.FS
+
+type Config struct {
+	// MinY and MaxY bound the blocks drawn, and how hills are shaded.
+	MinY, MaxY int
+	// CacheSize is how many rendered tiles are kept.
+	CacheSize int
+}
+
+func DefaultConfig() Config {
+	return Config{MinY: 0, MaxY: 127, CacheSize: 1024}
+}
+
+// Marker is a player shown on the map.
+type Marker struct {
+	Name string  `json:"name"`
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	Z    float64 `json:"z"`
+}
+
+// Map renders and serves tiles. It is an http.Handler.
+type Map struct {
+	cfg     Config
+	world   *world.World
+	players func() []Marker
+	mux     *http.ServeMux
+
+	mu    sync.Mutex
+	cache *tileCache
+}
+
+// New makes a map of w. players may be nil if there is nobody to show.
+func New(w *world.World, players func() []Marker, cfg Config) *Map {
+	m := &Map{
+		cfg:     cfg,
+		world:   w,
+		players: players,
+		mux:     http.NewServeMux(),
+		cache:   newTileCache(cfg.CacheSize),
+	}
+	files, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err) // the embedded directory is always there
+	}
+	m.mux.Handle("GET /", http.FileServerFS(files))
+	m.mux.HandleFunc("GET /tiles/{zoom}/{x}/{file}", m.serveTile)
+	m.mux.HandleFunc("GET /players.json", m.servePlayers)
+	m.mux.HandleFunc("GET /config.json", m.serveConfig)
+	return m
+}
+
+func (m *Map) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mux.ServeHTTP(w, r)
+}
+
+// Tile returns the tile at tile position x, z of zoom, rendering it if anything under it changed.
+// A zoom level tile covers TileSize << zoom blocks along each side.
+func (m *Map) Tile(zoom, x, z int) (img *image.RGBA, stamp uint64) {
+	t := m.tile(zoom, x, z)
+	return t.img, t.stamp
+}
+
+func (m *Map) cached(k tileKey) (*tile, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cache.get(k)
+}
+
+func (m *Map) tile(zoom, x, z int) *tile {
+	k := tileKey{zoom: zoom, x: x, z: z}
+	old, ok := m.cached(k)
+	var t *tile
+	if zoom == 0 {
+		stamp := m.baseStamp(x, z)
+		if ok && old.stamp == stamp {
+			return old
+		}
+		var prev *image.RGBA
+		if ok {
+			prev = old.img
+		}
+		t = &tile{key: k, img: m.renderBase(x, z, prev), stamp: stamp}
+	} else {
+		var children [4]*image.RGBA
+		h := fnv.New64a()
+		for i := range children {
+			c := m.tile(zoom-1, 2*x+i%2, 2*z+i/2)
+			children[i] = c.img
+			h.Write(binary.LittleEndian.AppendUint64(nil, c.stamp))
+		}
+		stamp := h.Sum64()
+		if ok && old.stamp == stamp {
+			return old
+		}
+		t = &tile{key: k, img: downsample(children), stamp: stamp}
+	}
+	m.mu.Lock()
+	m.cache.put(t)
+	m.mu.Unlock()
+	return t
+}
+
+func (m *Map) serveTile(w http.ResponseWriter, r *http.Request) {
+	zoom, err1 := strconv.Atoi(r.PathValue("zoom"))
+	x, err2 := strconv.Atoi(r.PathValue("x"))
+	z, err3 := strconv.Atoi(strings.TrimSuffix(r.PathValue("file"), ".png"))
+	if err1 != nil || err2 != nil || err3 != nil || !strings.HasSuffix(r.PathValue("file"), ".png") {
+		http.Error(w, "tiles are /tiles/{zoom}/{x}/{z}.png", http.StatusBadRequest)
+		return
+	}
+	if zoom < 0 || zoom > MaxZoom {
+		http.Error(w, fmt.Sprintf("zoom must be 0 to %d", MaxZoom), http.StatusNotFound)
+		return
+	}
+	t := m.tile(zoom, x, z)
+	etag := fmt.Sprintf(`"%x"`, t.stamp)
+	w.Header().Set("ETag", etag)
+	// tiles change as the world does, so browsers must check back each time
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	data, err := m.encode(t)
+	if err != nil {
+		glog.Errorf("Error encoding tile %v: %v", t.key, err)
+		http.Error(w, "error encoding tile", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(data)
+}
+
+// encode returns t as a PNG, encoding it once per render.
+func (m *Map) encode(t *tile) ([]byte, error) {
+	m.mu.Lock()
+	data := t.png
+	m.mu.Unlock()
+	if data != nil {
+		return data, nil
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, t.img); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	t.png = buf.Bytes()
+	m.mu.Unlock()
+	return buf.Bytes(), nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		glog.Errorf("Error writing JSON: %v", err)
+	}
+}
+
+func (m *Map) servePlayers(w http.ResponseWriter, r *http.Request) {
+	markers := []Marker{}
+	if m.players != nil {
+		markers = append(markers, m.players()...)
+	}
+	writeJSON(w, markers)
+}
+
+func (m *Map) serveConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]int{"tileSize": TileSize, "maxZoom": MaxZoom})
+}
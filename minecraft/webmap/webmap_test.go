@@ -0,0 +1,211 @@
+package webmap
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// loadColumns loads chunk columns from chunk x0, z0 to x1, z1 inclusive over the map's height.
+func loadColumns(w *world.World, x0, z0, x1, z1 int) {
+	for cz := z0; cz <= z1; cz++ {
+		for cx := x0; cx <= x1; cx++ {
+			for cy := 0; cy < 8; cy++ {
+				w.Chunk(vec.IntVec3{X: cx, Y: cy, Z: cz})
+			}
+		}
+	}
+}
+
+func get(t *testing.T, srv *httptest.Server, path string, header http.Header) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest("GET", srv.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("GET %s = %v", path, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func getTile(t *testing.T, srv *httptest.Server, path string) (*image.RGBA, string) {
+	t.Helper()
+	resp := get(t, srv, path, nil)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/png" {
+		t.Fatalf("GET %s = %s %s", path, resp.Status, resp.Header.Get("Content-Type"))
+	}
+	img, err := png.Decode(resp.Body)
+	if err != nil {
+		t.Fatalf("decoding %s: %v", path, err)
+	}
+	// PNG decodes to NRGBA, every pixel is opaque or clear so converting is exact
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, image.Point{}, draw.Src)
+	return rgba, resp.Header.Get("ETag")
+}
+
+func TestTiles(t *testing.T) {
+	t.Parallel()
+	w := world.New(1)
+	loadColumns(w, 0, 0, 1, 1)
+	loadColumns(w, -1, -1, -1, -1)
+	m := New(w, nil, DefaultConfig())
+	srv := httptest.NewServer(m)
+	defer srv.Close()
+
+	img, etag := getTile(t, srv, "/tiles/0/0/0.png")
+	if b := img.Bounds(); b.Dx() != TileSize || b.Dy() != TileSize {
+		t.Errorf("tile is %v, want %dx%d", b, TileSize, TileSize)
+	}
+	if img.RGBAAt(5, 5).A == 0 {
+		t.Errorf("loaded column at 5, 5 is transparent")
+	}
+	if img.RGBAAt(100, 100).A != 0 {
+		t.Errorf("unloaded column at 100, 100 was drawn")
+	}
+	if neg, _ := getTile(t, srv, "/tiles/0/-1/-1.png"); neg.RGBAAt(TileSize-1, TileSize-1).A == 0 {
+		t.Errorf("loaded column at -1, -1 is transparent")
+	}
+
+	t.Run("not modified", func(t *testing.T) {
+		resp := get(t, srv, "/tiles/0/0/0.png", http.Header{"If-None-Match": {etag}})
+		if resp.StatusCode != http.StatusNotModified {
+			t.Errorf("GET with matching ETag = %s, want 304", resp.Status)
+		}
+	})
+
+	t.Run("invalidated by edits", func(t *testing.T) {
+		w.SetBlock(vec.IntVec3{X: 5, Y: 120, Z: 5}, blocks.Lava)
+		edited, newTag := getTile(t, srv, "/tiles/0/0/0.png")
+		if newTag == etag {
+			t.Errorf("ETag didn't change after an edit")
+		}
+		if got, want := edited.RGBAAt(5, 5), shade(blockColor(blocks.Lava), 120, 0, 127); got != want {
+			t.Errorf("edited pixel = %v, want lava %v", got, want)
+		}
+		if edited.RGBAAt(6, 5) != img.RGBAAt(6, 5) {
+			t.Errorf("neighbouring pixel changed")
+		}
+	})
+
+	t.Run("zoomed out", func(t *testing.T) {
+		z1, _ := getTile(t, srv, "/tiles/1/0/0.png")
+		// zoom 1 pixel 3, 3 averages zoom 0 pixels 6 to 7
+		if z1.RGBAAt(3, 3).A == 0 || z1.RGBAAt(50, 50).A != 0 {
+			t.Errorf("zoom 1 tile doesn't match what's loaded")
+		}
+		top, _ := getTile(t, srv, "/tiles/4/-1/-1.png")
+		if top.RGBAAt(TileSize-1, TileSize-1).A == 0 || top.RGBAAt(0, 0).A != 0 {
+			t.Errorf("zoom 4 tile doesn't match what's loaded")
+		}
+	})
+
+	t.Run("remembers unloaded chunks", func(t *testing.T) {
+		before, _ := m.Tile(0, -1, -1)
+		for cy := 0; cy < 8; cy++ {
+			if _, err := w.Unload(vec.IntVec3{X: -1, Y: cy, Z: -1}); err != nil {
+				t.Fatalf("Unload() = %v", err)
+			}
+		}
+		after, _ := m.Tile(0, -1, -1)
+		if after == before || after.RGBAAt(TileSize-1, TileSize-1) != before.RGBAAt(TileSize-1, TileSize-1) {
+			t.Errorf("tile wasn't re-rendered keeping the unloaded column")
+		}
+	})
+
+	t.Run("bad requests", func(t *testing.T) {
+		for path, want := range map[string]int{
+			"/tiles/0/0/0.jpg":  http.StatusBadRequest,
+			"/tiles/0/x/0.png":  http.StatusBadRequest,
+			"/tiles/9/0/0.png":  http.StatusNotFound,
+			"/tiles/-1/0/0.png": http.StatusNotFound,
+		} {
+			if got := get(t, srv, path, nil).StatusCode; got != want {
+				t.Errorf("GET %s = %d, want %d", path, got, want)
+			}
+		}
+	})
+}
+
+func TestPage(t *testing.T) {
+	t.Parallel()
+	players := func() []Marker { return []Marker{{Name: "alex", X: 1.5, Y: 64, Z: -3}} }
+	srv := httptest.NewServer(New(world.New(1), players, DefaultConfig()))
+	defer srv.Close()
+
+	for path, want := range map[string]string{
+		"/":        "map.js",
+		"/map.js":  "tiles/",
+		"/map.css": "#map",
+	} {
+		resp := get(t, srv, path, nil)
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), want) {
+			t.Errorf("GET %s = %s without %q", path, resp.Status, want)
+		}
+	}
+
+	var markers []Marker
+	if err := json.NewDecoder(get(t, srv, "/players.json", nil).Body).Decode(&markers); err != nil {
+		t.Fatalf("decoding players: %v", err)
+	}
+	if len(markers) != 1 || markers[0] != (Marker{Name: "alex", X: 1.5, Y: 64, Z: -3}) {
+		t.Errorf("players.json = %+v", markers)
+	}
+	var cfg map[string]int
+	if err := json.NewDecoder(get(t, srv, "/config.json", nil).Body).Decode(&cfg); err != nil {
+		t.Fatalf("decoding config: %v", err)
+	}
+	if cfg["tileSize"] != TileSize || cfg["maxZoom"] != MaxZoom {
+		t.Errorf("config.json = %v", cfg)
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	t.Parallel()
+	c := newTileCache(2)
+	for i := 0; i < 3; i++ {
+		c.put(&tile{key: tileKey{x: i}})
+		if i == 1 {
+			c.get(tileKey{x: 0}) // keep 0 fresh so 1 is evicted instead
+		}
+	}
+	if _, ok := c.get(tileKey{x: 1}); ok || c.len() != 2 {
+		t.Errorf("expected tile 1 evicted leaving 2, have %d", c.len())
+	}
+	if _, ok := c.get(tileKey{x: 0}); !ok {
+		t.Errorf("recently used tile 0 was evicted")
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	t.Parallel()
+	child := image.NewRGBA(image.Rect(0, 0, TileSize, TileSize))
+	child.SetRGBA(0, 0, color.RGBA{R: 100, A: 255})
+	child.SetRGBA(1, 1, color.RGBA{R: 200, A: 255})
+	img := downsample([4]*image.RGBA{nil, child, nil, nil})
+	// transparent pixels don't darken the average
+	if got := img.RGBAAt(TileSize/2, 0); got != (color.RGBA{R: 150, A: 255}) {
+		t.Errorf("downsampled pixel = %v", got)
+	}
+	if img.RGBAAt(0, 0).A != 0 {
+		t.Errorf("missing child was drawn")
+	}
+}
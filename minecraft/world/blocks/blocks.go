@@ -1,5 +1,7 @@
 package blocks
 
+import "strings"
+
 type Block interface {
 	DirtyGen() bool
 }
@@ -15,6 +17,9 @@ const (
 	Leaves
 	Wood
 	Flower
+	Water
+	Lava
+	Planks
 )
 
 func (s SimpleBlockType) String() string {
@@ -35,10 +40,64 @@ func (s SimpleBlockType) String() string {
 		return "Wood"
 	case Flower:
 		return "Flower"
+	case Water:
+		return "Water"
+	case Lava:
+		return "Lava"
+	case Planks:
+		return "Planks"
 	}
 	return "unknown"
 }
 
+// Types lists every block type in order.
+func Types() []SimpleBlockType {
+	var out []SimpleBlockType
+	for t := Air; t.String() != "unknown"; t++ {
+		out = append(out, t)
+	}
+	return out
+}
+
+// ParseType looks up a block type by name, ignoring case and any "minecraft:" namespace.
+func ParseType(name string) (SimpleBlockType, bool) {
+	name = strings.TrimPrefix(strings.ToLower(name), "minecraft:")
+	for _, t := range Types() {
+		if strings.ToLower(t.String()) == name {
+			return t, true
+		}
+	}
+	return Air, false
+}
+
+// IsFluid reports whether entities swim (or burn) in the block rather than stand on it.
+func (s SimpleBlockType) IsFluid() bool {
+	return s == Water || s == Lava
+}
+
+// IsSolid reports whether the block occupies its full cell for collision.
+func (s SimpleBlockType) IsSolid() bool {
+	switch s {
+	case Air, Flower, Water, Lava:
+		return false
+	}
+	return true
+}
+
+// IsOpaque reports whether the block hides everything behind it, so faces against it need not be drawn.
+func (s SimpleBlockType) IsOpaque() bool {
+	switch s {
+	case Air, Leaves, Flower, Water:
+		return false
+	}
+	return true
+}
+
+// IsTranslucent reports whether the block is drawn blended over what is behind it.
+func (s SimpleBlockType) IsTranslucent() bool {
+	return s == Water
+}
+
 type SimpleBlock struct {
 	Dirtied bool
 	T       SimpleBlockType
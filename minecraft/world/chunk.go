@@ -0,0 +1,75 @@
+package world
+
+import (
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// ChunkSize is the edge length of the cubic chunks the world is stored in.
+const ChunkSize = 16
+
+const chunkVolume = ChunkSize * ChunkSize * ChunkSize
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// ChunkOf splits a world position into the chunk holding it and the offset inside that chunk.
+func ChunkOf(pos vec.IntVec3) (chunk, local vec.IntVec3) {
+	chunk = vec.IntVec3{X: floorDiv(pos.X, ChunkSize), Y: floorDiv(pos.Y, ChunkSize), Z: floorDiv(pos.Z, ChunkSize)}
+	local = vec.IntVec3{X: pos.X - chunk.X*ChunkSize, Y: pos.Y - chunk.Y*ChunkSize, Z: pos.Z - chunk.Z*ChunkSize}
+	return chunk, local
+}
+
+// ChunkOrigin is the world position of a chunk's minimum corner.
+func ChunkOrigin(chunk vec.IntVec3) vec.IntVec3 {
+	return vec.IntVec3{X: chunk.X * ChunkSize, Y: chunk.Y * ChunkSize, Z: chunk.Z * ChunkSize}
+}
+
+type Chunk struct {
+	Pos    vec.IntVec3
+	blocks [chunkVolume]blocks.SimpleBlockType
+	// Dirty is set on any change and cleared once the chunk has been saved.
+	Dirty bool
+	// Version is bumped on every change so caches (meshes, map tiles) can tell they are stale.
+	Version uint64
+}
+
+func index(local vec.IntVec3) int {
+	return (local.Y*ChunkSize+local.Z)*ChunkSize + local.X
+}
+
+// Get reads a block by its position inside the chunk.
+func (c *Chunk) Get(local vec.IntVec3) blocks.SimpleBlockType {
+	return c.blocks[index(local)]
+}
+
+// Set changes one block and returns the old one. Chunks owned by a World must
+// be changed through World.SetBlock instead so listeners hear about it.
+func (c *Chunk) Set(local vec.IntVec3, t blocks.SimpleBlockType) blocks.SimpleBlockType {
+	i := index(local)
+	old := c.blocks[i]
+	if old != t {
+		c.blocks[i] = t
+		c.Dirty = true
+		c.Version++
+	}
+	return old
+}
+
+// Blocks returns a copy of the chunk contents in Y, Z, X order.
+func (c *Chunk) Blocks() []blocks.SimpleBlockType {
+	out := make([]blocks.SimpleBlockType, chunkVolume)
+	copy(out, c.blocks[:])
+	return out
+}
+
+// Fill replaces the chunk contents, b must be in the order Blocks returns.
+func (c *Chunk) Fill(b []blocks.SimpleBlockType) {
+	copy(c.blocks[:], b)
+	c.Version++
+}
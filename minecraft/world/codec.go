@@ -0,0 +1,53 @@
+package world
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// EncodeChunk packs a chunk as one byte per block and zlib compresses it. It is used both
+// on disk and on the wire.
+func EncodeChunk(c *Chunk) ([]byte, error) {
+	raw := make([]byte, 0, chunkVolume)
+	for _, b := range c.Blocks() {
+		raw = append(raw, uint8(b))
+	}
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeChunk reverses EncodeChunk.
+func DecodeChunk(pos vec.IntVec3, data []byte) (*Chunk, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing chunk %v: %v", pos, err)
+	}
+	defer zr.Close()
+	// read one extra byte so oversized data is caught
+	raw, err := io.ReadAll(io.LimitReader(zr, chunkVolume+1))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing chunk %v: %v", pos, err)
+	}
+	if len(raw) != chunkVolume {
+		return nil, fmt.Errorf("chunk %v has %d blocks, expected %d", pos, len(raw), chunkVolume)
+	}
+	b := make([]blocks.SimpleBlockType, chunkVolume)
+	for i, v := range raw {
+		b[i] = blocks.SimpleBlockType(v)
+	}
+	c := &Chunk{Pos: pos}
+	c.Fill(b)
+	return c, nil
+}
@@ -0,0 +1,170 @@
+package edit
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// DefaultBatchSize is how many blocks are changed per game tick.
+const DefaultBatchSize = 4096
+
+type World interface {
+	BlockAt(pos vec.IntVec3) blocks.SimpleBlockType
+	// SetBlockBy records actor as the cause of the change, see world.BlockChange.
+	SetBlockBy(pos vec.IntVec3, t blocks.SimpleBlockType, actor string)
+}
+
+type target struct {
+	pos   vec.IntVec3
+	block blocks.SimpleBlockType
+}
+
+// Job is an edit being applied over one or more ticks.
+type Job struct {
+	targets []target
+	actor   string
+	done    chan struct{}
+	onDone  func(*Diff)
+
+	// mu guards the progress, which the applier updates while commands report on it.
+	mu      sync.Mutex
+	next    int
+	diff    *Diff
+	touched map[vec.IntVec3]bool
+}
+
+func newJob(targets []target, actor string, onDone func(*Diff)) *Job {
+	origin := vec.IntVec3{}
+	if len(targets) > 0 {
+		origin = targets[0].pos
+	}
+	return &Job{
+		targets: targets,
+		actor:   actor,
+		diff:    newDiff(origin),
+		touched: map[vec.IntVec3]bool{},
+		done:    make(chan struct{}),
+		onDone:  onDone,
+	}
+}
+
+// Done is closed once every block of the job has been applied.
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Progress is how many of the job's blocks have been processed so far.
+func (j *Job) Progress() (applied, total int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.next, len(j.targets)
+}
+
+// Changed is how many blocks actually changed, only final once the job is done.
+func (j *Job) Changed() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.diff.Len()
+}
+
+// Touched lists the chunks the job changed, which the world has marked dirty for saving and remeshing.
+func (j *Job) Touched() []vec.IntVec3 {
+	j.mu.Lock()
+	var out []vec.IntVec3
+	for c := range j.touched {
+		out = append(out, c)
+	}
+	j.mu.Unlock()
+	sort.Slice(out, func(i, k int) bool {
+		a, b := out[i], out[k]
+		if a.X != b.X {
+			return a.X < b.X
+		}
+		if a.Y != b.Y {
+			return a.Y < b.Y
+		}
+		return a.Z < b.Z
+	})
+	return out
+}
+
+// applyBatch applies up to n blocks and returns how many it used and whether the job is done.
+func (j *Job) applyBatch(w World, n int) (used int, done bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for ; j.next < len(j.targets) && used < n; j.next++ {
+		t := j.targets[j.next]
+		used++
+		old := w.BlockAt(t.pos)
+		if old == t.block {
+			continue
+		}
+		w.SetBlockBy(t.pos, t.block, j.actor)
+		j.diff.record(t.pos, old, t.block)
+		chunk, _ := world.ChunkOf(t.pos)
+		j.touched[chunk] = true
+	}
+	return used, j.next == len(j.targets)
+}
+
+// Applier spreads queued jobs over game ticks so big edits don't stall the server.
+type Applier struct {
+	mu    sync.Mutex
+	world World
+	batch int
+	queue []*Job
+}
+
+func NewApplier(w World, batchSize int) *Applier {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	return &Applier{world: w, batch: batchSize}
+}
+
+func (a *Applier) submit(j *Job) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.queue = append(a.queue, j)
+}
+
+// Pending is the number of jobs not yet finished.
+func (a *Applier) Pending() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.queue)
+}
+
+// Tick applies the next batch of blocks, oldest job first.
+func (a *Applier) Tick() {
+	a.mu.Lock()
+	budget := a.batch
+	var finished []*Job
+	for budget > 0 && len(a.queue) > 0 {
+		j := a.queue[0]
+		used, done := j.applyBatch(a.world, budget)
+		budget -= used
+		if done {
+			finished = append(finished, j)
+			a.queue = a.queue[1:]
+		}
+	}
+	a.mu.Unlock()
+	for _, j := range finished {
+		if j.onDone != nil {
+			j.onDone(j.diff)
+		}
+		close(j.done)
+	}
+}
+
+// Flush runs ticks until every queued job is applied, mostly useful for tests and shutdown.
+func (a *Applier) Flush() {
+	for a.Pending() > 0 {
+		a.Tick()
+	}
+}
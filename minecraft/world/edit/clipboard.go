@@ -0,0 +1,60 @@
+package edit
+
+import (
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// Clipboard holds a copied region relative to where it was copied from.
+type Clipboard struct {
+	size vec.IntVec3
+	// offset is the region's min corner relative to the copy origin.
+	offset vec.IntVec3
+	blocks []blocks.SimpleBlockType
+}
+
+// Transform is applied around the paste origin. Mirroring happens before rotation.
+type Transform struct {
+	// QuarterTurns rotates clockwise around Y when looking down.
+	QuarterTurns int
+	MirrorX      bool
+	MirrorZ      bool
+}
+
+func (t Transform) apply(v vec.IntVec3) vec.IntVec3 {
+	if t.MirrorX {
+		v.X = -v.X
+	}
+	if t.MirrorZ {
+		v.Z = -v.Z
+	}
+	for i := 0; i < ((t.QuarterTurns%4)+4)%4; i++ {
+		v = vec.IntVec3{X: -v.Z, Y: v.Y, Z: v.X}
+	}
+	return v
+}
+
+func copyRegion(w World, region Cuboid, origin vec.IntVec3) *Clipboard {
+	c := &Clipboard{
+		size:   region.Size(),
+		offset: vec.IntVec3{X: region.Min.X - origin.X, Y: region.Min.Y - origin.Y, Z: region.Min.Z - origin.Z},
+		blocks: make([]blocks.SimpleBlockType, 0, region.Volume()),
+	}
+	region.ForEach(func(p vec.IntVec3) {
+		c.blocks = append(c.blocks, w.BlockAt(p))
+	})
+	return c
+}
+
+func (c *Clipboard) Size() vec.IntVec3 {
+	return c.size
+}
+
+// each visits every copied block with its position relative to the copy origin.
+func (c *Clipboard) each(f func(rel vec.IntVec3, b blocks.SimpleBlockType)) {
+	i := 0
+	Cuboid{Max: vec.IntVec3{X: c.size.X - 1, Y: c.size.Y - 1, Z: c.size.Z - 1}}.ForEach(func(p vec.IntVec3) {
+		f(p.Add(c.offset), c.blocks[i])
+		i++
+	})
+}
@@ -0,0 +1,160 @@
+package edit
+
+import (
+	"math"
+
+	"github.com/dragon1672/go-mine/minecraft/command"
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+)
+
+// RegisterCommands adds WorldEdit style //commands backed by e.
+func RegisterCommands(reg *command.Registry, e *Editor) error {
+	queued := func(ctx *command.Context, j *Job, err error) error {
+		if err != nil {
+			return command.Failed("%v", err)
+		}
+		_, total := j.Progress()
+		ctx.Replyf("Queued %d blocks", total)
+		return nil
+	}
+	here := func(ctx *command.Context) (vec.IntVec3, error) {
+		pos, ok := ctx.Source.Position()
+		if !ok {
+			return vec.IntVec3{}, command.Failed("%s has no position", ctx.Source.Name())
+		}
+		return vec.IntVec3{X: int(math.Floor(pos.X)), Y: int(math.Floor(pos.Y)), Z: int(math.Floor(pos.Z))}, nil
+	}
+	corner := func(name string, set func(s *Session, p vec.IntVec3)) *command.Command {
+		return &command.Command{
+			Name:        "/" + name,
+			Description: "sets a selection corner, defaults to where you stand",
+			Permission:  command.PermGameMaster,
+			Args:        []command.Arg{command.Optional(command.Coords("pos"))},
+			Run: func(ctx *command.Context) error {
+				p, err := here(ctx)
+				if ctx.Has("pos") {
+					p, err = ctx.Pos("pos"), nil
+				}
+				if err != nil {
+					return err
+				}
+				set(e.Session(ctx.Source.Name()), p)
+				ctx.Replyf("%s set to %v", name, p)
+				return nil
+			},
+		}
+	}
+
+	for _, cmd := range []*command.Command{
+		corner("pos1", (*Session).SetPos1),
+		corner("pos2", (*Session).SetPos2),
+		{
+			Name:        "/set",
+			Description: "fills the selection",
+			Permission:  command.PermGameMaster,
+			Args:        []command.Arg{command.Block("block")},
+			Run: func(ctx *command.Context) error {
+				j, err := e.Session(ctx.Source.Name()).Fill(ctx.Block("block"))
+				return queued(ctx, j, err)
+			},
+		},
+		{
+			Name:        "/replace",
+			Description: "replaces one block type with another in the selection",
+			Permission:  command.PermGameMaster,
+			Args:        []command.Arg{command.Block("from"), command.Block("to")},
+			Run: func(ctx *command.Context) error {
+				j, err := e.Session(ctx.Source.Name()).Replace(ctx.Block("from"), ctx.Block("to"))
+				return queued(ctx, j, err)
+			},
+		},
+		{
+			Name:        "/copy",
+			Description: "copies the selection relative to where you stand",
+			Permission:  command.PermGameMaster,
+			Run: func(ctx *command.Context) error {
+				p, err := here(ctx)
+				if err != nil {
+					return err
+				}
+				c, err := e.Session(ctx.Source.Name()).Copy(p)
+				if err != nil {
+					return command.Failed("%v", err)
+				}
+				ctx.Replyf("Copied %v blocks", c.Size())
+				return nil
+			},
+		},
+		{
+			Name:        "/paste",
+			Description: "pastes the clipboard relative to where you stand",
+			Permission:  command.PermGameMaster,
+			Args: []command.Arg{
+				command.Optional(command.Int("quarter_turns", -3, 3)),
+				command.Optional(command.Word("mirror", "x", "z", "xz")),
+			},
+			Run: func(ctx *command.Context) error {
+				p, err := here(ctx)
+				if err != nil {
+					return err
+				}
+				var t Transform
+				if ctx.Has("quarter_turns") {
+					t.QuarterTurns = ctx.Int("quarter_turns")
+				}
+				if ctx.Has("mirror") {
+					switch ctx.String("mirror") {
+					case "x":
+						t.MirrorX = true
+					case "z":
+						t.MirrorZ = true
+					case "xz":
+						t.MirrorX, t.MirrorZ = true, true
+					default:
+						return command.Failed("mirror must be x, z or xz")
+					}
+				}
+				j, err := e.Session(ctx.Source.Name()).Paste(p, t)
+				return queued(ctx, j, err)
+			},
+		},
+		{
+			Name:        "/move",
+			Description: "moves the selection",
+			Permission:  command.PermGameMaster,
+			Args: []command.Arg{
+				command.Int("dx", -math.MaxInt16, math.MaxInt16),
+				command.Int("dy", -math.MaxInt16, math.MaxInt16),
+				command.Int("dz", -math.MaxInt16, math.MaxInt16),
+			},
+			Run: func(ctx *command.Context) error {
+				offset := vec.IntVec3{X: ctx.Int("dx"), Y: ctx.Int("dy"), Z: ctx.Int("dz")}
+				j, err := e.Session(ctx.Source.Name()).Move(offset)
+				return queued(ctx, j, err)
+			},
+		},
+		{
+			Name:        "/undo",
+			Description: "undoes your last edit",
+			Permission:  command.PermGameMaster,
+			Run: func(ctx *command.Context) error {
+				j, err := e.Session(ctx.Source.Name()).Undo()
+				return queued(ctx, j, err)
+			},
+		},
+		{
+			Name:        "/redo",
+			Description: "redoes your last undone edit",
+			Permission:  command.PermGameMaster,
+			Run: func(ctx *command.Context) error {
+				j, err := e.Session(ctx.Source.Name()).Redo()
+				return queued(ctx, j, err)
+			},
+		},
+	} {
+		if err := reg.Register(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package edit
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dragon1672/go-mine/minecraft/command"
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// sky is far above generated terrain so every block starts as air.
+const sky = 200
+
+func p(x, y, z int) vec.IntVec3 {
+	return vec.IntVec3{X: x, Y: sky + y, Z: z}
+}
+
+func TestFillUndoRedo(t *testing.T) {
+	t.Parallel()
+	w := world.New(1)
+	e := NewEditor(w, 10)
+	s := e.Session("alex")
+	if _, err := s.Fill(blocks.Stone); err == nil {
+		t.Errorf("expected error filling without a selection")
+	}
+	s.SetPos1(p(0, 0, 0))
+	s.SetPos2(p(3, 1, 19)) // 160 blocks across two chunks
+
+	j, err := s.Fill(blocks.Stone)
+	if err != nil {
+		t.Fatalf("Fill() err: %v", err)
+	}
+	e.Tick()
+	if applied, total := j.Progress(); applied != 10 || total != 160 {
+		t.Errorf("after one tick progress = %d/%d, want 10/160", applied, total)
+	}
+	e.Applier().Flush()
+	<-j.Done()
+	if w.BlockAt(p(3, 1, 19)) != blocks.Stone || j.Changed() != 160 {
+		t.Errorf("fill not applied, changed %d", j.Changed())
+	}
+	if touched := j.Touched(); len(touched) != 2 {
+		t.Errorf("expected 2 touched chunks, got %v", touched)
+	}
+	for _, c := range j.Touched() {
+		if !w.Chunk(c).Dirty {
+			t.Errorf("chunk %v not marked dirty", c)
+		}
+	}
+
+	s.SetPos1(p(0, 0, 0))
+	s.SetPos2(p(0, 0, 0))
+	j, _ = s.Replace(blocks.Stone, blocks.Sand)
+	e.Applier().Flush()
+	if w.BlockAt(p(0, 0, 0)) != blocks.Sand || w.BlockAt(p(1, 0, 0)) != blocks.Stone {
+		t.Errorf("replace touched the wrong blocks")
+	}
+
+	if _, err := s.Undo(); err != nil {
+		t.Fatalf("Undo() err: %v", err)
+	}
+	e.Applier().Flush()
+	if w.BlockAt(p(0, 0, 0)) != blocks.Stone {
+		t.Errorf("undo of replace got %v", w.BlockAt(p(0, 0, 0)))
+	}
+	if _, err := s.Undo(); err != nil {
+		t.Fatalf("Undo() err: %v", err)
+	}
+	e.Applier().Flush()
+	if w.BlockAt(p(2, 1, 5)) != blocks.Air {
+		t.Errorf("undo of fill got %v", w.BlockAt(p(2, 1, 5)))
+	}
+	if _, err := s.Undo(); err == nil {
+		t.Errorf("expected nothing left to undo")
+	}
+
+	if _, err := s.Redo(); err != nil {
+		t.Fatalf("Redo() err: %v", err)
+	}
+	e.Applier().Flush()
+	if w.BlockAt(p(2, 1, 5)) != blocks.Stone || w.BlockAt(p(0, 0, 0)) != blocks.Stone {
+		t.Errorf("redo should restore the fill but not the replace")
+	}
+	if !s.History().CanRedo() || !s.History().CanUndo() {
+		t.Errorf("expected both undo and redo to be available")
+	}
+}
+
+func TestHugeSelection(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name string
+		pos1 vec.IntVec3
+		pos2 vec.IntVec3
+		want int
+	}{
+		{name: "wraps to zero", pos2: vec.IntVec3{X: 1<<21 - 1, Y: 1<<21 - 1, Z: 1<<22 - 1}, want: math.MaxInt},
+		{name: "wraps negative", pos2: vec.IntVec3{X: 1<<32 - 1, Y: 1<<31 - 1, Z: 0}, want: math.MaxInt},
+		{name: "every int", pos1: vec.IntVec3{X: math.MinInt}, pos2: vec.IntVec3{X: math.MaxInt}, want: math.MaxInt},
+		{name: "at the edge", pos1: vec.IntVec3{X: math.MaxInt - 1}, pos2: vec.IntVec3{X: math.MaxInt, Y: 1}, want: 4},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			c := NewCuboid(tc.pos1, tc.pos2)
+			if got := c.Volume(); got != tc.want {
+				t.Errorf("Volume() = %d, want %d", got, tc.want)
+			}
+			if tc.want != math.MaxInt {
+				n := 0
+				c.ForEach(func(vec.IntVec3) { n++ })
+				if n != tc.want {
+					t.Errorf("ForEach() visited %d blocks, want %d", n, tc.want)
+				}
+				return
+			}
+			s := NewEditor(world.New(1), 10).Session("alex")
+			s.SetPos1(tc.pos1)
+			s.SetPos2(tc.pos2)
+			if _, err := s.Fill(blocks.Stone); err == nil {
+				t.Errorf("Fill() expected error")
+			}
+			if _, err := s.Copy(tc.pos1); err == nil {
+				t.Errorf("Copy() expected error")
+			}
+			if _, err := s.Move(vec.IntVec3{X: 1}); err == nil {
+				t.Errorf("Move() expected error")
+			}
+		})
+	}
+}
+
+func TestCopyPaste(t *testing.T) {
+	t.Parallel()
+	// An L shape: (0,0,0) stone, (1,0,0) wood, (0,0,1) sand.
+	build := func() (*world.World, *Session, *Editor) {
+		w := world.New(1)
+		w.SetBlock(p(0, 0, 0), blocks.Stone)
+		w.SetBlock(p(1, 0, 0), blocks.Wood)
+		w.SetBlock(p(0, 0, 1), blocks.Sand)
+		e := NewEditor(w, 0)
+		s := e.Session("alex")
+		s.SetPos1(p(0, 0, 0))
+		s.SetPos2(p(1, 0, 1))
+		if _, err := s.Copy(p(0, 0, 0)); err != nil {
+			t.Fatalf("Copy() err: %v", err)
+		}
+		return w, s, e
+	}
+	for _, tc := range []struct {
+		name string
+		t    Transform
+		want map[vec.IntVec3]blocks.SimpleBlockType
+	}{
+		{
+			name: "Plain",
+			want: map[vec.IntVec3]blocks.SimpleBlockType{p(10, 0, 0): blocks.Stone, p(11, 0, 0): blocks.Wood, p(10, 0, 1): blocks.Sand},
+		},
+		{
+			name: "Quarter turn",
+			t:    Transform{QuarterTurns: 1},
+			want: map[vec.IntVec3]blocks.SimpleBlockType{p(10, 0, 0): blocks.Stone, p(10, 0, 1): blocks.Wood, p(9, 0, 0): blocks.Sand},
+		},
+		{
+			name: "Mirror X",
+			t:    Transform{MirrorX: true},
+			want: map[vec.IntVec3]blocks.SimpleBlockType{p(10, 0, 0): blocks.Stone, p(9, 0, 0): blocks.Wood, p(10, 0, 1): blocks.Sand},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w, s, e := build()
+			if _, err := s.Paste(p(10, 0, 0), tc.t); err != nil {
+				t.Fatalf("Paste() err: %v", err)
+			}
+			e.Applier().Flush()
+			for pos, want := range tc.want {
+				if got := w.BlockAt(pos); got != want {
+					t.Errorf("%v = %v, want %v", pos, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("Move", func(t *testing.T) {
+		w, s, e := build()
+		if _, err := s.Move(vec.IntVec3{X: 1}); err != nil {
+			t.Fatalf("Move() err: %v", err)
+		}
+		e.Applier().Flush()
+		want := map[vec.IntVec3]blocks.SimpleBlockType{
+			p(0, 0, 0): blocks.Air, p(0, 0, 1): blocks.Air,
+			p(1, 0, 0): blocks.Stone, p(2, 0, 0): blocks.Wood, p(1, 0, 1): blocks.Sand,
+		}
+		for pos, b := range want {
+			if got := w.BlockAt(pos); got != b {
+				t.Errorf("%v = %v, want %v", pos, got, b)
+			}
+		}
+		if sel, _ := s.Selection(); sel.Min != p(1, 0, 0) {
+			t.Errorf("selection should follow the move, got %v", sel)
+		}
+		_, _ = s.Undo()
+		e.Applier().Flush()
+		if w.BlockAt(p(1, 0, 0)) != blocks.Wood || w.BlockAt(p(2, 0, 0)) != blocks.Air {
+			t.Errorf("undo of move did not restore the original")
+		}
+	})
+}
+
+func TestQueuedEdits(t *testing.T) {
+	t.Parallel()
+	w := world.New(1)
+	e := NewEditor(w, 1)
+	s := e.Session("alex")
+	s.SetPos1(p(0, 0, 0))
+	s.SetPos2(p(3, 0, 0))
+	// none of these are applied before the next one reads the world
+	if _, err := s.Fill(blocks.Stone); err != nil {
+		t.Fatalf("Fill() err: %v", err)
+	}
+	j, err := s.Replace(blocks.Stone, blocks.Sand)
+	if err != nil {
+		t.Fatalf("Replace() err: %v", err)
+	}
+	if _, total := j.Progress(); total != 4 {
+		t.Errorf("Replace() queued %d blocks, want the 4 filled", total)
+	}
+	if _, err := s.Fill(blocks.Wood); err != nil {
+		t.Fatalf("Fill() err: %v", err)
+	}
+	c, err := s.Copy(p(0, 0, 0))
+	if err != nil {
+		t.Fatalf("Copy() err: %v", err)
+	}
+	c.each(func(rel vec.IntVec3, b blocks.SimpleBlockType) {
+		if b != blocks.Wood {
+			t.Errorf("Copy() has %v at %v, want Wood", b, rel)
+		}
+	})
+	if _, err := s.Fill(blocks.Planks); err != nil {
+		t.Fatalf("Fill() err: %v", err)
+	}
+	j, err = s.Move(vec.IntVec3{Y: 1})
+	if err != nil {
+		t.Fatalf("Move() err: %v", err)
+	}
+	if _, total := j.Progress(); total != 8 {
+		t.Errorf("Move() queued %d blocks, want 8", total)
+	}
+	e.Applier().Flush()
+	for x := 0; x < 4; x++ {
+		if got := w.BlockAt(p(x, 1, 0)); got != blocks.Planks {
+			t.Errorf("%v = %v after move, want Planks", p(x, 1, 0), got)
+		}
+	}
+}
+
+type testPlayer struct {
+	pos vec.Vec3
+}
+
+func (t *testPlayer) Name() string               { return "alex" }
+func (t *testPlayer) Permission() int            { return command.PermGameMaster }
+func (t *testPlayer) Position() (vec.Vec3, bool) { return t.pos, true }
+func (t *testPlayer) Reply(string)               {}
+
+func TestCommands(t *testing.T) {
+	t.Parallel()
+	w := world.New(1)
+	e := NewEditor(w, 0)
+	reg := command.NewRegistry()
+	if err := RegisterCommands(reg, e); err != nil {
+		t.Fatalf("RegisterCommands() err: %v", err)
+	}
+	src := &testPlayer{pos: vec.Vec3{X: 0.5, Y: sky, Z: 0.5}}
+	for _, line := range []string{
+		"//pos1",
+		"//pos2 ~2 ~ ~",
+		"//set wood",
+		"//copy",
+	} {
+		if err := reg.Execute(src, line); err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+		e.Applier().Flush()
+	}
+	src.pos.Z = 5.5
+	if err := reg.Execute(src, "//paste 1"); err != nil {
+		t.Fatalf("//paste: %v", err)
+	}
+	e.Applier().Flush()
+	if w.BlockAt(p(0, 0, 7)) != blocks.Wood {
+		t.Errorf("expected rotated paste to run along Z")
+	}
+}
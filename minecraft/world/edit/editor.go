@@ -0,0 +1,276 @@
+package edit
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+const (
+	// MaxVolume caps how many blocks a single operation may touch.
+	MaxVolume = 1 << 22
+	// DefaultHistoryLimit is how many edits each user can undo.
+	DefaultHistoryLimit = 32
+)
+
+// Editor owns every user's edit session and the applier their edits go through.
+type Editor struct {
+	mu       sync.Mutex
+	world    World
+	applier  *Applier
+	sessions map[string]*Session
+}
+
+func NewEditor(w World, batchSize int) *Editor {
+	return &Editor{
+		world:    w,
+		applier:  NewApplier(w, batchSize),
+		sessions: map[string]*Session{},
+	}
+}
+
+// Tick applies the next batch of queued edits, call it once per game tick.
+func (e *Editor) Tick() {
+	e.applier.Tick()
+}
+
+func (e *Editor) Applier() *Applier {
+	return e.applier
+}
+
+// Session returns the session for user, creating it on first use.
+func (e *Editor) Session(user string) *Session {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	s, ok := e.sessions[user]
+	if !ok {
+		s = &Session{editor: e, user: user, history: NewHistory(DefaultHistoryLimit)}
+		e.sessions[user] = s
+	}
+	return s
+}
+
+// Session is one user's selection, clipboard and history.
+type Session struct {
+	editor     *Editor
+	user       string
+	mu         sync.Mutex
+	pos1, pos2 *vec.IntVec3
+	clipboard  *Clipboard
+	history    *History
+}
+
+func (s *Session) SetPos1(p vec.IntVec3) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pos1 = &p
+}
+
+func (s *Session) SetPos2(p vec.IntVec3) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pos2 = &p
+}
+
+func (s *Session) Selection() (Cuboid, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pos1 == nil || s.pos2 == nil {
+		return Cuboid{}, fmt.Errorf("select both corners first")
+	}
+	return NewCuboid(*s.pos1, *s.pos2), nil
+}
+
+func (s *Session) History() *History {
+	return s.history
+}
+
+func checkTargets(targets []target) error {
+	if len(targets) > MaxVolume {
+		return fmt.Errorf("%d blocks is more than the limit of %d", len(targets), MaxVolume)
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	origin := targets[0].pos
+	for _, t := range targets {
+		// diffs store offsets from the first block as int16
+		if abs(t.pos.X-origin.X) > math.MaxInt16 || abs(t.pos.Y-origin.Y) > math.MaxInt16 || abs(t.pos.Z-origin.Z) > math.MaxInt16 {
+			return fmt.Errorf("edit spans more than %d blocks", math.MaxInt16)
+		}
+	}
+	return nil
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+// run queues targets as a new undoable edit.
+func (s *Session) run(targets []target) (*Job, error) {
+	if err := checkTargets(targets); err != nil {
+		return nil, err
+	}
+	j := newJob(targets, s.user, func(d *Diff) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.history.push(d)
+	})
+	s.editor.applier.submit(j)
+	return j, nil
+}
+
+func (s *Session) selection() ([]target, Cuboid, error) {
+	sel, err := s.Selection()
+	if err != nil {
+		return nil, sel, err
+	}
+	if sel.Volume() > MaxVolume {
+		return nil, sel, fmt.Errorf("selection of %d blocks is more than the limit of %d", sel.Volume(), MaxVolume)
+	}
+	return make([]target, 0, sel.Volume()), sel, nil
+}
+
+// Fill sets every block in the selection to b.
+func (s *Session) Fill(b blocks.SimpleBlockType) (*Job, error) {
+	targets, sel, err := s.selection()
+	if err != nil {
+		return nil, err
+	}
+	sel.ForEach(func(p vec.IntVec3) {
+		targets = append(targets, target{pos: p, block: b})
+	})
+	return s.run(targets)
+}
+
+// Replace swaps every from block in the selection for to. Queued edits are applied first so
+// it sees their blocks, the same goes for Copy and Move.
+func (s *Session) Replace(from, to blocks.SimpleBlockType) (*Job, error) {
+	targets, sel, err := s.selection()
+	if err != nil {
+		return nil, err
+	}
+	w := s.editor.world
+	s.editor.applier.Flush()
+	sel.ForEach(func(p vec.IntVec3) {
+		if w.BlockAt(p) == from {
+			targets = append(targets, target{pos: p, block: to})
+		}
+	})
+	return s.run(targets)
+}
+
+// Copy stores the selection relative to origin, usually where the player is standing.
+func (s *Session) Copy(origin vec.IntVec3) (*Clipboard, error) {
+	sel, err := s.Selection()
+	if err != nil {
+		return nil, err
+	}
+	if sel.Volume() > MaxVolume {
+		return nil, fmt.Errorf("selection of %d blocks is more than the limit of %d", sel.Volume(), MaxVolume)
+	}
+	s.editor.applier.Flush()
+	c := copyRegion(s.editor.world, sel, origin)
+	s.mu.Lock()
+	s.clipboard = c
+	s.mu.Unlock()
+	return c, nil
+}
+
+// Paste places the clipboard relative to at.
+func (s *Session) Paste(at vec.IntVec3, t Transform) (*Job, error) {
+	s.mu.Lock()
+	c := s.clipboard
+	s.mu.Unlock()
+	if c == nil {
+		return nil, fmt.Errorf("clipboard is empty")
+	}
+	targets := make([]target, 0, len(c.blocks))
+	c.each(func(rel vec.IntVec3, b blocks.SimpleBlockType) {
+		targets = append(targets, target{pos: at.Add(t.apply(rel)), block: b})
+	})
+	return s.run(targets)
+}
+
+// Move shifts the selection by offset, leaving air behind, and moves the selection with it.
+func (s *Session) Move(offset vec.IntVec3) (*Job, error) {
+	_, sel, err := s.selection()
+	if err != nil {
+		return nil, err
+	}
+	w := s.editor.world
+	s.editor.applier.Flush()
+	dest := sel.Shift(offset)
+	left := 0
+	sel.ForEach(func(p vec.IntVec3) {
+		if !dest.Contains(p) {
+			left++
+		}
+	})
+	targets := make([]target, 0, left+sel.Volume())
+	sel.ForEach(func(p vec.IntVec3) {
+		if !dest.Contains(p) {
+			targets = append(targets, target{pos: p, block: blocks.Air})
+		}
+	})
+	sel.ForEach(func(p vec.IntVec3) {
+		targets = append(targets, target{pos: p.Add(offset), block: w.BlockAt(p)})
+	})
+	j, err := s.run(targets)
+	if err != nil {
+		return nil, err
+	}
+	s.SetPos1(dest.Min)
+	s.SetPos2(dest.Max)
+	return j, nil
+}
+
+// Undo reverts the most recent edit, the diff moves to the redo stack once applied.
+func (s *Session) Undo() (*Job, error) {
+	s.mu.Lock()
+	d, ok := s.history.popUndo()
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("nothing to undo")
+	}
+	targets := make([]target, d.Len())
+	for i := 0; i < d.Len(); i++ {
+		p, old, _ := d.at(d.Len() - 1 - i)
+		targets[i] = target{pos: p, block: old}
+	}
+	j := newJob(targets, s.user, func(*Diff) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.history.redo = append(s.history.redo, d)
+	})
+	s.editor.applier.submit(j)
+	return j, nil
+}
+
+// Redo reapplies the most recently undone edit.
+func (s *Session) Redo() (*Job, error) {
+	s.mu.Lock()
+	d, ok := s.history.popRedo()
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("nothing to redo")
+	}
+	targets := make([]target, d.Len())
+	for i := 0; i < d.Len(); i++ {
+		p, _, n := d.at(i)
+		targets[i] = target{pos: p, block: n}
+	}
+	j := newJob(targets, s.user, func(*Diff) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.history.undo = append(s.history.undo, d)
+	})
+	s.editor.applier.submit(j)
+	return j, nil
+}
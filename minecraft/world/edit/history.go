@@ -0,0 +1,87 @@
+package edit
+
+import (
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// diffEntry is 8 bytes: an offset from the diff origin plus the before and after block.
+type diffEntry struct {
+	dx, dy, dz int16
+	old, new   uint8
+}
+
+// Diff records only the blocks an edit actually changed.
+type Diff struct {
+	origin  vec.IntVec3
+	entries []diffEntry
+}
+
+func newDiff(origin vec.IntVec3) *Diff {
+	return &Diff{origin: origin}
+}
+
+func (d *Diff) record(p vec.IntVec3, old, new blocks.SimpleBlockType) {
+	d.entries = append(d.entries, diffEntry{
+		dx:  int16(p.X - d.origin.X),
+		dy:  int16(p.Y - d.origin.Y),
+		dz:  int16(p.Z - d.origin.Z),
+		old: uint8(old),
+		new: uint8(new),
+	})
+}
+
+// Len is the number of changed blocks.
+func (d *Diff) Len() int {
+	return len(d.entries)
+}
+
+func (d *Diff) at(i int) (p vec.IntVec3, old, new blocks.SimpleBlockType) {
+	e := d.entries[i]
+	p = vec.IntVec3{X: d.origin.X + int(e.dx), Y: d.origin.Y + int(e.dy), Z: d.origin.Z + int(e.dz)}
+	return p, blocks.SimpleBlockType(e.old), blocks.SimpleBlockType(e.new)
+}
+
+// History is one user's undo and redo stacks.
+type History struct {
+	limit int
+	undo  []*Diff
+	redo  []*Diff
+}
+
+func NewHistory(limit int) *History {
+	return &History{limit: limit}
+}
+
+// push records a fresh edit, which invalidates anything that could be redone.
+func (h *History) push(d *Diff) {
+	if d.Len() == 0 {
+		return
+	}
+	h.undo = append(h.undo, d)
+	if h.limit > 0 && len(h.undo) > h.limit {
+		h.undo = h.undo[len(h.undo)-h.limit:]
+	}
+	h.redo = nil
+}
+
+func (h *History) popUndo() (*Diff, bool) {
+	if len(h.undo) == 0 {
+		return nil, false
+	}
+	d := h.undo[len(h.undo)-1]
+	h.undo = h.undo[:len(h.undo)-1]
+	return d, true
+}
+
+func (h *History) popRedo() (*Diff, bool) {
+	if len(h.redo) == 0 {
+		return nil, false
+	}
+	d := h.redo[len(h.redo)-1]
+	h.redo = h.redo[:len(h.redo)-1]
+	return d, true
+}
+
+func (h *History) CanUndo() bool { return len(h.undo) > 0 }
+func (h *History) CanRedo() bool { return len(h.redo) > 0 }
@@ -0,0 +1,62 @@
+package edit
+
+import (
+	"math"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+)
+
+// Cuboid is an axis aligned box of blocks, Min and Max are both inclusive.
+type Cuboid struct {
+	Min, Max vec.IntVec3
+}
+
+// NewCuboid builds the box spanning two opposite corners in any order.
+func NewCuboid(a, b vec.IntVec3) Cuboid {
+	return Cuboid{
+		Min: vec.IntVec3{X: min(a.X, b.X), Y: min(a.Y, b.Y), Z: min(a.Z, b.Z)},
+		Max: vec.IntVec3{X: max(a.X, b.X), Y: max(a.Y, b.Y), Z: max(a.Z, b.Z)},
+	}
+}
+
+func (c Cuboid) Size() vec.IntVec3 {
+	return vec.IntVec3{X: c.Max.X - c.Min.X + 1, Y: c.Max.Y - c.Min.Y + 1, Z: c.Max.Z - c.Min.Z + 1}
+}
+
+// Volume is how many blocks the box holds, saturating at math.MaxInt for boxes too big to
+// count rather than overflowing.
+func (c Cuboid) Volume() int {
+	v := 1
+	for _, d := range [3]int{c.Max.X - c.Min.X, c.Max.Y - c.Min.Y, c.Max.Z - c.Min.Z} {
+		// d overflows int for far apart corners, but as Max >= Min it's right as a uint
+		side := uint(d) + 1
+		if side == 0 || side > math.MaxInt || v > math.MaxInt/int(side) {
+			return math.MaxInt
+		}
+		v *= int(side)
+	}
+	return v
+}
+
+func (c Cuboid) Contains(p vec.IntVec3) bool {
+	return p.X >= c.Min.X && p.X <= c.Max.X &&
+		p.Y >= c.Min.Y && p.Y <= c.Max.Y &&
+		p.Z >= c.Min.Z && p.Z <= c.Max.Z
+}
+
+func (c Cuboid) Shift(offset vec.IntVec3) Cuboid {
+	return Cuboid{Min: c.Min.Add(offset), Max: c.Max.Add(offset)}
+}
+
+// ForEach visits every block in Y, Z, X order. It counts from Min so a box ending at the
+// largest int still stops.
+func (c Cuboid) ForEach(f func(p vec.IntVec3)) {
+	s := c.Size()
+	for y := 0; y < s.Y; y++ {
+		for z := 0; z < s.Z; z++ {
+			for x := 0; x < s.X; x++ {
+				f(c.Min.Add(vec.IntVec3{X: x, Y: y, Z: z}))
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package journal
+
+import (
+	"time"
+
+	"github.com/dragon1672/go-mine/minecraft/command"
+	"github.com/dragon1672/go-mine/minecraft/world"
+)
+
+// maxListed is how many entries a query prints, newest first.
+const maxListed = 10
+
+// defaultSince is how far back /journal near looks when no duration is given.
+const defaultSince = time.Hour
+
+// RegisterCommands adds /journal for looking up and undoing changes to w.
+func RegisterCommands(reg *command.Registry, j *Journal, w *world.World) error {
+	list := func(ctx *command.Context, entries []Entry) {
+		if len(entries) == 0 {
+			ctx.Replyf("No changes found")
+			return
+		}
+		ctx.Replyf("%d changes, newest first:", len(entries))
+		for i := len(entries) - 1; i >= 0 && i >= len(entries)-maxListed; i-- {
+			e := entries[i]
+			ctx.Replyf("%s %s %v: %v -> %v", e.Time.Format(time.DateTime), actorName(e.Actor), e.Pos, e.Old, e.New)
+		}
+	}
+	// filter builds the rollback and restore filter, radius is around the source if given.
+	filter := func(ctx *command.Context) (Filter, error) {
+		f := Filter{Actor: ctx.String("player"), Since: j.now().Add(-ctx.Duration("since"))}
+		if ctx.Has("radius") {
+			pos, ok := ctx.Source.Position()
+			if !ok {
+				return Filter{}, command.Failed("%s has no position to take a radius around", ctx.Source.Name())
+			}
+			f.Center, f.Radius = pos.Floor(), ctx.Int("radius")
+		}
+		return f, nil
+	}
+	replayArgs := []command.Arg{command.Word("player"), command.Duration("since"), command.Optional(command.Int("radius", 1, 256))}
+
+	return reg.Register(&command.Command{
+		Name:        "journal",
+		Description: "looks up and undoes block changes",
+		Permission:  command.PermModerator,
+		Subcommands: []*command.Command{
+			{
+				Name:        "who",
+				Description: "lists who changed a block",
+				Permission:  command.PermModerator,
+				Args:        []command.Arg{command.Coords("pos")},
+				Run: func(ctx *command.Context) error {
+					list(ctx, j.At(ctx.Pos("pos")))
+					return nil
+				},
+			},
+			{
+				Name:        "near",
+				Description: "lists changes around you, within the last hour by default",
+				Permission:  command.PermModerator,
+				Args:        []command.Arg{command.Int("radius", 1, 256), command.Optional(command.Duration("since"))},
+				Run: func(ctx *command.Context) error {
+					pos, ok := ctx.Source.Position()
+					if !ok {
+						return command.Failed("%s has no position", ctx.Source.Name())
+					}
+					since := defaultSince
+					if ctx.Has("since") {
+						since = ctx.Duration("since")
+					}
+					list(ctx, j.Find(Filter{Since: j.now().Add(-since), Center: pos.Floor(), Radius: ctx.Int("radius")}))
+					return nil
+				},
+			},
+			{
+				Name:        "rollback",
+				Description: "undoes a player's changes since a time ago",
+				Permission:  command.PermAdmin,
+				Args:        replayArgs,
+				Run: func(ctx *command.Context) error {
+					f, err := filter(ctx)
+					if err != nil {
+						return err
+					}
+					ctx.Replyf("Rolled back %d blocks", j.Rollback(w, f))
+					return nil
+				},
+			},
+			{
+				Name:        "restore",
+				Description: "redoes changes undone by rollback",
+				Permission:  command.PermAdmin,
+				Args:        replayArgs,
+				Run: func(ctx *command.Context) error {
+					f, err := filter(ctx)
+					if err != nil {
+						return err
+					}
+					ctx.Replyf("Restored %d blocks", j.Restore(w, f))
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func actorName(actor string) string {
+	if actor == "" {
+		return "(world)"
+	}
+	return actor
+}
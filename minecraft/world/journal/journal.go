@@ -0,0 +1,238 @@
+// Package journal keeps an append-only log of every block change so griefing can be
+// looked up and undone, even across restarts.
+package journal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+	"github.com/golang/glog"
+)
+
+const fileName = "journal.log"
+
+// headerSize is the fixed part of a record: time, position, old and new block.
+const headerSize = 8 + 3*4 + 2
+
+// maxActorLength keeps records small, longer actors are cut.
+const maxActorLength = 64
+
+// Entry is one recorded block change.
+type Entry struct {
+	Time     time.Time
+	Actor    string
+	Pos      vec.IntVec3
+	Old, New blocks.SimpleBlockType
+}
+
+// Journal is the on disk log plus an in memory copy for queries. It is safe for concurrent use.
+//
+// Records are a 16 bit big endian length followed by the entry. A record cut short by
+// a crash is dropped when the journal is next opened.
+type Journal struct {
+	path string
+	// now is the clock Attach stamps changes with, swapped out by tests.
+	now func() time.Time
+
+	mu      sync.Mutex
+	f       *os.File
+	w       *bufio.Writer
+	entries []Entry
+}
+
+// Open loads the journal in dir, creating it if needed.
+func Open(dir string) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	j := &Journal{path: filepath.Join(dir, fileName), now: time.Now}
+	f, err := os.OpenFile(j.path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening journal: %v", err)
+	}
+	entries, size, err := readEntries(bufio.NewReader(f))
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error reading journal: %v", err)
+	}
+	if st, err := f.Stat(); err == nil && st.Size() != size {
+		glog.Warningf("Dropping %d bytes of torn journal record", st.Size()-size)
+		if err := f.Truncate(size); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("error truncating journal: %v", err)
+		}
+	}
+	if _, err := f.Seek(size, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	j.f, j.w, j.entries = f, bufio.NewWriter(f), entries
+	return j, nil
+}
+
+// readEntries decodes records until the end of r or the first incomplete one,
+// returning how many bytes were good.
+func readEntries(r io.Reader) (entries []Entry, size int64, err error) {
+	var lenBuf [2]byte
+	for {
+		if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return entries, size, nil
+			}
+			return nil, 0, err
+		}
+		n := int(binary.BigEndian.Uint16(lenBuf[:]))
+		if n < headerSize {
+			return entries, size, nil
+		}
+		rec := make([]byte, n)
+		if _, err := io.ReadFull(r, rec); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return entries, size, nil
+			}
+			return nil, 0, err
+		}
+		entries = append(entries, decode(rec))
+		size += int64(2 + n)
+	}
+}
+
+func encode(e Entry) []byte {
+	actor := e.Actor
+	if len(actor) > maxActorLength {
+		actor = actor[:maxActorLength]
+	}
+	b := make([]byte, 0, 2+headerSize+len(actor))
+	b = binary.BigEndian.AppendUint16(b, uint16(headerSize+len(actor)))
+	b = binary.BigEndian.AppendUint64(b, uint64(e.Time.UnixNano()))
+	for _, v := range []int{e.Pos.X, e.Pos.Y, e.Pos.Z} {
+		b = binary.BigEndian.AppendUint32(b, uint32(int32(v)))
+	}
+	b = append(b, uint8(e.Old), uint8(e.New))
+	return append(b, actor...)
+}
+
+func decode(rec []byte) Entry {
+	coord := func(i int) int { return int(int32(binary.BigEndian.Uint32(rec[8+4*i:]))) }
+	return Entry{
+		Time:  time.Unix(0, int64(binary.BigEndian.Uint64(rec))),
+		Pos:   vec.IntVec3{X: coord(0), Y: coord(1), Z: coord(2)},
+		Old:   blocks.SimpleBlockType(rec[20]),
+		New:   blocks.SimpleBlockType(rec[21]),
+		Actor: string(rec[headerSize:]),
+	}
+}
+
+// Record appends e. It is buffered until Flush or Close.
+func (j *Journal) Record(e Entry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return errors.New("journal is closed")
+	}
+	if _, err := j.w.Write(encode(e)); err != nil {
+		return fmt.Errorf("error writing journal: %v", err)
+	}
+	j.entries = append(j.entries, e)
+	return nil
+}
+
+// Attach records every change made to w from now on.
+func (j *Journal) Attach(w *world.World) {
+	w.OnChange(func(c world.BlockChange) {
+		err := j.Record(Entry{Time: j.now(), Actor: c.Actor, Pos: c.Pos, Old: c.Old, New: c.New})
+		if err != nil {
+			glog.Errorf("Block change at %v not journaled: %v", c.Pos, err)
+		}
+	})
+}
+
+// Flush writes buffered records to the file.
+func (j *Journal) Flush() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return nil
+	}
+	return j.w.Flush()
+}
+
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return nil
+	}
+	err := j.w.Flush()
+	if cerr := j.f.Close(); err == nil {
+		err = cerr
+	}
+	j.f = nil
+	return err
+}
+
+// Len is how many entries the journal holds.
+func (j *Journal) Len() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.entries)
+}
+
+// Compact drops entries older than before and rewrites the file without them,
+// returning how many were dropped.
+func (j *Journal) Compact(before time.Time) (int, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.f == nil {
+		return 0, errors.New("journal is closed")
+	}
+	// entries are in the order they were recorded, which may not quite be time order
+	// if the clock jumped, so check them all
+	var kept []Entry
+	for _, e := range j.entries {
+		if !e.Time.Before(before) {
+			kept = append(kept, e)
+		}
+	}
+	dropped := len(j.entries) - len(kept)
+	if dropped == 0 {
+		return 0, nil
+	}
+	if err := j.w.Flush(); err != nil {
+		return 0, fmt.Errorf("error writing journal: %v", err)
+	}
+	tmp := j.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return 0, fmt.Errorf("error compacting journal: %v", err)
+	}
+	w := bufio.NewWriter(f)
+	for _, e := range kept {
+		w.Write(encode(e))
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return 0, fmt.Errorf("error compacting journal: %v", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return 0, fmt.Errorf("error compacting journal: %v", err)
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		f.Close()
+		return 0, fmt.Errorf("error compacting journal: %v", err)
+	}
+	j.f.Close()
+	j.f, j.w, j.entries = f, bufio.NewWriter(f), kept
+	return dropped, nil
+}
@@ -0,0 +1,226 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dragon1672/go-mine/minecraft/command"
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+// sky is far above generated terrain so every block starts as air.
+const sky = 200
+
+func p(x, y, z int) vec.IntVec3 {
+	return vec.IntVec3{X: x, Y: sky + y, Z: z}
+}
+
+var start = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// fakeClock lets tests move time forward between changes.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func open(t *testing.T, dir string) (*Journal, *fakeClock) {
+	t.Helper()
+	j, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open() err: %v", err)
+	}
+	t.Cleanup(func() { j.Close() })
+	clock := &fakeClock{t: start}
+	j.now = clock.now
+	return j, clock
+}
+
+func TestSurvivesRestart(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	j, _ := open(t, dir)
+	w := world.New(1)
+	j.Attach(w)
+	w.SetBlockBy(p(0, 0, 0), blocks.Stone, "alex")
+	w.SetBlockBy(p(-5, 1, 3), blocks.Wood, "sam")
+	if err := j.Close(); err != nil {
+		t.Fatalf("Close() err: %v", err)
+	}
+
+	// a crash part way through a record leaves a torn tail
+	path := filepath.Join(dir, fileName)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(encode(Entry{Actor: "torn", Pos: p(1, 1, 1)})[:10])
+	f.Close()
+
+	j, _ = open(t, dir)
+	want := []Entry{
+		{Time: start, Actor: "alex", Pos: p(0, 0, 0), Old: blocks.Air, New: blocks.Stone},
+		{Time: start, Actor: "sam", Pos: p(-5, 1, 3), Old: blocks.Air, New: blocks.Wood},
+	}
+	got := j.Find(Filter{})
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries after reopening, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Time.Equal(want[i].Time) || got[i].Actor != want[i].Actor || got[i].Pos != want[i].Pos ||
+			got[i].Old != want[i].Old || got[i].New != want[i].New {
+			t.Errorf("entry %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	// new records land after the truncated tail rather than behind it
+	j.Record(Entry{Time: start, Actor: "alex", Pos: p(2, 0, 0), New: blocks.Sand})
+	j.Close()
+	j, _ = open(t, dir)
+	if j.Len() != 3 {
+		t.Errorf("got %d entries, want 3", j.Len())
+	}
+}
+
+func TestQueries(t *testing.T) {
+	t.Parallel()
+	j, clock := open(t, t.TempDir())
+	w := world.New(1)
+	j.Attach(w)
+	w.SetBlockBy(p(0, 0, 0), blocks.Stone, "alex")
+	clock.t = start.Add(time.Hour)
+	w.SetBlockBy(p(0, 0, 0), blocks.Wood, "sam")
+	w.SetBlockBy(p(30, 0, 0), blocks.Sand, "sam")
+
+	if got := j.At(p(0, 0, 0)); len(got) != 2 || got[1].Actor != "sam" {
+		t.Errorf("At() = %+v, want alex then sam", got)
+	}
+	for _, tc := range []struct {
+		name string
+		f    Filter
+		want int
+	}{
+		{name: "Everything", want: 3},
+		{name: "Actor", f: Filter{Actor: "SAM"}, want: 2},
+		{name: "Since", f: Filter{Since: start.Add(time.Minute)}, want: 2},
+		{name: "Until", f: Filter{Until: start.Add(time.Minute)}, want: 1},
+		{name: "Radius", f: Filter{Center: p(2, 0, 0), Radius: 5}, want: 2},
+		{name: "All of them", f: Filter{Actor: "sam", Since: start.Add(time.Minute), Center: p(2, 0, 0), Radius: 5}, want: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := j.Find(tc.f); len(got) != tc.want {
+				t.Errorf("Find(%+v) got %d entries, want %d", tc.f, len(got), tc.want)
+			}
+		})
+	}
+}
+
+func TestRollbackRestore(t *testing.T) {
+	t.Parallel()
+	j, clock := open(t, t.TempDir())
+	w := world.New(1)
+	j.Attach(w)
+	w.SetBlockBy(p(0, 0, 0), blocks.Wood, "alex")
+	w.SetBlockBy(p(1, 0, 0), blocks.Wood, "alex")
+	clock.t = start.Add(time.Hour)
+	// the griefer replaces one block twice and breaks another
+	w.SetBlockBy(p(0, 0, 0), blocks.Sand, "griefer")
+	w.SetBlockBy(p(0, 0, 0), blocks.Stone, "griefer")
+	w.SetBlockBy(p(1, 0, 0), blocks.Air, "griefer")
+	w.SetBlockBy(p(2, 0, 0), blocks.Sand, "griefer")
+	// and alex has since built over one of the griefer's blocks
+	w.SetBlockBy(p(2, 0, 0), blocks.Wood, "alex")
+
+	f := Filter{Actor: "griefer", Since: start.Add(time.Minute)}
+	if n := j.Rollback(w, f); n != 3 {
+		t.Errorf("Rollback() changed %d blocks, want 3", n)
+	}
+	for pos, want := range map[vec.IntVec3]blocks.SimpleBlockType{p(0, 0, 0): blocks.Wood, p(1, 0, 0): blocks.Wood, p(2, 0, 0): blocks.Wood} {
+		if got := w.BlockAt(pos); got != want {
+			t.Errorf("after rollback %v = %v, want %v", pos, got, want)
+		}
+	}
+	if got := j.At(p(1, 0, 0)); got[len(got)-1].Actor != RollbackActor {
+		t.Errorf("rollback should be journaled, got %+v", got[len(got)-1])
+	}
+
+	if n := j.Restore(w, f); n != 3 {
+		t.Errorf("Restore() changed %d blocks, want 3", n)
+	}
+	for pos, want := range map[vec.IntVec3]blocks.SimpleBlockType{p(0, 0, 0): blocks.Stone, p(1, 0, 0): blocks.Air, p(2, 0, 0): blocks.Wood} {
+		if got := w.BlockAt(pos); got != want {
+			t.Errorf("after restore %v = %v, want %v", pos, got, want)
+		}
+	}
+}
+
+func TestCompact(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	j, clock := open(t, dir)
+	for i := 0; i < 10; i++ {
+		clock.t = start.Add(time.Duration(i) * time.Hour)
+		j.Record(Entry{Time: clock.t, Actor: "alex", Pos: p(i, 0, 0), New: blocks.Stone})
+	}
+	dropped, err := j.Compact(start.Add(4 * time.Hour))
+	if err != nil {
+		t.Fatalf("Compact() err: %v", err)
+	}
+	if dropped != 4 || j.Len() != 6 {
+		t.Errorf("Compact() dropped %d leaving %d, want 4 and 6", dropped, j.Len())
+	}
+	j.Record(Entry{Time: clock.t, Actor: "sam", Pos: p(0, 0, 0), New: blocks.Sand})
+	j.Close()
+
+	j, _ = open(t, dir)
+	got := j.Find(Filter{})
+	if len(got) != 7 || got[0].Pos != p(4, 0, 0) || got[6].Actor != "sam" {
+		t.Errorf("after reopening got %+v", got)
+	}
+}
+
+type testPlayer struct {
+	replies []string
+}
+
+func (t *testPlayer) Name() string               { return "mod" }
+func (t *testPlayer) Permission() int            { return command.PermAdmin }
+func (t *testPlayer) Position() (vec.Vec3, bool) { return p(0, 0, 0).Center(), true }
+func (t *testPlayer) Reply(msg string)           { t.replies = append(t.replies, msg) }
+
+func TestCommands(t *testing.T) {
+	t.Parallel()
+	j, clock := open(t, t.TempDir())
+	w := world.New(1)
+	j.Attach(w)
+	reg := command.NewRegistry()
+	if err := RegisterCommands(reg, j, w); err != nil {
+		t.Fatalf("RegisterCommands() err: %v", err)
+	}
+	w.SetBlockBy(p(0, 0, 0), blocks.Stone, "griefer")
+	clock.t = start.Add(2 * time.Hour)
+	src := &testPlayer{}
+
+	if err := reg.Execute(src, "journal who ~ ~ ~"); err != nil {
+		t.Fatalf("who: %v", err)
+	}
+	if last := src.replies[len(src.replies)-1]; !strings.Contains(last, "griefer") {
+		t.Errorf("who reply %q does not name the griefer", last)
+	}
+	if err := reg.Execute(src, "journal near 5"); err != nil {
+		t.Fatalf("near: %v", err)
+	}
+	if last := src.replies[len(src.replies)-1]; last != "No changes found" {
+		t.Errorf("change from two hours ago should be outside the default hour, got %q", last)
+	}
+	if err := reg.Execute(src, "journal rollback griefer 3h 5"); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if w.BlockAt(p(0, 0, 0)) != blocks.Air {
+		t.Errorf("rollback command did not undo the change")
+	}
+}
@@ -0,0 +1,116 @@
+package journal
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+)
+
+const (
+	// RollbackActor and RestoreActor are who changes made by Rollback and Restore are journaled as.
+	RollbackActor = "#rollback"
+	RestoreActor  = "#restore"
+)
+
+// Filter picks entries, zero fields match everything.
+type Filter struct {
+	Actor        string
+	Since, Until time.Time
+	// Radius limits entries to a cube around Center when above 0.
+	Center vec.IntVec3
+	Radius int
+}
+
+func (f Filter) match(e Entry) bool {
+	if f.Actor != "" && !strings.EqualFold(f.Actor, e.Actor) {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && e.Time.After(f.Until) {
+		return false
+	}
+	if f.Radius > 0 {
+		d := e.Pos.Sub(f.Center)
+		if max(abs(d.X), abs(d.Y), abs(d.Z)) > f.Radius {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// Find returns the entries matching f, oldest first.
+func (j *Journal) Find(f Filter) []Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var out []Entry
+	for _, e := range j.entries {
+		if f.match(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// At is the history of one block, oldest first.
+func (j *Journal) At(pos vec.IntVec3) []Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var out []Entry
+	for _, e := range j.entries {
+		if e.Pos == pos {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Rollback undoes the changes matching f, newest first, and returns how many blocks
+// it changed. A block that has since been changed by someone else is left alone.
+func (j *Journal) Rollback(w *world.World, f Filter) int {
+	entries := j.replayable(f)
+	changed := 0
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if w.BlockAt(e.Pos) == e.New {
+			w.SetBlockBy(e.Pos, e.Old, RollbackActor)
+			changed++
+		}
+	}
+	return changed
+}
+
+// Restore redoes the changes matching f, oldest first, so it reverses a Rollback
+// with the same filter.
+func (j *Journal) Restore(w *world.World, f Filter) int {
+	changed := 0
+	for _, e := range j.replayable(f) {
+		if w.BlockAt(e.Pos) == e.Old {
+			w.SetBlockBy(e.Pos, e.New, RestoreActor)
+			changed++
+		}
+	}
+	return changed
+}
+
+// replayable is Find without the journal's own rollbacks and restores, which are
+// undone by running the opposite operation instead.
+func (j *Journal) replayable(f Filter) []Entry {
+	var out []Entry
+	for _, e := range j.Find(f) {
+		if e.Actor != RollbackActor && e.Actor != RestoreActor {
+			out = append(out, e)
+		}
+	}
+	return out
+}
@@ -0,0 +1,118 @@
+package pathfinding
+
+import (
+	"context"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+)
+
+// Navigator keeps a path to a goal up to date as the world changes around it.
+// Only the part of the path after the first broken step is searched again.
+type Navigator struct {
+	world  World
+	entity Entity
+	opts   Options
+
+	goal  vec.IntVec3
+	path  Path
+	dirty bool
+}
+
+func NewNavigator(w World, e Entity, opts Options) *Navigator {
+	return &Navigator{
+		world:  w,
+		entity: e,
+		opts:   opts,
+	}
+}
+
+// SetGoal computes a fresh path from the entity's current position.
+func (n *Navigator) SetGoal(ctx context.Context, from, goal vec.IntVec3) error {
+	n.goal = goal
+	n.dirty = false
+	p, err := FindPath(ctx, n.world, n.entity, from, goal, n.opts)
+	if err != nil {
+		n.path = nil
+		return err
+	}
+	n.path = p
+	return nil
+}
+
+func (n *Navigator) Path() Path {
+	return n.path
+}
+
+// BlockChanged flags the path for revalidation if pos is close enough to matter.
+func (n *Navigator) BlockChanged(pos vec.IntVec3) {
+	if n.dirty || len(n.path) == 0 {
+		return
+	}
+	reach := n.entity.MaxStepUp
+	if n.entity.MaxFall > reach {
+		reach = n.entity.MaxFall
+	}
+	for i := 0; i < len(n.path); i++ {
+		a, b := n.path[i], n.path[i]
+		if i+1 < len(n.path) {
+			b = n.path[i+1]
+		}
+		lo := vec.IntVec3{X: min(a.X, b.X), Y: min(a.Y, b.Y) - 1, Z: min(a.Z, b.Z)}
+		hi := vec.IntVec3{
+			X: max(a.X, b.X) + n.entity.Width - 1,
+			Y: max(a.Y, b.Y) + n.entity.Height - 1 + reach,
+			Z: max(a.Z, b.Z) + n.entity.Width - 1,
+		}
+		if pos.X >= lo.X && pos.X <= hi.X && pos.Y >= lo.Y && pos.Y <= hi.Y && pos.Z >= lo.Z && pos.Z <= hi.Z {
+			n.dirty = true
+			return
+		}
+	}
+}
+
+// Update revalidates a dirty path from the entity's current position.
+// The still valid prefix is kept and only the rest is searched again.
+func (n *Navigator) Update(ctx context.Context, from vec.IntVec3) error {
+	if !n.dirty {
+		return nil
+	}
+	start := 0
+	for i, p := range n.path {
+		if p == from {
+			start = i
+			break
+		}
+	}
+	if len(n.path) == 0 || n.path[start] != from {
+		return n.SetGoal(ctx, from, n.goal)
+	}
+
+	broken := -1
+	for i := start; i < len(n.path); i++ {
+		if !n.entity.Standable(n.world, n.path[i]) {
+			broken = i
+			break
+		}
+		if i > start && !n.entity.canMove(n.world, n.path[i-1], n.path[i]) {
+			broken = i
+			break
+		}
+	}
+	n.dirty = false
+	if broken == -1 {
+		n.path = n.path[start:]
+		return nil
+	}
+	if broken == start {
+		return n.SetGoal(ctx, from, n.goal)
+	}
+
+	resume := broken - 1
+	tail, err := FindPath(ctx, n.world, n.entity, n.path[resume], n.goal, n.opts)
+	if err != nil {
+		// the detour might need to start further back, try from scratch
+		return n.SetGoal(ctx, from, n.goal)
+	}
+	n.path = append(append(Path{}, n.path[start:resume]...), tail...)
+	return nil
+}
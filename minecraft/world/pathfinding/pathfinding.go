@@ -0,0 +1,243 @@
+package pathfinding
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+var (
+	ErrNoPath    = errors.New("no path to goal")
+	ErrNodeLimit = errors.New("search node limit reached")
+)
+
+// World is the read only view of blocks the search runs against.
+type World interface {
+	BlockAt(pos vec.IntVec3) blocks.SimpleBlockType
+}
+
+// Entity describes the footprint of whatever is walking.
+// Positions are the block the entity's feet occupy at its minimum X/Z corner.
+type Entity struct {
+	Width, Height int
+	MaxStepUp     int
+	MaxFall       int
+}
+
+type Options struct {
+	// MaxNodes caps how many nodes are expanded before giving up, 0 means no limit.
+	MaxNodes int
+}
+
+// Path is the list of feet positions from start to goal, both inclusive.
+type Path []vec.IntVec3
+
+const ctxCheckInterval = 64
+
+var horizontalDirs = []vec.IntVec3{
+	{X: 1}, {X: -1}, {Z: 1}, {Z: -1},
+}
+
+func (e Entity) footprint(pos vec.IntVec3, yFrom, yTo int, f func(p vec.IntVec3) bool) bool {
+	for x := 0; x < e.Width; x++ {
+		for z := 0; z < e.Width; z++ {
+			for y := yFrom; y <= yTo; y++ {
+				if !f(vec.IntVec3{X: pos.X + x, Y: pos.Y + y, Z: pos.Z + z}) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// clear reports whether the entity's body fits at pos, fluids count as blocked.
+func (e Entity) clear(w World, pos vec.IntVec3) bool {
+	return e.footprint(pos, 0, e.Height-1, func(p vec.IntVec3) bool {
+		b := w.BlockAt(p)
+		return !b.IsSolid() && !b.IsFluid()
+	})
+}
+
+// Standable reports whether the entity can stand at pos.
+func (e Entity) Standable(w World, pos vec.IntVec3) bool {
+	if !e.clear(w, pos) {
+		return false
+	}
+	supported := false
+	noFluid := e.footprint(pos, -1, -1, func(p vec.IntVec3) bool {
+		b := w.BlockAt(p)
+		supported = supported || b.IsSolid()
+		return !b.IsFluid()
+	})
+	return supported && noFluid
+}
+
+type move struct {
+	to   vec.IntVec3
+	cost float64
+}
+
+// moves lists the positions reachable in one step from pos.
+func (e Entity) moves(w World, pos vec.IntVec3) []move {
+	var out []move
+	for _, d := range horizontalDirs {
+		next := vec.IntVec3{X: pos.X + d.X, Y: pos.Y, Z: pos.Z + d.Z}
+		if e.clear(w, next) {
+			if e.Standable(w, next) {
+				out = append(out, move{to: next, cost: 1})
+				continue
+			}
+			// walk off the edge and fall until something catches us
+			for fall := 1; fall <= e.MaxFall; fall++ {
+				below := next.Add(vec.IntVec3{Y: -fall})
+				if !e.clear(w, below) {
+					break
+				}
+				if e.Standable(w, below) {
+					out = append(out, move{to: below, cost: 1 + 0.1*float64(fall)})
+					break
+				}
+			}
+			continue
+		}
+		for up := 1; up <= e.MaxStepUp; up++ {
+			// need headroom above where we are now to climb
+			if !e.clear(w, pos.Add(vec.IntVec3{Y: up})) {
+				break
+			}
+			above := next.Add(vec.IntVec3{Y: up})
+			if e.Standable(w, above) {
+				out = append(out, move{to: above, cost: 1 + 0.5*float64(up)})
+				break
+			}
+		}
+	}
+	return out
+}
+
+func (e Entity) canMove(w World, from, to vec.IntVec3) bool {
+	for _, m := range e.moves(w, from) {
+		if m.to == to {
+			return true
+		}
+	}
+	return false
+}
+
+func heuristic(a, b vec.IntVec3) float64 {
+	// Vertical moves are cheap, only horizontal distance is guaranteed to be paid.
+	return float64(abs(a.X-b.X) + abs(a.Z-b.Z))
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+type node struct {
+	pos    vec.IntVec3
+	g, f   float64
+	parent *node
+	index  int
+}
+
+type openSet []*node
+
+func (o openSet) Len() int { return len(o) }
+func (o openSet) Less(i, j int) bool {
+	if o[i].f == o[j].f {
+		return o[i].g > o[j].g // prefer nodes closer to the goal on ties
+	}
+	return o[i].f < o[j].f
+}
+func (o openSet) Swap(i, j int) {
+	o[i], o[j] = o[j], o[i]
+	o[i].index = i
+	o[j].index = j
+}
+func (o *openSet) Push(x any) {
+	n := x.(*node)
+	n.index = len(*o)
+	*o = append(*o, n)
+}
+func (o *openSet) Pop() any {
+	old := *o
+	n := old[len(old)-1]
+	*o = old[:len(old)-1]
+	n.index = -1
+	return n
+}
+
+// FindPath runs A* from start to goal.
+func FindPath(ctx context.Context, w World, e Entity, start, goal vec.IntVec3, opts Options) (Path, error) {
+	if !e.Standable(w, start) {
+		return nil, fmt.Errorf("start %v is not standable", start)
+	}
+	if !e.Standable(w, goal) {
+		return nil, fmt.Errorf("goal %v is not standable: %w", goal, ErrNoPath)
+	}
+	open := &openSet{}
+	nodes := map[vec.IntVec3]*node{}
+	closed := map[vec.IntVec3]bool{}
+
+	first := &node{pos: start, f: heuristic(start, goal)}
+	nodes[start] = first
+	heap.Push(open, first)
+
+	expanded := 0
+	for open.Len() > 0 {
+		if expanded%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		if opts.MaxNodes > 0 && expanded >= opts.MaxNodes {
+			return nil, ErrNodeLimit
+		}
+		cur := heap.Pop(open).(*node)
+		if cur.pos == goal {
+			return buildPath(cur), nil
+		}
+		closed[cur.pos] = true
+		expanded++
+
+		for _, m := range e.moves(w, cur.pos) {
+			if closed[m.to] {
+				continue
+			}
+			g := cur.g + m.cost
+			n, ok := nodes[m.to]
+			if !ok {
+				n = &node{pos: m.to, g: g, f: g + heuristic(m.to, goal), parent: cur}
+				nodes[m.to] = n
+				heap.Push(open, n)
+				continue
+			}
+			if g < n.g {
+				n.g = g
+				n.f = g + heuristic(m.to, goal)
+				n.parent = cur
+				heap.Fix(open, n.index)
+			}
+		}
+	}
+	return nil, ErrNoPath
+}
+
+func buildPath(n *node) Path {
+	var p Path
+	for ; n != nil; n = n.parent {
+		p = append(p, n.pos)
+	}
+	for i, j := 0, len(p)-1; i < j; i, j = i+1, j-1 {
+		p[i], p[j] = p[j], p[i]
+	}
+	return p
+}
@@ -0,0 +1,205 @@
+package pathfinding
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+type testWorld map[vec.IntVec3]blocks.SimpleBlockType
+
+func (w testWorld) BlockAt(pos vec.IntVec3) blocks.SimpleBlockType {
+	return w[pos]
+}
+
+// floor makes a stone floor at y=0 covering x and z in [0, size).
+func floor(size int) testWorld {
+	w := testWorld{}
+	for x := 0; x < size; x++ {
+		for z := 0; z < size; z++ {
+			w[vec.IntVec3{X: x, Y: 0, Z: z}] = blocks.Stone
+		}
+	}
+	return w
+}
+
+var mob = Entity{Width: 1, Height: 2, MaxStepUp: 1, MaxFall: 3}
+
+func TestFindPath(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	start := vec.IntVec3{X: 0, Y: 1, Z: 0}
+
+	t.Run("Flat", func(t *testing.T) {
+		w := floor(8)
+		p, err := FindPath(ctx, w, mob, start, vec.IntVec3{X: 5, Y: 1, Z: 0}, Options{})
+		if err != nil {
+			t.Fatalf("FindPath() err: %v", err)
+		}
+		if len(p) != 6 {
+			t.Errorf("expected straight path of 6 nodes, got %v", p)
+		}
+	})
+
+	t.Run("Step up", func(t *testing.T) {
+		w := floor(8)
+		for z := 0; z < 8; z++ {
+			w[vec.IntVec3{X: 3, Y: 1, Z: z}] = blocks.Stone
+			w[vec.IntVec3{X: 4, Y: 1, Z: z}] = blocks.Stone
+		}
+		goal := vec.IntVec3{X: 4, Y: 2, Z: 0}
+		p, err := FindPath(ctx, w, mob, start, goal, Options{})
+		if err != nil {
+			t.Fatalf("FindPath() err: %v", err)
+		}
+		if p[len(p)-1] != goal {
+			t.Errorf("path ended at %v, want %v", p[len(p)-1], goal)
+		}
+	})
+
+	t.Run("Wall too tall", func(t *testing.T) {
+		w := floor(8)
+		for z := 0; z < 8; z++ {
+			w[vec.IntVec3{X: 3, Y: 1, Z: z}] = blocks.Stone
+			w[vec.IntVec3{X: 3, Y: 2, Z: z}] = blocks.Stone
+		}
+		_, err := FindPath(ctx, w, mob, start, vec.IntVec3{X: 5, Y: 1, Z: 0}, Options{})
+		if !errors.Is(err, ErrNoPath) {
+			t.Errorf("expected ErrNoPath, got %v", err)
+		}
+	})
+
+	t.Run("Fall height", func(t *testing.T) {
+		for _, tc := range []struct {
+			drop    int
+			wantErr error
+		}{
+			{drop: 3},
+			{drop: 4, wantErr: ErrNoPath},
+		} {
+			w := testWorld{}
+			top := tc.drop
+			for x := 0; x < 3; x++ {
+				w[vec.IntVec3{X: x, Y: top}] = blocks.Stone
+			}
+			w[vec.IntVec3{X: 3, Y: 0}] = blocks.Stone
+			_, err := FindPath(ctx, w, mob, vec.IntVec3{Y: top + 1}, vec.IntVec3{X: 3, Y: 1}, Options{})
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("drop %d: got err %v, want %v", tc.drop, err, tc.wantErr)
+			}
+		}
+	})
+
+	t.Run("Avoids water", func(t *testing.T) {
+		w := floor(5)
+		for x := 0; x < 4; x++ {
+			w[vec.IntVec3{X: x, Y: 0, Z: 2}] = blocks.Water
+		}
+		p, err := FindPath(ctx, w, mob, start, vec.IntVec3{X: 0, Y: 1, Z: 4}, Options{})
+		if err != nil {
+			t.Fatalf("FindPath() err: %v", err)
+		}
+		for _, n := range p {
+			if n.Z == 2 && n.X != 4 {
+				t.Errorf("path crosses water at %v", n)
+			}
+		}
+	})
+
+	t.Run("Tall entity needs headroom", func(t *testing.T) {
+		w := floor(5)
+		for z := 0; z < 5; z++ {
+			w[vec.IntVec3{X: 2, Y: 3, Z: z}] = blocks.Stone
+		}
+		goal := vec.IntVec3{X: 4, Y: 1, Z: 0}
+		if _, err := FindPath(ctx, w, mob, start, goal, Options{}); err != nil {
+			t.Errorf("2 high mob should fit under the ceiling: %v", err)
+		}
+		tall := mob
+		tall.Height = 3
+		if _, err := FindPath(ctx, w, tall, start, goal, Options{}); !errors.Is(err, ErrNoPath) {
+			t.Errorf("3 high mob should not fit, got %v", err)
+		}
+	})
+
+	t.Run("Wide entity", func(t *testing.T) {
+		w := floor(6)
+		for z := 0; z < 6; z++ {
+			if z != 2 {
+				w[vec.IntVec3{X: 3, Y: 1, Z: z}] = blocks.Stone
+				w[vec.IntVec3{X: 3, Y: 2, Z: z}] = blocks.Stone
+			}
+		}
+		wide := mob
+		wide.Width = 2
+		if _, err := FindPath(ctx, w, wide, start, vec.IntVec3{X: 4, Y: 1, Z: 0}, Options{}); !errors.Is(err, ErrNoPath) {
+			t.Errorf("2 wide mob should not fit through a 1 wide gap, got %v", err)
+		}
+	})
+
+	t.Run("Node limit", func(t *testing.T) {
+		w := floor(32)
+		_, err := FindPath(ctx, w, mob, start, vec.IntVec3{X: 31, Y: 1, Z: 31}, Options{MaxNodes: 10})
+		if !errors.Is(err, ErrNodeLimit) {
+			t.Errorf("expected ErrNodeLimit, got %v", err)
+		}
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		cctx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := FindPath(cctx, floor(8), mob, start, vec.IntVec3{X: 5, Y: 1, Z: 5}, Options{})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+}
+
+func TestNavigator(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	w := floor(8)
+	start := vec.IntVec3{X: 0, Y: 1, Z: 3}
+	goal := vec.IntVec3{X: 7, Y: 1, Z: 3}
+	n := NewNavigator(w, mob, Options{})
+	if err := n.SetGoal(ctx, start, goal); err != nil {
+		t.Fatalf("SetGoal() err: %v", err)
+	}
+
+	t.Run("Far change keeps path", func(t *testing.T) {
+		before := len(n.Path())
+		n.BlockChanged(vec.IntVec3{X: 0, Y: 1, Z: 7})
+		if err := n.Update(ctx, start); err != nil {
+			t.Fatalf("Update() err: %v", err)
+		}
+		if len(n.Path()) != before {
+			t.Errorf("path changed from %d to %d nodes", before, len(n.Path()))
+		}
+	})
+
+	t.Run("Wall on path reroutes", func(t *testing.T) {
+		for y := 1; y <= 2; y++ {
+			pos := vec.IntVec3{X: 4, Y: y, Z: 3}
+			w[pos] = blocks.Stone
+			n.BlockChanged(pos)
+		}
+		if err := n.Update(ctx, start); err != nil {
+			t.Fatalf("Update() err: %v", err)
+		}
+		p := n.Path()
+		if p[0] != start || p[len(p)-1] != goal {
+			t.Fatalf("path runs %v -> %v, want %v -> %v", p[0], p[len(p)-1], start, goal)
+		}
+		for i, node := range p {
+			if node.X == 4 && node.Z == 3 {
+				t.Errorf("path still goes through the wall at %v", node)
+			}
+			if i > 0 && !mob.canMove(w, p[i-1], node) {
+				t.Errorf("invalid step %v -> %v", p[i-1], node)
+			}
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package save
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+)
+
+const chunkDir = "chunks"
+
+// ChunkStore keeps chunks in dir as one file each, see world.EncodeChunk.
+// It implements world.Storage.
+type ChunkStore struct {
+	dir string
+}
+
+var _ world.Storage = (*ChunkStore)(nil)
+
+func NewChunkStore(dir string) *ChunkStore {
+	return &ChunkStore{dir: dir}
+}
+
+func (s *ChunkStore) path(pos vec.IntVec3) string {
+	return filepath.Join(s.dir, chunkDir, fmt.Sprintf("%d_%d_%d.chunk", pos.X, pos.Y, pos.Z))
+}
+
+func (s *ChunkStore) SaveChunk(c *world.Chunk) error {
+	data, err := world.EncodeChunk(c)
+	if err != nil {
+		return err
+	}
+	if err := writeAtomic(s.path(c.Pos), data); err != nil {
+		return fmt.Errorf("error writing chunk %v: %v", c.Pos, err)
+	}
+	return nil
+}
+
+// LoadChunk reads a saved chunk, ok is false if it was never saved.
+func (s *ChunkStore) LoadChunk(pos vec.IntVec3) (c *world.Chunk, ok bool, err error) {
+	data, err := os.ReadFile(s.path(pos))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("error reading chunk %v: %v", pos, err)
+	}
+	c, err = world.DecodeChunk(pos, data)
+	if err != nil {
+		return nil, false, err
+	}
+	return c, true, nil
+}
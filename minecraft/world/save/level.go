@@ -0,0 +1,57 @@
+package save
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const levelFile = "level.json"
+
+// Level is the world wide state stored alongside the chunks.
+type Level struct {
+	Seed      int64 `json:"seed"`
+	Time      int64 `json:"time"`
+	DayLength int64 `json:"day_length"`
+}
+
+// writeAtomic replaces path with data without leaving a half written file behind on a crash.
+func writeAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func WriteLevel(dir string, l Level) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeAtomic(filepath.Join(dir, levelFile), data); err != nil {
+		return fmt.Errorf("error writing level: %v", err)
+	}
+	return nil
+}
+
+// ReadLevel loads the level data, ok is false if the save does not exist yet.
+func ReadLevel(dir string) (l Level, ok bool, err error) {
+	data, err := os.ReadFile(filepath.Join(dir, levelFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Level{}, false, nil
+	}
+	if err != nil {
+		return Level{}, false, fmt.Errorf("error reading level: %v", err)
+	}
+	if err := json.Unmarshal(data, &l); err != nil {
+		return Level{}, false, fmt.Errorf("error parsing level: %v", err)
+	}
+	return l, true, nil
+}
@@ -0,0 +1,76 @@
+package save
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/dragon1672/go-mine/minecraft/gamemode"
+)
+
+const playerDir = "players"
+
+// ValidName matches the player names allowed, which are also safe to use as file names.
+var ValidName = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// Player is what is remembered about a player between sessions.
+type Player struct {
+	GameMode gamemode.Mode `json:"game_mode"`
+	Health   int           `json:"health"`
+	// Inventory is an items.PlayerInventory, left raw since decoding it needs the item registry.
+	Inventory json.RawMessage `json:"inventory,omitempty"`
+}
+
+// PlayerStore keeps one JSON file per player in dir.
+type PlayerStore struct {
+	dir string
+}
+
+func NewPlayerStore(dir string) *PlayerStore {
+	return &PlayerStore{dir: dir}
+}
+
+func (s *PlayerStore) path(name string) (string, error) {
+	if !ValidName.MatchString(name) {
+		return "", fmt.Errorf("player name %q can't be saved", name)
+	}
+	return filepath.Join(s.dir, playerDir, name+".json"), nil
+}
+
+func (s *PlayerStore) SavePlayer(name string, p Player) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeAtomic(path, data); err != nil {
+		return fmt.Errorf("error writing player %s: %v", name, err)
+	}
+	return nil
+}
+
+// LoadPlayer reads a saved player, ok is false if they have never been saved.
+func (s *PlayerStore) LoadPlayer(name string) (p Player, ok bool, err error) {
+	path, err := s.path(name)
+	if err != nil {
+		return Player{}, false, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Player{}, false, nil
+	}
+	if err != nil {
+		return Player{}, false, fmt.Errorf("error reading player %s: %v", name, err)
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Player{}, false, fmt.Errorf("error parsing player %s: %v", name, err)
+	}
+	return p, true, nil
+}
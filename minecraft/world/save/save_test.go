@@ -0,0 +1,86 @@
+package save
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/dragon1672/go-mine/minecraft/gamemode"
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+)
+
+func TestLevel(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	if _, ok, err := ReadLevel(dir); ok || err != nil {
+		t.Errorf("ReadLevel() of an empty dir = %v, %v", ok, err)
+	}
+	want := Level{Seed: 7, Time: 1234, DayLength: 24000}
+	if err := WriteLevel(dir, want); err != nil {
+		t.Fatalf("WriteLevel() = %v", err)
+	}
+	if got, ok, err := ReadLevel(dir); got != want || !ok || err != nil {
+		t.Errorf("ReadLevel() = %+v, %v, %v, want %+v", got, ok, err, want)
+	}
+}
+
+func TestPlayerStore(t *testing.T) {
+	t.Parallel()
+	s := NewPlayerStore(t.TempDir())
+	if _, ok, err := s.LoadPlayer("alex"); ok || err != nil {
+		t.Errorf("LoadPlayer() of a new player = %v, %v", ok, err)
+	}
+	want := Player{GameMode: gamemode.Creative, Health: 12, Inventory: json.RawMessage(`{"selected":2}`)}
+	if err := s.SavePlayer("alex", want); err != nil {
+		t.Fatalf("SavePlayer() = %v", err)
+	}
+	got, ok, err := s.LoadPlayer("alex")
+	// the inventory comes back indented
+	var inv bytes.Buffer
+	if err == nil {
+		err = json.Compact(&inv, got.Inventory)
+	}
+	got.Inventory = inv.Bytes()
+	if !reflect.DeepEqual(got, want) || !ok || err != nil {
+		t.Errorf("LoadPlayer() = %+v, %v, %v, want %+v", got, ok, err, want)
+	}
+	if err := s.SavePlayer("../level", want); err == nil {
+		t.Errorf("expected a name with a path in it to be refused")
+	}
+}
+
+func TestChunkStore(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	s := NewChunkStore(dir)
+	pos := vec.IntVec3{X: -2, Y: 3, Z: 4}
+	if _, ok, err := s.LoadChunk(pos); ok || err != nil {
+		t.Errorf("LoadChunk() of a missing chunk = %v, %v", ok, err)
+	}
+
+	w := world.New(5)
+	at := world.ChunkOrigin(pos).Add(vec.IntVec3{X: 1, Y: 2, Z: 3})
+	w.SetBlock(at, blocks.Planks)
+	want := w.Snapshot(pos)
+	if err := s.SaveChunk(want); err != nil {
+		t.Fatalf("SaveChunk() = %v", err)
+	}
+	got, ok, err := s.LoadChunk(pos)
+	if !ok || err != nil {
+		t.Fatalf("LoadChunk() = %v, %v", ok, err)
+	}
+	if got.Pos != pos || got.Get(vec.IntVec3{X: 1, Y: 2, Z: 3}) != blocks.Planks {
+		t.Errorf("LoadChunk() = chunk %v without the saved edit", got.Pos)
+	}
+
+	if err := os.WriteFile(s.path(pos), []byte("junk"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.LoadChunk(pos); err == nil {
+		t.Errorf("LoadChunk() of a corrupt file succeeded")
+	}
+}
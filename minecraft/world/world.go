@@ -0,0 +1,247 @@
+package world
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+	"github.com/dragon1672/go-mine/minecraft/world/worldgen"
+	"github.com/dragon1672/go-mine/minecraft/world/worldtime"
+	"github.com/golang/glog"
+)
+
+// BlockChange describes a single block being replaced.
+type BlockChange struct {
+	Pos      vec.IntVec3
+	Old, New blocks.SimpleBlockType
+	// Actor is who made the change, such as a player name, or empty for the game itself.
+	Actor string
+}
+
+// Storage persists chunks so they can be dropped from memory, see World.Unload.
+type Storage interface {
+	// LoadChunk returns a previously saved chunk, ok is false if there isn't one.
+	LoadChunk(pos vec.IntVec3) (c *Chunk, ok bool, err error)
+	SaveChunk(c *Chunk) error
+}
+
+// World is every loaded chunk plus the generator that fills in new ones.
+// It is safe for concurrent use.
+type World struct {
+	mu        sync.RWMutex
+	seed      int64
+	gen       *worldgen.WorldGenny
+	chunks    map[vec.IntVec3]*Chunk
+	listeners []func(BlockChange)
+	clock     *worldtime.Clock
+	storage   Storage
+}
+
+func New(seed int64) *World {
+	return &World{
+		seed:   seed,
+		gen:    worldgen.New(seed),
+		chunks: map[vec.IntVec3]*Chunk{},
+		clock:  worldtime.NewClock(worldtime.DefaultDayLength),
+	}
+}
+
+func (w *World) Seed() int64 {
+	return w.seed
+}
+
+func (w *World) Clock() *worldtime.Clock {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.clock
+}
+
+// SetClock replaces the world's clock, as when loading a level with its own day length.
+func (w *World) SetClock(c *worldtime.Clock) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.clock = c
+}
+
+// OnChange registers f to be called after every block change.
+// Listeners run on the goroutine that made the change, without the world lock held.
+func (w *World) OnChange(f func(BlockChange)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.listeners = append(w.listeners, f)
+}
+
+// SetStorage makes the world load chunks from s before generating them, and save them to it on unload.
+func (w *World) SetStorage(s Storage) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.storage = s
+}
+
+func (w *World) generate(pos vec.IntVec3) *Chunk {
+	c := &Chunk{Pos: pos}
+	origin := ChunkOrigin(pos)
+	for y := 0; y < ChunkSize; y++ {
+		for z := 0; z < ChunkSize; z++ {
+			for x := 0; x < ChunkSize; x++ {
+				local := vec.IntVec3{X: x, Y: y, Z: z}
+				c.blocks[index(local)] = w.gen.GetType(origin.Add(local))
+			}
+		}
+	}
+	return c
+}
+
+// chunkLocked returns the chunk at pos, generating it if needed. w.mu must be held for writing.
+func (w *World) chunkLocked(pos vec.IntVec3) *Chunk {
+	c, ok := w.chunks[pos]
+	if ok {
+		return c
+	}
+	if w.storage != nil {
+		saved, ok, err := w.storage.LoadChunk(pos)
+		if err != nil {
+			glog.Errorf("Error loading chunk %v, generating it instead: %v", pos, err)
+		}
+		if ok {
+			saved.Dirty = false
+			w.chunks[pos] = saved
+			return saved
+		}
+	}
+	c = w.generate(pos)
+	w.chunks[pos] = c
+	return c
+}
+
+// Chunk returns the chunk at chunk position pos, loading it if needed.
+func (w *World) Chunk(pos vec.IntVec3) *Chunk {
+	w.mu.RLock()
+	c, ok := w.chunks[pos]
+	w.mu.RUnlock()
+	if ok {
+		return c
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.chunkLocked(pos)
+}
+
+// Snapshot copies the chunk at chunk position pos so it can be read without racing writers.
+func (w *World) Snapshot(pos vec.IntVec3) *Chunk {
+	c := w.Chunk(pos)
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	cp := *c
+	return &cp
+}
+
+// ViewChunk calls f with the chunk at chunk position pos under the read lock, without loading it.
+// It returns false if the chunk isn't in memory. f must not keep c or call back into the world.
+func (w *World) ViewChunk(pos vec.IntVec3, f func(c *Chunk)) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	c, ok := w.chunks[pos]
+	if ok {
+		f(c)
+	}
+	return ok
+}
+
+// IsLoaded reports whether the chunk at chunk position pos is in memory.
+func (w *World) IsLoaded(pos vec.IntVec3) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	_, ok := w.chunks[pos]
+	return ok
+}
+
+// LoadedChunks lists the positions of every chunk in memory.
+func (w *World) LoadedChunks() []vec.IntVec3 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	out := make([]vec.IntVec3, 0, len(w.chunks))
+	for p := range w.chunks {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.X != b.X {
+			return a.X < b.X
+		}
+		if a.Y != b.Y {
+			return a.Y < b.Y
+		}
+		return a.Z < b.Z
+	})
+	return out
+}
+
+// Unload drops the chunk at chunk position pos from memory, saving it first if it changed.
+// Changed chunks are kept if there is no storage to save them to, so edits are never lost.
+func (w *World) Unload(pos vec.IntVec3) (unloaded bool, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	c, ok := w.chunks[pos]
+	if !ok {
+		return false, nil
+	}
+	if c.Dirty {
+		if w.storage == nil {
+			return false, nil
+		}
+		if err := w.storage.SaveChunk(c); err != nil {
+			return false, err
+		}
+	}
+	delete(w.chunks, pos)
+	return true, nil
+}
+
+// SaveAll saves every changed chunk, such as before shutting down.
+func (w *World) SaveAll() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.storage == nil {
+		return nil
+	}
+	for _, c := range w.chunks {
+		if !c.Dirty {
+			continue
+		}
+		if err := w.storage.SaveChunk(c); err != nil {
+			return err
+		}
+		c.Dirty = false
+	}
+	return nil
+}
+
+func (w *World) BlockAt(pos vec.IntVec3) blocks.SimpleBlockType {
+	chunk, local := ChunkOf(pos)
+	c := w.Chunk(chunk)
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return c.Get(local)
+}
+
+func (w *World) SetBlock(pos vec.IntVec3, t blocks.SimpleBlockType) {
+	w.SetBlockBy(pos, t, "")
+}
+
+// SetBlockBy is SetBlock on behalf of actor, who listeners see in BlockChange.Actor.
+func (w *World) SetBlockBy(pos vec.IntVec3, t blocks.SimpleBlockType, actor string) {
+	chunk, local := ChunkOf(pos)
+	w.mu.Lock()
+	old := w.chunkLocked(chunk).Set(local, t)
+	listeners := w.listeners
+	w.mu.Unlock()
+	if old == t {
+		return
+	}
+	change := BlockChange{Pos: pos, Old: old, New: t, Actor: actor}
+	for _, f := range listeners {
+		f(change)
+	}
+}
@@ -0,0 +1,120 @@
+package world
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+	"github.com/dragon1672/go-mine/minecraft/world/blocks"
+	"github.com/dragon1672/go-mine/minecraft/world/worldgen"
+)
+
+func TestChunkOf(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		pos, chunk, local vec.IntVec3
+	}{
+		{pos: vec.IntVec3{X: 0, Y: 0, Z: 0}},
+		{pos: vec.IntVec3{X: 15, Y: 16, Z: 17}, chunk: vec.IntVec3{Y: 1, Z: 1}, local: vec.IntVec3{X: 15, Z: 1}},
+		{pos: vec.IntVec3{X: -1, Y: -16, Z: -17}, chunk: vec.IntVec3{X: -1, Y: -1, Z: -2}, local: vec.IntVec3{X: 15, Z: 15}},
+	} {
+		chunk, local := ChunkOf(tc.pos)
+		if chunk != tc.chunk || local != tc.local {
+			t.Errorf("ChunkOf(%v) = %v, %v want %v, %v", tc.pos, chunk, local, tc.chunk, tc.local)
+		}
+		if got := ChunkOrigin(chunk).Add(local); got != tc.pos {
+			t.Errorf("origin + local = %v, want %v", got, tc.pos)
+		}
+	}
+}
+
+func TestWorld(t *testing.T) {
+	t.Parallel()
+	w := New(42)
+	gen := worldgen.New(42)
+	pos := vec.IntVec3{X: -3, Y: 10, Z: 20}
+	if got, want := w.BlockAt(pos), gen.GetType(pos); got != want {
+		t.Errorf("generated %v, want %v", got, want)
+	}
+
+	var changes []BlockChange
+	w.OnChange(func(c BlockChange) { changes = append(changes, c) })
+	old := w.BlockAt(pos)
+	w.SetBlock(pos, blocks.Wood)
+	w.SetBlock(pos, blocks.Wood)
+	if len(changes) != 1 || changes[0] != (BlockChange{Pos: pos, Old: old, New: blocks.Wood}) {
+		t.Errorf("expected a single change event, got %v", changes)
+	}
+	chunk, _ := ChunkOf(pos)
+	if c := w.Chunk(chunk); !c.Dirty || c.Version == 0 {
+		t.Errorf("expected chunk to be dirty with a new version, got dirty %v version %d", c.Dirty, c.Version)
+	}
+}
+
+// memStorage keeps saved chunks in a map.
+type memStorage map[vec.IntVec3]*Chunk
+
+func (m memStorage) LoadChunk(pos vec.IntVec3) (*Chunk, bool, error) {
+	c, ok := m[pos]
+	return c, ok, nil
+}
+
+func (m memStorage) SaveChunk(c *Chunk) error {
+	cp := *c
+	m[c.Pos] = &cp
+	return nil
+}
+
+func TestUnload(t *testing.T) {
+	t.Parallel()
+	w := New(42)
+	pos := vec.IntVec3{X: 5, Y: 100, Z: 5}
+	chunk, _ := ChunkOf(pos)
+	w.SetBlock(pos, blocks.Stone)
+	if ok, err := w.Unload(chunk); ok || err != nil {
+		t.Errorf("Unload() of a dirty chunk with no storage = %v, %v, want it kept", ok, err)
+	}
+
+	store := memStorage{}
+	w.SetStorage(store)
+	clean, _ := ChunkOf(vec.IntVec3{X: 100, Y: 100, Z: 100})
+	w.Chunk(clean)
+	for _, c := range []vec.IntVec3{chunk, clean} {
+		if ok, err := w.Unload(c); !ok || err != nil {
+			t.Errorf("Unload(%v) = %v, %v, want unloaded", c, ok, err)
+		}
+		if w.IsLoaded(c) {
+			t.Errorf("%v still loaded", c)
+		}
+	}
+	if _, ok := store[clean]; ok {
+		t.Errorf("clean chunk was saved")
+	}
+	if got := w.BlockAt(pos); got != blocks.Stone {
+		t.Errorf("BlockAt() after reload = %v, want Stone", got)
+	}
+	if w.Chunk(chunk).Dirty {
+		t.Errorf("reloaded chunk is dirty")
+	}
+}
+
+func TestChunkRoundTrip(t *testing.T) {
+	t.Parallel()
+	w := New(3)
+	w.SetBlock(vec.IntVec3{X: 1, Y: 2, Z: 3}, blocks.Lava)
+	c := w.Snapshot(vec.IntVec3{})
+	data, err := EncodeChunk(c)
+	if err != nil {
+		t.Fatalf("EncodeChunk() = %v", err)
+	}
+	got, err := DecodeChunk(c.Pos, data)
+	if err != nil {
+		t.Fatalf("DecodeChunk() = %v", err)
+	}
+	if !reflect.DeepEqual(got.Blocks(), c.Blocks()) {
+		t.Errorf("DecodeChunk() blocks differ from the original")
+	}
+	if _, err := DecodeChunk(c.Pos, data[:len(data)/2]); err == nil {
+		t.Errorf("DecodeChunk() of truncated data succeeded")
+	}
+}
@@ -89,6 +89,11 @@ func (w *WorldGenny) GetGen(pos vec.IntVec3) blocks.Block {
 	}
 }
 
+// GetType is the block type generated at pos.
+func (w *WorldGenny) GetType(pos vec.IntVec3) blocks.SimpleBlockType {
+	return w.baseGen(pos)
+}
+
 func New(seed int64) *WorldGenny {
 	return &WorldGenny{
 		sim: opensimplex.New(seed),
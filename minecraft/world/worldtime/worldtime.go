@@ -0,0 +1,135 @@
+package worldtime
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dragon1672/go-mine/minecraft/utils/vec"
+)
+
+const (
+	// TicksPerSecond is the game tick rate the clock is advanced at.
+	TicksPerSecond = 20
+	// DefaultDayLength is a Minecraft day, 20 minutes.
+	DefaultDayLength int64 = 24000
+)
+
+// Named times of day, scaled to DefaultDayLength. 0 is sunrise.
+var namedTimes = map[string]int64{
+	"day":      1000,
+	"noon":     6000,
+	"sunset":   12000,
+	"night":    13000,
+	"midnight": 18000,
+	"sunrise":  23000,
+}
+
+type RGB struct {
+	R, G, B float32
+}
+
+var (
+	daySky   = RGB{0.47, 0.65, 1.0}
+	nightSky = RGB{0.02, 0.02, 0.06}
+	duskSky  = RGB{0.85, 0.45, 0.25}
+)
+
+// minSkylight keeps nights from going pitch black.
+const minSkylight = 0.2
+
+// Clock counts game ticks since the world was created.
+type Clock struct {
+	mu        sync.Mutex
+	ticks     int64
+	dayLength int64
+}
+
+func NewClock(dayLength int64) *Clock {
+	if dayLength <= 0 {
+		dayLength = DefaultDayLength
+	}
+	return &Clock{dayLength: dayLength}
+}
+
+func (c *Clock) Tick() {
+	c.mu.Lock()
+	c.ticks++
+	c.mu.Unlock()
+}
+
+func (c *Clock) Ticks() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ticks
+}
+
+func (c *Clock) SetTicks(t int64) {
+	c.mu.Lock()
+	c.ticks = t
+	c.mu.Unlock()
+}
+
+func (c *Clock) DayLength() int64 {
+	return c.dayLength
+}
+
+func (c *Clock) Day() int64 {
+	return c.Ticks() / c.dayLength
+}
+
+func (c *Clock) TimeOfDay() int64 {
+	return c.Ticks() % c.dayLength
+}
+
+// SetTimeOfDay jumps to a time of day without changing the day count.
+func (c *Clock) SetTimeOfDay(t int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t = ((t % c.dayLength) + c.dayLength) % c.dayLength
+	c.ticks = c.ticks - c.ticks%c.dayLength + t
+}
+
+// ParseTimeOfDay turns a name such as "noon" or a tick count into ticks scaled to dayLength.
+func ParseTimeOfDay(s string, dayLength int64) (int64, error) {
+	if t, ok := namedTimes[strings.ToLower(s)]; ok {
+		return t * dayLength / DefaultDayLength, nil
+	}
+	t, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected a number or one of day, noon, sunset, night, midnight, sunrise", s)
+	}
+	return t, nil
+}
+
+// SunAngle is radians travelled around the sky, 0 at sunrise and Pi at sunset.
+func (c *Clock) SunAngle() float64 {
+	return 2 * math.Pi * float64(c.TimeOfDay()) / float64(c.dayLength)
+}
+
+// SunDirection points from the world towards the sun, the sun rises in +X.
+func (c *Clock) SunDirection() vec.Vec3 {
+	a := c.SunAngle()
+	return vec.Vec3{X: math.Cos(a), Y: math.Sin(a), Z: 0.2}
+}
+
+// SkylightMultiplier scales sky light, 1 during the day and minSkylight at night.
+func (c *Clock) SkylightMultiplier() float64 {
+	l := math.Sin(c.SunAngle())*2 + 0.5
+	return math.Max(minSkylight, math.Min(1, l))
+}
+
+func lerp(a, b RGB, t float32) RGB {
+	return RGB{a.R + (b.R-a.R)*t, a.G + (b.G-a.G)*t, a.B + (b.B-a.B)*t}
+}
+
+// SkyColor blends from night to day with the skylight, tinted orange near the horizon.
+func (c *Clock) SkyColor() RGB {
+	l := float32((c.SkylightMultiplier() - minSkylight) / (1 - minSkylight))
+	sky := lerp(nightSky, daySky, l)
+	// strongest when the sun is level with the horizon and the sky is half lit
+	dusk := float32(math.Max(0, 1-math.Abs(math.Sin(c.SunAngle()))*4)) * 0.6
+	return lerp(sky, duskSky, dusk)
+}
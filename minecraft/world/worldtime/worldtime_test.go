@@ -0,0 +1,74 @@
+package worldtime
+
+import (
+	"math"
+	"testing"
+)
+
+func TestClock(t *testing.T) {
+	t.Parallel()
+	t.Run("Ticks wrap into days", func(t *testing.T) {
+		c := NewClock(100)
+		for i := 0; i < 250; i++ {
+			c.Tick()
+		}
+		if c.Day() != 2 || c.TimeOfDay() != 50 {
+			t.Errorf("got day %d time %d, want day 2 time 50", c.Day(), c.TimeOfDay())
+		}
+		c.SetTimeOfDay(10)
+		if c.Day() != 2 || c.TimeOfDay() != 10 || c.Ticks() != 210 {
+			t.Errorf("SetTimeOfDay changed the day, got ticks %d", c.Ticks())
+		}
+	})
+
+	t.Run("Day and night", func(t *testing.T) {
+		c := NewClock(DefaultDayLength)
+		c.SetTimeOfDay(namedTimes["noon"])
+		if math.Abs(c.SunAngle()-math.Pi/2) > 1e-9 {
+			t.Errorf("noon sun angle = %v, want Pi/2", c.SunAngle())
+		}
+		if c.SkylightMultiplier() != 1 {
+			t.Errorf("noon skylight = %v, want 1", c.SkylightMultiplier())
+		}
+		noonSky := c.SkyColor()
+		if noonSky != daySky {
+			t.Errorf("noon sky = %v, want %v", noonSky, daySky)
+		}
+
+		c.SetTimeOfDay(namedTimes["midnight"])
+		if c.SkylightMultiplier() != minSkylight {
+			t.Errorf("midnight skylight = %v, want %v", c.SkylightMultiplier(), minSkylight)
+		}
+		if c.SunDirection().Y >= 0 {
+			t.Errorf("sun should be below the horizon at midnight, got %v", c.SunDirection())
+		}
+		if c.SkyColor() != nightSky {
+			t.Errorf("midnight sky = %v, want %v", c.SkyColor(), nightSky)
+		}
+	})
+}
+
+func TestParseTimeOfDay(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		in        string
+		dayLength int64
+		want      int64
+		wantErr   bool
+	}{
+		{in: "noon", dayLength: DefaultDayLength, want: 6000},
+		{in: "Night", dayLength: DefaultDayLength, want: 13000},
+		{in: "noon", dayLength: 1200, want: 300},
+		{in: "1234", dayLength: DefaultDayLength, want: 1234},
+		{in: "teatime", dayLength: DefaultDayLength, wantErr: true},
+	} {
+		got, err := ParseTimeOfDay(tc.in, tc.dayLength)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ParseTimeOfDay(%q) err = %v, wantErr %v", tc.in, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseTimeOfDay(%q) = %d, want %d", tc.in, got, tc.want)
+		}
+	}
+}